@@ -0,0 +1,72 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/facebookincubator/ttpforge/pkg/campaign"
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+func buildCampaignRunCommand(cfg *Config) *cobra.Command {
+	var reportPath string
+	var ttpCfg blocks.TTPExecutionConfig
+	runCmd := &cobra.Command{
+		Use:   "run [path/to/campaign.yaml]",
+		Short: "Run the TTPs of the campaign found in the specified YAML file.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := campaign.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			// don't want confusing usage display for errors past this point
+			cmd.SilenceUsage = true
+
+			// capture output for tests if needed
+			if cfg.testCfg != nil {
+				ttpCfg.Stdout, ttpCfg.Stderr = cfg.testCfg.Stdout, cfg.testCfg.Stderr
+			}
+
+			ttpCfg.PluginPaths = cfg.pluginPaths
+			report, runErr := c.Run(cfg.repoCollection, ttpCfg)
+			if reportPath != "" {
+				if err := report.WriteFile(reportPath); err != nil {
+					return err
+				}
+				logging.L().Infof("Wrote campaign report to %v", reportPath)
+			}
+
+			if runErr != nil {
+				return fmt.Errorf("campaign %q failed: %v", c.Name, runErr)
+			}
+			return nil
+		},
+	}
+	runCmd.PersistentFlags().BoolVar(&ttpCfg.NoCleanup, "no-cleanup", false, "Disable cleanup of all TTPs of the campaign")
+	runCmd.PersistentFlags().UintVar(&ttpCfg.CleanupDelaySeconds, "cleanup-delay-seconds", 0, "Wait this long after each TTP before starting its cleanup")
+	runCmd.PersistentFlags().StringVar(&reportPath, "report", "", "Write a combined JSON report of the campaign results to this file")
+
+	return runCmd
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCampaignRun(t *testing.T) {
+	testConfigFilePath := filepath.Join(testResourcesDir, "test-config.yaml")
+	campaignPath := filepath.Join(testResourcesDir, "campaigns", "simple.yaml")
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	stdout, err := runCommandForTest(t, "campaign", "run", "-c", testConfigFilePath, "--report", reportPath, campaignPath)
+	require.NoError(t, err)
+	assert.Equal(t, "simple inline was executed\nsimple inline was executed\ncleaning up simple inline\ncleaning up simple inline\n", stdout)
+
+	reportBytes, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var report struct {
+		Success bool `json:"success"`
+		TTPs    []struct {
+			Name    string `json:"name"`
+			Success bool   `json:"success"`
+		} `json:"ttps"`
+	}
+	require.NoError(t, json.Unmarshal(reportBytes, &report))
+	assert.True(t, report.Success)
+	require.Len(t, report.TTPs, 2)
+	assert.Equal(t, "first", report.TTPs[0].Name)
+	assert.Equal(t, "second", report.TTPs[1].Name)
+}
+
+func TestCampaignRunInvalidFile(t *testing.T) {
+	_, err := runCommandForTest(t, "campaign", "run", filepath.Join(testResourcesDir, "campaigns", "does-not-exist.yaml"))
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/spf13/cobra"
+)
+
+func buildCleanupCommand(cfg *Config) *cobra.Command {
+	var ttpCfg blocks.TTPExecutionConfig
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup [state-file]",
+		Short: "Clean up a TTP run using the state file written by 'ttpforge run --state-file'",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// don't want confusing usage display for errors past this point
+			cmd.SilenceUsage = true
+
+			// capture output for tests if needed
+			if cfg.testCfg != nil {
+				ttpCfg.Stdout, ttpCfg.Stderr = cfg.testCfg.Stdout, cfg.testCfg.Stderr
+			}
+
+			stateFilePath := args[0]
+			state, err := blocks.ReadRunState(stateFilePath)
+			if err != nil {
+				return err
+			}
+
+			// the repository is only needed by cleanup
+			// actions that reference other TTPs
+			if state.TTPPath != "" {
+				foundRepo, _, err := cfg.repoCollection.ResolveTTPRef(state.TTPPath)
+				if err != nil {
+					logging.L().Warnf("Could not find the repository containing %v: %v", state.TTPPath, err)
+				} else {
+					ttpCfg.Repo = foundRepo
+				}
+			}
+
+			ttpCfg.PluginPaths = cfg.pluginPaths
+			execCtx := blocks.NewTTPExecutionContext()
+			execCtx.Cfg = ttpCfg
+			cleanupErr := state.RunCleanup(execCtx)
+
+			// record which steps were cleaned up so
+			// that they are not cleaned up again
+			if err := state.WriteFile(stateFilePath); err != nil {
+				return err
+			}
+			if cleanupErr != nil {
+				return fmt.Errorf("failed to clean up TTP %q: %w", state.TTPName, cleanupErr)
+			}
+			return nil
+		},
+	}
+	return cleanupCmd
+}
@@ -0,0 +1,163 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/facebookincubator/ttpforge/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCommandForTest(t *testing.T, args ...string) (string, error) {
+	var stdoutBuf, stderrBuf bytes.Buffer
+	rc := BuildRootCommand(&TestConfig{
+		Stdout: &stdoutBuf,
+		Stderr: &stderrBuf,
+	})
+	rc.SetArgs(args)
+	logMutex.Lock()
+	err := rc.Execute()
+	logMutex.Unlock()
+	return stdoutBuf.String(), err
+}
+
+func TestCleanup(t *testing.T) {
+	testConfigFilePath := filepath.Join(testResourcesDir, "test-config.yaml")
+
+	testCases := []struct {
+		name                  string
+		description           string
+		ttpRef                string
+		wantRunError          bool
+		expectedRunStdout     string
+		expectedCleanupStdout string
+	}{
+		{
+			name:                  "simple-inline",
+			description:           "the cleanup of a TTP run with --no-cleanup should run later",
+			ttpRef:                "another-repo//simple-inline.yaml",
+			expectedRunStdout:     "simple inline was executed\n",
+			expectedCleanupStdout: "cleaning up simple inline\n",
+		},
+		{
+			name:         "subttp-cleanup",
+			description:  "completed steps of sub TTPs should be cleaned up in the right order",
+			ttpRef:       "another-repo//sub-ttp-example/ttp.yaml",
+			wantRunError: true,
+			// the failed sub TTP is cleaned up immediately
+			expectedRunStdout:     "subttp1_step_1\nsubttp1_step_2\nsubttp2_step_1\nsubttp2_step_1_cleanup\n",
+			expectedCleanupStdout: "subttp1_step_2_cleanup\nsubttp1_step_1_cleanup\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			stdout, err := runCommandForTest(t, "run", "-c", testConfigFilePath, "--no-cleanup", "--state-file", statePath, tc.ttpRef)
+			if tc.wantRunError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedRunStdout, stdout)
+
+			stdout, err = runCommandForTest(t, "cleanup", "-c", testConfigFilePath, statePath)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCleanupStdout, stdout)
+
+			// everything has been cleaned up now
+			stdout, err = runCommandForTest(t, "cleanup", "-c", testConfigFilePath, statePath)
+			require.NoError(t, err)
+			assert.Empty(t, stdout)
+		})
+	}
+}
+
+func TestRunStateFileAfterCleanup(t *testing.T) {
+	testConfigFilePath := filepath.Join(testResourcesDir, "test-config.yaml")
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	stdout, err := runCommandForTest(t, "run", "-c", testConfigFilePath, "--state-file", statePath, "another-repo//simple-inline.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "simple inline was executed\ncleaning up simple inline\n", stdout)
+
+	// cleanup already ran as part of `ttpforge run`
+	stdout, err = runCommandForTest(t, "cleanup", "-c", testConfigFilePath, statePath)
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+}
+
+func TestCleanupRetriesFailedSteps(t *testing.T) {
+	testDir, err := testutils.MakeTempTestDir(map[string][]byte{
+		"config.yaml": []byte("---\nrepos:\n  - name: cleanup-repo\n    path: cleanup-repo\n"),
+		filepath.Join("cleanup-repo", repos.RepoConfigFileName): []byte("ttp_search_paths: [ttps]"),
+	})
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	configPath := filepath.Join(testDir, "config.yaml")
+	markerPath := filepath.Join(testDir, "marker")
+	statePath := filepath.Join(testDir, "state.json")
+
+	// the cleanup of the second step fails until the marker file exists
+	ttpContent := fmt.Sprintf(`---
+name: flaky-cleanup
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleaning up first
+  - name: second
+    inline: echo second
+    cleanup:
+      inline: test -f %q && echo cleaning up second
+`, markerPath)
+	ttpPath := filepath.Join(testDir, "cleanup-repo", "ttps", "flaky.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(ttpPath), 0755))
+	require.NoError(t, os.WriteFile(ttpPath, []byte(ttpContent), 0644))
+
+	stdout, err := runCommandForTest(t, "run", "-c", configPath, "--state-file", statePath, "cleanup-repo//flaky.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\ncleaning up first\n", stdout)
+
+	state, err := blocks.ReadRunState(statePath)
+	require.NoError(t, err)
+	assert.False(t, state.CleanupComplete)
+	require.Len(t, state.Steps, 2)
+	assert.True(t, state.Steps[0].CleanedUp)
+	assert.False(t, state.Steps[1].CleanedUp)
+
+	// only the step whose cleanup failed is cleaned up again
+	require.NoError(t, os.WriteFile(markerPath, nil, 0644))
+	stdout, err = runCommandForTest(t, "cleanup", "-c", configPath, statePath)
+	require.NoError(t, err)
+	assert.Equal(t, "cleaning up second\n", stdout)
+
+	stdout, err = runCommandForTest(t, "cleanup", "-c", configPath, statePath)
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+}
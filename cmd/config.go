@@ -30,6 +30,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/facebookincubator/ttpforge/pkg/fileutils"
 	"github.com/facebookincubator/ttpforge/pkg/logging"
 	"github.com/facebookincubator/ttpforge/pkg/repos"
 	"github.com/spf13/afero"
@@ -51,9 +52,11 @@ type TestConfig struct {
 // we export it for use in tests, but packages besides `cmd` probably
 // should not touch it
 type Config struct {
-	RepoSpecs []repos.Spec `yaml:"repos"`
+	RepoSpecs   []repos.Spec `yaml:"repos"`
+	PluginPaths []string     `yaml:"plugin_paths,omitempty"`
 
 	repoCollection repos.RepoCollection
+	pluginPaths    []string
 	cfgFile        string
 	testCfg        *TestConfig
 }
@@ -80,17 +83,46 @@ func getDefaultConfigFilePath() (string, error) {
 // in the configuration file are present on the filesystem
 // and clones missing ones if needed
 func (cfg *Config) loadRepoCollection() (repos.RepoCollection, error) {
-	// locate our config file directory to expend config-relative paths
-	var basePath string
-	if cfg.cfgFile != "" {
-		cfgFileAbsPath, err := filepath.Abs(cfg.cfgFile)
+	basePath, err := cfg.basePath()
+	if err != nil {
+		return nil, err
+	}
+	fsys := afero.NewOsFs()
+	return repos.NewRepoCollection(fsys, cfg.RepoSpecs, basePath)
+}
+
+// basePath returns the directory of the config file,
+// against which config-relative paths are expanded
+func (cfg *Config) basePath() (string, error) {
+	if cfg.cfgFile == "" {
+		return "", nil
+	}
+	cfgFileAbsPath, err := filepath.Abs(cfg.cfgFile)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(cfgFileAbsPath), nil
+}
+
+// resolvePluginPaths expands the plugin search paths from the
+// config file - relative paths are relative to the config file
+func (cfg *Config) resolvePluginPaths() ([]string, error) {
+	basePath, err := cfg.basePath()
+	if err != nil {
+		return nil, err
+	}
+	var resolved []string
+	for _, path := range cfg.PluginPaths {
+		expanded, err := fileutils.ExpandTilde(path)
 		if err != nil {
 			return nil, err
 		}
-		basePath = filepath.Dir(cfgFileAbsPath)
+		if !filepath.IsAbs(expanded) && basePath != "" {
+			expanded = filepath.Join(basePath, expanded)
+		}
+		resolved = append(resolved, expanded)
 	}
-	fsys := afero.NewOsFs()
-	return repos.NewRepoCollection(fsys, cfg.RepoSpecs, basePath)
+	return resolved, nil
 }
 
 // save() writes the current config back to its file - used by `install“ command
@@ -143,6 +175,9 @@ func (cfg *Config) init() error {
 	if cfg.repoCollection, err = cfg.loadRepoCollection(); err != nil {
 		return err
 	}
+	if cfg.pluginPaths, err = cfg.resolvePluginPaths(); err != nil {
+		return err
+	}
 
 	// setup logging
 	return logging.InitLog(logConfig)
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/format"
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func buildFmtCommand(cfg *Config) *cobra.Command {
+	var repoFilter string
+	var check bool
+	fmtCmd := &cobra.Command{
+		Use:   "fmt [repo_name//path/to/ttp...]",
+		Short: "Rewrite TTP files in the canonical format.",
+		Long: `Rewrite the specified TTPs, the TTPs of the repository selected with --repo,
+or all installed TTPs (if neither is given) in the canonical format.
+The paths of the files that were changed are printed.
+
+With --check, files are not modified - instead, the paths of the files
+that are not formatted are printed and the command exits with an error
+if there are any, so that it can be used in CI.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repoFilter != "" && len(args) > 0 {
+				return errors.New("TTP references and --repo cannot be used together")
+			}
+			if repoFilter != "" {
+				if _, err := cfg.repoCollection.GetRepo(repoFilter); err != nil {
+					return err
+				}
+			}
+
+			// don't want confusing usage display for errors past this point
+			cmd.SilenceUsage = true
+
+			ttpRefs := args
+			if len(ttpRefs) == 0 {
+				allRefs, err := cfg.repoCollection.ListTTPs()
+				if err != nil {
+					return err
+				}
+				for _, ttpRef := range allRefs {
+					if repoFilter == "" || strings.HasPrefix(ttpRef, repoFilter+repos.RepoPrefixSep) {
+						ttpRefs = append(ttpRefs, ttpRef)
+					}
+				}
+			}
+
+			var changedCount, failedCount int
+			for _, ttpRef := range ttpRefs {
+				changed, err := formatTTP(cfg.repoCollection, ttpRef, check)
+				if err != nil {
+					logging.L().Errorf("failed to format %v: %v", ttpRef, err)
+					failedCount++
+					continue
+				}
+				if changed != "" {
+					fmt.Fprintln(cmd.OutOrStdout(), changed)
+					changedCount++
+				}
+			}
+			if failedCount > 0 {
+				return fmt.Errorf("failed to format %d TTP(s)", failedCount)
+			}
+			if check && changedCount > 0 {
+				return fmt.Errorf("%d TTP(s) are not formatted - run `ttpforge fmt` to format them", changedCount)
+			}
+			return nil
+		},
+	}
+	fmtCmd.PersistentFlags().StringVar(&repoFilter, "repo", "", "Format only the TTPs of the specified repository")
+	fmtCmd.PersistentFlags().BoolVar(&check, "check", false, "Report the TTPs that are not formatted instead of rewriting them")
+	return fmtCmd
+}
+
+// formatTTP formats the TTP with the given reference, writing the
+// result back to the file unless check is set. It returns the path
+// of the TTP if it was not already formatted, or "" otherwise.
+func formatTTP(rc repos.RepoCollection, ttpRef string, check bool) (string, error) {
+	repo, ttpAbsPath, err := rc.ResolveTTPRef(ttpRef)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve TTP reference %v: %w", ttpRef, err)
+	}
+	fsys := repo.GetFs()
+	content, err := afero.ReadFile(fsys, ttpAbsPath)
+	if err != nil {
+		return "", err
+	}
+	formatted, err := format.TTP(content)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Equal(content, formatted) {
+		return "", nil
+	}
+	if !check {
+		info, err := fsys.Stat(ttpAbsPath)
+		if err != nil {
+			return "", err
+		}
+		if err := afero.WriteFile(fsys, ttpAbsPath, formatted, info.Mode()); err != nil {
+			return "", err
+		}
+	}
+	return ttpAbsPath, nil
+}
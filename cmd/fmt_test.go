@@ -0,0 +1,109 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/facebookincubator/ttpforge/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFmt(t *testing.T) {
+	const formattedTTP = `---
+name: formatted
+description: already formatted
+steps:
+  - name: hello
+    print_str: hello
+`
+	const unformattedTTP = `description: needs formatting
+name: unformatted
+steps:
+    - print_str: hello {{ .Args.who }}
+      name: hello
+args:
+    - name: who
+      default: world
+`
+	const expectedTTP = `---
+name: unformatted
+description: needs formatting
+args:
+  - name: who
+    default: world
+steps:
+  - name: hello
+    print_str: hello {{ .Args.who }}
+`
+	testDir, err := testutils.MakeTempTestDir(map[string][]byte{
+		"config.yaml": []byte("---\nrepos:\n  - name: fmt-repo\n    path: fmt-repo\n"),
+		filepath.Join("fmt-repo", repos.RepoConfigFileName):   []byte("ttp_search_paths: [ttps]"),
+		filepath.Join("fmt-repo", "ttps", "formatted.yaml"):   []byte(formattedTTP),
+		filepath.Join("fmt-repo", "ttps", "unformatted.yaml"): []byte(unformattedTTP),
+	})
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	configPath := filepath.Join(testDir, "config.yaml")
+	formattedPath := filepath.Join(testDir, "fmt-repo", "ttps", "formatted.yaml")
+	unformattedPath := filepath.Join(testDir, "fmt-repo", "ttps", "unformatted.yaml")
+
+	runFmt := func(args ...string) (string, error) {
+		var stdout bytes.Buffer
+		rc := BuildRootCommand(&TestConfig{})
+		rc.SetArgs(append([]string{"fmt", "-c", configPath}, args...))
+		rc.SetOut(&stdout)
+		err := rc.Execute()
+		return stdout.String(), err
+	}
+
+	// --check reports the unformatted TTP without changing it
+	output, err := runFmt("--check")
+	require.Error(t, err)
+	assert.Equal(t, unformattedPath+"\n", output)
+	content, err := os.ReadFile(unformattedPath)
+	require.NoError(t, err)
+	assert.Equal(t, unformattedTTP, string(content))
+
+	// already formatted TTPs are left alone
+	output, err = runFmt("--check", "fmt-repo//formatted.yaml")
+	require.NoError(t, err)
+	assert.Empty(t, output)
+
+	// formatting rewrites only the unformatted TTP
+	output, err = runFmt("--repo", "fmt-repo")
+	require.NoError(t, err)
+	assert.Equal(t, unformattedPath+"\n", output)
+	content, err = os.ReadFile(unformattedPath)
+	require.NoError(t, err)
+	assert.Equal(t, expectedTTP, string(content))
+	content, err = os.ReadFile(formattedPath)
+	require.NoError(t, err)
+	assert.Equal(t, formattedTTP, string(content))
+
+	output, err = runFmt("--check")
+	require.NoError(t, err)
+	assert.Empty(t, output)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/spf13/cobra"
+)
+
+func buildGraphCommand(cfg *Config) *cobra.Command {
+	var argsList []string
+	var format string
+	graphCmd := &cobra.Command{
+		Use:   "graph [repo_name//path/to/ttp]",
+		Short: "Print the sub-TTP dependency tree of the specified TTP.",
+		Long: `Print the tree of sub-TTPs (referenced through ttp: steps) used by the specified TTP,
+either in the Graphviz DOT format (the default) or as JSON.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "dot" && format != "json" {
+				return fmt.Errorf("invalid graph format %q - must be dot or json", format)
+			}
+
+			// don't want confusing usage display for errors past this point
+			cmd.SilenceUsage = true
+
+			ttpRef := args[0]
+			foundRepo, ttpAbsPath, err := cfg.repoCollection.ResolveTTPRef(ttpRef)
+			if err != nil {
+				return fmt.Errorf("failed to resolve TTP reference %v: %v", ttpRef, err)
+			}
+
+			graph, err := blocks.LoadGraph(ttpAbsPath, foundRepo, argsList)
+			if err != nil {
+				return fmt.Errorf("could not load TTP at %v:\n\t%v", ttpAbsPath, err)
+			}
+			graph.Ref = ttpRef
+			if format == "json" {
+				return graph.WriteJSON(cmd.OutOrStdout())
+			}
+			return graph.WriteDOT(cmd.OutOrStdout())
+		},
+	}
+	graphCmd.PersistentFlags().StringVar(&format, "format", "dot", "Output format of the graph (dot or json)")
+	graphCmd.Flags().StringArrayVarP(&argsList, "arg", "a", []string{}, "variable input mapping for args to be used in place of inputs defined in each ttp file")
+	return graphCmd
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph(t *testing.T) {
+	testConfigFilePath := filepath.Join(testResourcesDir, "test-config.yaml")
+	ttpRef := "another-repo//sub-ttp-example/ttp.yaml"
+
+	testCases := []struct {
+		name      string
+		format    string
+		checkFunc func(t *testing.T, stdout string)
+		wantError bool
+	}{
+		{
+			name:   "DOT",
+			format: "dot",
+			checkFunc: func(t *testing.T, stdout string) {
+				assert.Contains(t, stdout, "digraph ttps {")
+				assert.Contains(t, stdout, `[label="first_sub_ttp"]`)
+				assert.Contains(t, stdout, `[label="second_sub_ttp"]`)
+			},
+		},
+		{
+			name:   "JSON",
+			format: "json",
+			checkFunc: func(t *testing.T, stdout string) {
+				var graph struct {
+					Name     string `json:"name"`
+					Ref      string `json:"ref"`
+					Children []struct {
+						Ref  string `json:"ref"`
+						Step string `json:"step"`
+					} `json:"children"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(stdout), &graph))
+				assert.Equal(t, "subttp_cleanup_test", graph.Name)
+				assert.Equal(t, ttpRef, graph.Ref)
+				require.Len(t, graph.Children, 2)
+				assert.Equal(t, "sub-ttp-example/subttp1.yaml", graph.Children[0].Ref)
+				assert.Equal(t, "second_sub_ttp", graph.Children[1].Step)
+			},
+		},
+		{
+			name:      "Invalid format",
+			format:    "svg",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var graphBuf bytes.Buffer
+			rc := BuildRootCommand(&TestConfig{
+				Stdout: &bytes.Buffer{},
+				Stderr: &bytes.Buffer{},
+			})
+			rc.SetArgs([]string{"graph", "-c", testConfigFilePath, "--format", tc.format, ttpRef})
+			rc.SetOut(&graphBuf)
+			rc.SetErr(&bytes.Buffer{})
+			logMutex.Lock()
+			err := rc.Execute()
+			logMutex.Unlock()
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tc.checkFunc(t, graphBuf.String())
+		})
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/lint"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/spf13/cobra"
+)
+
+func buildLintCommand(cfg *Config) *cobra.Command {
+	var repoFilter string
+	lintCmd := &cobra.Command{
+		Use:   "lint [repo_name//path/to/ttp...]",
+		Short: "Check TTPs for common mistakes.",
+		Long: `Run a set of lint rules over the specified TTPs, over the TTPs of the repository
+selected with --repo, or over all installed TTPs if neither is given.
+The command exits with an error if any rule with the error severity
+reports a finding, so that it can be used in CI.
+
+Findings can be suppressed with a "# ttpforge-lint-ignore <rule-id>" comment
+on the offending line (or the line above it), or for a whole file with
+"# ttpforge-lint-ignore-file <rule-id>".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repoFilter != "" && len(args) > 0 {
+				return errors.New("TTP references and --repo cannot be used together")
+			}
+			if repoFilter != "" {
+				if _, err := cfg.repoCollection.GetRepo(repoFilter); err != nil {
+					return err
+				}
+			}
+
+			// don't want confusing usage display for errors past this point
+			cmd.SilenceUsage = true
+
+			// rules such as duplicate-uuid compare each
+			// TTP with all of the installed TTPs
+			ttpRefs, err := cfg.repoCollection.ListTTPs()
+			if err != nil {
+				return err
+			}
+			var corpus []*lint.Document
+			var targets []*lint.Document
+			for _, ttpRef := range ttpRefs {
+				doc, err := loadLintDocument(cfg, ttpRef)
+				if err != nil {
+					return err
+				}
+				corpus = append(corpus, doc)
+				if len(args) == 0 && (repoFilter == "" || strings.HasPrefix(ttpRef, repoFilter+repos.RepoPrefixSep)) {
+					targets = append(targets, doc)
+				}
+			}
+			for _, ttpRef := range args {
+				doc, err := loadLintDocument(cfg, ttpRef)
+				if err != nil {
+					return err
+				}
+				targets = append(targets, doc)
+			}
+
+			findings := lint.Lint(targets, corpus, lint.DefaultRules())
+			for _, finding := range findings {
+				fmt.Fprintln(cmd.OutOrStdout(), finding)
+			}
+			if errCount := lint.CountErrors(findings); errCount > 0 {
+				return fmt.Errorf("lint found %d error(s) in %d TTP(s)", errCount, len(targets))
+			}
+			return nil
+		},
+	}
+	lintCmd.PersistentFlags().StringVar(&repoFilter, "repo", "", "Lint only the TTPs of the specified repository")
+	return lintCmd
+}
+
+// loadLintDocument resolves a TTP reference and loads the TTP for linting
+func loadLintDocument(cfg *Config, ttpRef string) (*lint.Document, error) {
+	repo, ttpAbsPath, err := cfg.repoCollection.ResolveTTPRef(ttpRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve TTP reference %v: %w", ttpRef, err)
+	}
+	doc, err := lint.LoadDocument(repo.GetFs(), ttpAbsPath, repo)
+	if err != nil {
+		return nil, err
+	}
+	doc.PluginPaths = cfg.pluginPaths
+	return doc, nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/facebookincubator/ttpforge/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	const goodTTP = `---
+uuid: 6f0e4a1c-2d4b-4c5e-9f7a-1b2c3d4e5f60
+name: good
+description: a TTP without findings
+mitre:
+  tactics:
+    - TA0002 Execution
+steps:
+  - name: hello
+    print_str: hello
+`
+	const warningTTP = `---
+uuid: 6f0e4a1c-2d4b-4c5e-9f7a-1b2c3d4e5f61
+name: warning
+description: a TTP without a MITRE mapping
+steps:
+  - name: hello
+    print_str: hello
+`
+	const errorTTP = `---
+uuid: 6f0e4a1c-2d4b-4c5e-9f7a-1b2c3d4e5f60
+name: "@nocommit"
+description: a TTP that reuses the uuid of good.yaml
+mitre:
+  tactics:
+    - TA0002 Execution
+steps:
+  - name: hello
+    print_str: hello
+`
+	testDir, err := testutils.MakeTempTestDir(map[string][]byte{
+		"config.yaml": []byte("---\nrepos:\n  - name: lint-repo\n    path: lint-repo\n  - name: other-repo\n    path: other-repo\n"),
+		filepath.Join("lint-repo", repos.RepoConfigFileName):  []byte("ttp_search_paths: [ttps]"),
+		filepath.Join("lint-repo", "ttps", "good.yaml"):       []byte(goodTTP),
+		filepath.Join("lint-repo", "ttps", "warning.yaml"):    []byte(warningTTP),
+		filepath.Join("other-repo", repos.RepoConfigFileName): []byte("ttp_search_paths: [ttps]"),
+		filepath.Join("other-repo", "ttps", "error.yaml"):     []byte(errorTTP),
+	})
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	configPath := filepath.Join(testDir, "config.yaml")
+	goodPath := filepath.Join(testDir, "lint-repo", "ttps", "good.yaml")
+	warningPath := filepath.Join(testDir, "lint-repo", "ttps", "warning.yaml")
+	errorPath := filepath.Join(testDir, "other-repo", "ttps", "error.yaml")
+
+	testCases := []struct {
+		name           string
+		args           []string
+		expectedOutput string
+		wantError      bool
+	}{
+		{
+			name:           "Warnings Only",
+			args:           []string{"lint-repo//warning.yaml"},
+			expectedOutput: warningPath + ":2:1: warning [missing-mitre] the TTP has no MITRE ATT&CK mapping (`mitre:`)\n",
+		},
+		{
+			name:      "Errors",
+			args:      []string{"other-repo//error.yaml"},
+			wantError: true,
+			expectedOutput: errorPath + ":2:7: error [duplicate-uuid] uuid 6f0e4a1c-2d4b-4c5e-9f7a-1b2c3d4e5f60 is also used by " + goodPath + "\n" +
+				errorPath + ":3:8: error [nocommit] replace the @nocommit placeholder before committing the TTP\n",
+		},
+		{
+			name: "Repo Filter",
+			args: []string{"--repo", "lint-repo"},
+			expectedOutput: goodPath + ":2:7: error [duplicate-uuid] uuid 6f0e4a1c-2d4b-4c5e-9f7a-1b2c3d4e5f60 is also used by " + errorPath + "\n" +
+				warningPath + ":2:1: warning [missing-mitre] the TTP has no MITRE ATT&CK mapping (`mitre:`)\n",
+			wantError: true,
+		},
+		{
+			name:      "Unknown Repo",
+			args:      []string{"--repo", "does-not-exist"},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			rc := BuildRootCommand(&TestConfig{})
+			rc.SetArgs(append([]string{"lint", "-c", configPath}, tc.args...))
+			rc.SetOut(&stdout)
+			err := rc.Execute()
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			if tc.expectedOutput != "" {
+				assert.Equal(t, tc.expectedOutput, stdout.String())
+			}
+		})
+	}
+}
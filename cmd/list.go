@@ -27,10 +27,11 @@ func buildListCommand(cfg *Config) *cobra.Command {
 	listCmd := &cobra.Command{
 		Use:              "list",
 		Short:            "list various resources available to TTPForge",
-		Long:             "Use this command to list repos, TTPs, etc.",
+		Long:             "Use this command to list repos, TTPs, plugins, etc.",
 		TraverseChildren: true,
 	}
 	listCmd.AddCommand(buildListTTPsCommand(cfg))
 	listCmd.AddCommand(buildListReposCommand(cfg))
+	listCmd.AddCommand(buildListPluginsCommand(cfg))
 	return listCmd
 }
@@ -0,0 +1,45 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/ttpforge/pkg/plugins"
+	"github.com/spf13/cobra"
+)
+
+func buildListPluginsCommand(cfg *Config) *cobra.Command {
+	return &cobra.Command{
+		Use:              "plugins",
+		Short:            "list the action plugins found in the plugin_paths of your config file",
+		TraverseChildren: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			found, err := plugins.List(cfg.pluginPaths)
+			if err != nil {
+				return err
+			}
+			for _, plugin := range found {
+				fmt.Fprintf(cmd.OutOrStdout(), "%v\t%v\n", plugin.Name, plugin.Path)
+			}
+			return nil
+		},
+	}
+}
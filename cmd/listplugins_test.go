@@ -0,0 +1,54 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin discovery on windows relies on file extensions")
+	}
+
+	// plugin_paths are relative to the config file
+	configDir := t.TempDir()
+	pluginDir := filepath.Join(configDir, "plugins")
+	require.NoError(t, os.Mkdir(pluginDir, 0755))
+	pluginPath := filepath.Join(pluginDir, plugins.ExecutablePrefix+"ad")
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0755))
+	configPath := filepath.Join(configDir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("---\nplugin_paths:\n  - plugins\n"), 0644))
+
+	var stdout bytes.Buffer
+	rc := BuildRootCommand(&TestConfig{})
+	rc.SetArgs([]string{"list", "plugins", "-c", configPath})
+	rc.SetOut(&stdout)
+	require.NoError(t, rc.Execute())
+	assert.Equal(t, "ad\t"+pluginPath+"\n", stdout.String())
+}
@@ -56,7 +56,8 @@ TTPForge is a Purple Team engagement tool to execute Tactics, Techniques, and Pr
     `,
 		TraverseChildren: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if cmd.CalledAs() != "init" {
+			// neither of these commands needs a configuration file
+			if cmd.CalledAs() != "init" && cmd.CalledAs() != "schema" {
 				err := cfg.init()
 				if err != nil {
 					return fmt.Errorf("failed to load TTPForge configuration file: %w", err)
@@ -81,9 +82,15 @@ TTPForge is a Purple Team engagement tool to execute Tactics, Techniques, and Pr
 	rootCmd.AddCommand(buildEnumCommand(cfg))
 	rootCmd.AddCommand(buildShowCommand(cfg))
 	rootCmd.AddCommand(buildRunCommand(cfg))
+	rootCmd.AddCommand(buildCleanupCommand(cfg))
+	rootCmd.AddCommand(buildCampaignCommand(cfg))
+	rootCmd.AddCommand(buildGraphCommand(cfg))
 	rootCmd.AddCommand(buildTestCommand(cfg))
 	rootCmd.AddCommand(buildInstallCommand(cfg))
 	rootCmd.AddCommand(buildRemoveCommand(cfg))
 	rootCmd.AddCommand(buildParseYamlCommand(cfg))
+	rootCmd.AddCommand(buildLintCommand(cfg))
+	rootCmd.AddCommand(buildFmtCommand(cfg))
+	rootCmd.AddCommand(buildSchemaCommand())
 	return rootCmd
 }
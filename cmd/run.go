@@ -21,6 +21,8 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/facebookincubator/ttpforge/pkg/blocks"
 	"github.com/facebookincubator/ttpforge/pkg/logging"
@@ -29,12 +31,22 @@ import (
 
 func buildRunCommand(cfg *Config) *cobra.Command {
 	var argsList []string
+	var reportPath string
+	var stateFilePath string
+	var interactive bool
+	var breakAt []string
+	var showPlan bool
+	var planFormat string
 	var ttpCfg blocks.TTPExecutionConfig
 	runCmd := &cobra.Command{
 		Use:   "run [repo_name//path/to/ttp]",
 		Short: "Run the TTP found in the specified YAML file.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if planFormat != "text" && planFormat != "json" {
+				return fmt.Errorf("invalid plan format %q - must be text or json", planFormat)
+			}
+
 			// don't want confusing usage display for errors past this point
 			cmd.SilenceUsage = true
 
@@ -52,18 +64,40 @@ func buildRunCommand(cfg *Config) *cobra.Command {
 
 			// load TTP and process argument values
 			// based on the TTPs argument value specifications
+			ttpCfg.PluginPaths = cfg.pluginPaths
 			ttpCfg.Repo = foundRepo
+			if showPlan {
+				// planning must not run anything either
+				ttpCfg.DryRun = true
+			}
+			if interactive || len(breakAt) > 0 {
+				ttpCfg.Debugger = blocks.NewDebugger(cmd.InOrStdin(), cmd.OutOrStdout(), interactive, breakAt)
+			}
 
 			ttp, execCtx, err := blocks.LoadTTP(ttpAbsPath, foundRepo.GetFs(), &ttpCfg, map[string]string{}, argsList)
 			if err != nil {
 				return fmt.Errorf("could not load TTP at %v:\n\t%v", ttpAbsPath, err)
 			}
+			if ttpCfg.Debugger != nil {
+				if err := ttpCfg.Debugger.CheckBreakpoints(ttp); err != nil {
+					return err
+				}
+			}
+
+			if showPlan {
+				return writePlan(cmd.OutOrStdout(), ttp, *execCtx, planFormat)
+			}
 
 			if ttpCfg.DryRun {
 				logging.L().Info("Dry-Run Requested - Returning Early")
 				return nil
 			}
 
+			if stateFilePath != "" {
+				execCtx.RecordState(stateFilePath, ttp)
+			}
+
+			startTime := time.Now()
 			runErr := ttp.Execute(*execCtx)
 			// Run clean up always
 			cleanupErr := ttp.RunCleanup(*execCtx)
@@ -72,8 +106,22 @@ func buildRunCommand(cfg *Config) *cobra.Command {
 				logging.L().Warnf("Failed to run cleanup: %v", cleanupErr)
 			}
 
+			if stateFilePath != "" {
+				if err := writeFinalRunState(stateFilePath, ttp, *execCtx, cleanupErr == nil && !ttpCfg.NoCleanup); err != nil {
+					return err
+				}
+			}
+
+			if reportPath != "" {
+				report := blocks.NewReport(ttp, *execCtx, startTime, time.Now(), runErr)
+				if err := report.WriteFile(reportPath); err != nil {
+					return err
+				}
+				logging.L().Infof("Wrote execution report to %v", reportPath)
+			}
+
 			if runErr != nil {
-				return fmt.Errorf("failed to run TTP at %v: %v", ttpAbsPath, err)
+				return fmt.Errorf("failed to run TTP at %v: %v", ttpAbsPath, runErr)
 			}
 			return nil
 		},
@@ -81,7 +129,42 @@ func buildRunCommand(cfg *Config) *cobra.Command {
 	runCmd.PersistentFlags().BoolVar(&ttpCfg.DryRun, "dry-run", false, "Parse arguments and validate TTP Contents, but do not actually run the TTP")
 	runCmd.PersistentFlags().BoolVar(&ttpCfg.NoCleanup, "no-cleanup", false, "Disable cleanup (useful for debugging and daisy-chaining TTPs)")
 	runCmd.PersistentFlags().UintVar(&ttpCfg.CleanupDelaySeconds, "cleanup-delay-seconds", 0, "Wait this long after TTP execution before starting cleanup")
+	runCmd.PersistentFlags().StringVar(&reportPath, "report", "", "Write a JSON report of the TTP execution results to this file")
+	runCmd.PersistentFlags().StringVar(&stateFilePath, "state-file", "", "Record the progress of the TTP in this file so that it can be cleaned up later with 'ttpforge cleanup'")
+	runCmd.PersistentFlags().BoolVar(&showPlan, "plan", false, "Print the fully rendered execution plan of the TTP without running it")
+	runCmd.PersistentFlags().StringVar(&planFormat, "plan-format", "text", "Format of the plan printed by --plan (text or json)")
+	runCmd.PersistentFlags().BoolVar(&interactive, "interactive", false, "Pause before each step to inspect and control the execution of the TTP")
+	runCmd.PersistentFlags().StringArrayVar(&breakAt, "break-at", []string{}, "Pause before the step with this name (can be repeated)")
 	runCmd.Flags().StringArrayVarP(&argsList, "arg", "a", []string{}, "variable input mapping for args to be used in place of inputs defined in each ttp file")
 
 	return runCmd
 }
+
+// writeFinalRunState records the state of the TTP once execution
+// and cleanup have finished
+func writeFinalRunState(path string, ttp *blocks.TTP, execCtx blocks.TTPExecutionContext, cleanupComplete bool) error {
+	state, err := blocks.NewRunState(ttp, execCtx)
+	if err != nil {
+		return fmt.Errorf("failed to record run state: %w", err)
+	}
+	state.CleanupComplete = cleanupComplete
+	if err := state.WriteFile(path); err != nil {
+		return err
+	}
+	if !cleanupComplete {
+		logging.L().Infof("Run 'ttpforge cleanup %v' to clean up this TTP", path)
+	}
+	return nil
+}
+
+// writePlan prints the execution plan of the TTP in the requested format
+func writePlan(w io.Writer, ttp *blocks.TTP, execCtx blocks.TTPExecutionContext, format string) error {
+	plan, err := blocks.NewPlan(ttp, execCtx)
+	if err != nil {
+		return fmt.Errorf("failed to build execution plan: %w", err)
+	}
+	if format == "json" {
+		return plan.WriteJSON(w)
+	}
+	return plan.WriteText(w)
+}
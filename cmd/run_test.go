@@ -21,11 +21,16 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 
+	"github.com/facebookincubator/ttpforge/pkg/plugins"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -53,6 +58,9 @@ func checkRunCmdTestCase(t *testing.T, tc runCmdTestCase) {
 		Stderr: &stderrBuf,
 	})
 	rc.SetArgs(append([]string{"run"}, tc.args...))
+	// the debugger must never wait for input from the terminal
+	rc.SetIn(&bytes.Buffer{})
+	rc.SetOut(&bytes.Buffer{})
 	logMutex.Lock()
 	err := rc.Execute()
 	logMutex.Unlock()
@@ -118,6 +126,30 @@ func TestRun(t *testing.T) {
 			},
 			expectedStdout: "",
 		},
+		{
+			name:        "break-at-unknown-step",
+			description: "`--break-at` must refer to a step of the TTP",
+			args: []string{
+				"-c",
+				testConfigFilePath,
+				"--break-at",
+				"no_such_step",
+				testRepoName + "//steps/file-step-demo.yaml",
+			},
+			wantError: true,
+		},
+		{
+			name:        "break-at-without-input",
+			description: "the debugger runs the remaining steps when there is no more input",
+			args: []string{
+				"-c",
+				testConfigFilePath,
+				"--break-at",
+				"execute_file",
+				testRepoName + "//steps/file-step-demo.yaml",
+			},
+			expectedStdout: "Hello World\n",
+		},
 		{
 			name:        "dry-run-fail",
 			description: "validating a TTP with `--dry-run` should fail for a syntactically invalid TTP",
@@ -159,6 +191,119 @@ func TestRun(t *testing.T) {
 	}
 }
 
+// TestRunReport checks that `ttpforge run --report` writes
+// a JSON report containing the results of each step
+func TestRunReport(t *testing.T) {
+	testConfigFilePath := filepath.Join(testResourcesDir, "test-config.yaml")
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	checkRunCmdTestCase(t, runCmdTestCase{
+		name:        "report",
+		description: "the report should contain step and cleanup output",
+		args: []string{
+			"-c",
+			testConfigFilePath,
+			"--report",
+			reportPath,
+			"another-repo//simple-inline.yaml",
+		},
+		expectedStdout: "simple inline was executed\ncleaning up simple inline\n",
+	})
+
+	contents, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var report struct {
+		TTP struct {
+			Name string `json:"name"`
+		} `json:"ttp"`
+		Success bool `json:"success"`
+		Steps   []struct {
+			Name    string `json:"name"`
+			Stdout  string `json:"stdout"`
+			Cleanup struct {
+				Stdout string `json:"stdout"`
+			} `json:"cleanup"`
+		} `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &report))
+	assert.Equal(t, "basic_inline", report.TTP.Name)
+	assert.True(t, report.Success)
+	require.Len(t, report.Steps, 1)
+	assert.Equal(t, "hello", report.Steps[0].Name)
+	assert.Equal(t, "simple inline was executed\n", report.Steps[0].Stdout)
+	assert.Equal(t, "cleaning up simple inline\n", report.Steps[0].Cleanup.Stdout)
+}
+
+func TestRunPlan(t *testing.T) {
+	testConfigFilePath := filepath.Join(testResourcesDir, "test-config.yaml")
+
+	testCases := []struct {
+		name         string
+		format       string
+		expectedPlan []string
+		wantError    bool
+	}{
+		{
+			name:   "Text plan",
+			format: "text",
+			expectedPlan: []string{
+				"TTP: basic_inline\n",
+				"  #1 hello\n",
+				"inline: echo \"simple inline was executed\"",
+				"     Cleanup:\n",
+			},
+		},
+		{
+			name:   "JSON plan",
+			format: "json",
+			expectedPlan: []string{
+				`"name": "basic_inline"`,
+				`"type": "inline"`,
+			},
+		},
+		{
+			name:      "Invalid format",
+			format:    "xml",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdoutBuf, stderrBuf, planBuf bytes.Buffer
+			rc := BuildRootCommand(&TestConfig{
+				Stdout: &stdoutBuf,
+				Stderr: &stderrBuf,
+			})
+			rc.SetArgs([]string{
+				"run",
+				"-c",
+				testConfigFilePath,
+				"--plan",
+				"--plan-format",
+				tc.format,
+				"another-repo//simple-inline.yaml",
+			})
+			rc.SetOut(&planBuf)
+			rc.SetErr(&bytes.Buffer{})
+			logMutex.Lock()
+			err := rc.Execute()
+			logMutex.Unlock()
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// nothing should have been executed
+			assert.Empty(t, stdoutBuf.String())
+			for _, expected := range tc.expectedPlan {
+				assert.Contains(t, planBuf.String(), expected)
+			}
+		})
+	}
+}
+
 // TestRunPathArguments checks that referencing relative paths in `--arg` values
 // when executing `ttpforge run` works as expected. One typically needs to
 // specify `type: path` in the argument specification in order to get desired
@@ -238,3 +383,102 @@ func TestRunPathArguments(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadingTTPDoesNotRunPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin discovery on windows relies on file extensions")
+	}
+
+	testDir := t.TempDir()
+	markerPath := filepath.Join(testDir, "plugin-was-run")
+	files := map[string]string{
+		"config.yaml": "---\nrepos:\n  - name: plugin-repo\n    path: plugin-repo\nplugin_paths:\n  - plugins\n",
+		filepath.Join("plugin-repo", repos.RepoConfigFileName): "ttp_search_paths: [ttps]",
+		filepath.Join("plugin-repo", "ttps", "plugin.yaml"): `---
+name: plugin-ttp
+steps:
+  - name: join
+    plugin: ad
+    args:
+      domain: corp.example.com`,
+		filepath.Join("plugins", plugins.ExecutablePrefix+"ad"): "#!/bin/sh\ntouch " + markerPath + "\necho '{}'\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(testDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0755))
+	}
+	configPath := filepath.Join(testDir, "config.yaml")
+
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "Dry run",
+			args: []string{"run", "--dry-run"},
+		},
+		{
+			name: "Plan",
+			args: []string{"run", "--plan"},
+		},
+		{
+			name: "Graph",
+			args: []string{"graph"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append(tc.args, "-c", configPath, "plugin-repo//plugin.yaml")
+			_, err := runCommandForTest(t, args...)
+			require.NoError(t, err)
+			assert.NoFileExists(t, markerPath)
+		})
+	}
+}
+
+func TestRelativeChangeDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test TTP uses pwd")
+	}
+
+	testDir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": "---\nrepos:\n  - name: cd-repo\n    path: cd-repo\n",
+		filepath.Join("cd-repo", repos.RepoConfigFileName): "ttp_search_paths: [ttps]",
+		filepath.Join("cd-repo", "ttps", "cd.yaml"): `---
+name: relative-cd
+steps:
+  - name: enter_sub
+    cd: sub
+  - name: print_dir
+    inline: pwd`,
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(testDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0644))
+	}
+	subDir := filepath.Join(testDir, "cd-repo", "ttps", "sub")
+	require.NoError(t, os.Mkdir(subDir, 0755))
+
+	// the cd must be resolved relative to the TTP,
+	// not to the working directory of the process
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(string(filepath.Separator)))
+	defer func() {
+		if err := os.Chdir(wd); err != nil {
+			panic(err)
+		}
+	}()
+
+	stdout, err := runCommandForTest(t, "run", "-c", filepath.Join(testDir, "config.yaml"), "cd-repo//cd.yaml")
+	require.NoError(t, err)
+	expectedDir, err := filepath.EvalSymlinks(subDir)
+	require.NoError(t, err)
+	actualDir, err := filepath.EvalSymlinks(strings.TrimSpace(stdout))
+	require.NoError(t, err)
+	assert.Equal(t, expectedDir, actualDir)
+}
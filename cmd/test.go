@@ -35,19 +35,12 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-type testCase struct {
-	Name        string            `yaml:"name"`
-	Description string            `yaml:"description"`
-	Args        map[string]string `yaml:"args"`
-	DryRun      bool              `yaml:"dry_run"`
-}
-
 // We want to verify everything but the steps themselves against
 // our schema. The steps will, in turn, be validated
 // by the subsequent invocation of the `ttpforge run` command.
 type ttpNonStepFields struct {
 	blocks.PreambleFields `yaml:",inline"`
-	Cases                 []testCase `yaml:"tests"`
+	Cases                 []blocks.TestCase `yaml:"tests"`
 }
 
 // Note - this command cannot be unit tested
@@ -118,12 +111,12 @@ func runTestsForTTP(ttpAbsPath string, timeoutSeconds int) error {
 		return fmt.Errorf("could not resolve self path (path to current ttpforge binary): %w", err)
 	}
 
-	var testCases []testCase
+	var testCases []blocks.TestCase
 	if len(ttpf.Cases) == 0 {
 		if len(ttpf.ArgSpecs) == 0 {
 			// since this TTP doesn't accept arguments, it doesn't need a test case
 			// to validate its steps - so we can add an implicit dry run case
-			testCases = append(testCases, testCase{
+			testCases = append(testCases, blocks.TestCase{
 				Name:        "auto_generated_dry_run",
 				Description: "Auto-generated dry run test case",
 				DryRun:      true,
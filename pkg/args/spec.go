@@ -22,24 +22,41 @@ package args
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/facebookincubator/ttpforge/pkg/fileutils"
+	"github.com/facebookincubator/ttpforge/pkg/jsonschema"
 )
 
 // Spec defines a CLI argument for the TTP
 type Spec struct {
-	Name    string   `yaml:"name"`
-	Type    string   `yaml:"type,omitempty"`
-	Default string   `yaml:"default,omitempty"`
-	Choices []string `yaml:"choices,omitempty"`
-	Format  string   `yaml:"regexp,omitempty"`
+	Name string `yaml:"name"`
+	// Description explains what the argument is for - it is
+	// shown when a required argument is not provided
+	Description string   `yaml:"description,omitempty"`
+	Type        string   `yaml:"type,omitempty"`
+	Default     string   `yaml:"default,omitempty"`
+	Choices     []string `yaml:"choices,omitempty"`
+	Format      string   `yaml:"regexp,omitempty"`
 
 	formatReg *regexp.Regexp
 }
 
+// JSONSchema describes the argument specification
+// and the argument types supported by convertArgToType
+func (spec Spec) JSONSchema(r *jsonschema.Reflector) *jsonschema.Schema {
+	schema := r.ReflectStruct(reflect.TypeOf(spec))
+	schema.Properties["type"] = &jsonschema.Schema{
+		Enum: []any{"string", "int", "bool", "path", "list"},
+	}
+	schema.Required = []string{"name"}
+	return schema
+}
+
 // ParseAndValidate checks that the provided arguments
 // match the argument specifications for this TTP
 //
@@ -140,6 +157,9 @@ func ParseAndValidate(specs []Spec, argsKvStrs []string) (map[string]any, error)
 	// error if argument was not provided and no default value was specified
 	for _, spec := range specs {
 		if _, ok := processedArgs[spec.Name]; !ok {
+			if spec.Description != "" {
+				return nil, fmt.Errorf("value for required argument '%v' (%v) was not provided and no default value was specified", spec.Name, strings.TrimSpace(spec.Description))
+			}
 			return nil, fmt.Errorf("value for required argument '%v' was not provided and no default value was specified", spec.Name)
 		}
 	}
@@ -169,7 +189,61 @@ func (spec Spec) convertArgToType(val string) (any, error) {
 			return nil, fmt.Errorf("failed to process argument of type `path`: %w", err)
 		}
 		return absPath, nil
+	case "list":
+		// list values are specified as comma-separated strings
+		var items []string
+		for _, item := range strings.Split(val, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
 	default:
 		return nil, fmt.Errorf("invalid type %v specified in configuration for argument %v", spec.Type, spec.Name)
 	}
 }
+
+// WithPlaceholders supplies a placeholder value for each argument
+// that has no default and is not set in argsKvStrs, so that TTPs with
+// required arguments can be rendered without running them (for
+// example by `ttpforge lint` or `ttpforge graph`). Format constraints
+// are dropped for these arguments as the placeholder values would
+// not generally match them.
+//
+// **Parameters:**
+//
+// specs: slice of argument Spec values loaded from the TTP yaml
+// argsKvStrs: slice of arguments in "ARG_NAME=ARG_VALUE" format
+//
+// **Returns:**
+//
+// []Spec: the specs with the format of placeholder arguments removed
+// []string: argsKvStrs followed by the placeholder arguments
+func WithPlaceholders(specs []Spec, argsKvStrs []string) ([]Spec, []string) {
+	provided := make(map[string]bool)
+	for _, argKvStr := range argsKvStrs {
+		argName, _, _ := strings.Cut(argKvStr, "=")
+		provided[argName] = true
+	}
+
+	resultKvStrs := slices.Clone(argsKvStrs)
+	result := make([]Spec, len(specs))
+	for i, spec := range specs {
+		result[i] = spec
+		if spec.Default != "" || provided[spec.Name] {
+			continue
+		}
+		result[i].Format = ""
+		value := "placeholder"
+		switch {
+		case len(spec.Choices) > 0:
+			value = spec.Choices[0]
+		case spec.Type == "int":
+			value = "0"
+		case spec.Type == "bool":
+			value = "false"
+		}
+		resultKvStrs = append(resultKvStrs, spec.Name+"="+value)
+	}
+	return result, resultKvStrs
+}
@@ -47,6 +47,28 @@ func checkValidateTestCase(t *testing.T, tc validateTestCase) {
 func TestValidateArgs(t *testing.T) {
 
 	testCases := []validateTestCase{
+		{
+			name: "Parse List Argument",
+			specs: []Spec{
+				{
+					Name: "hosts",
+					Type: "list",
+				},
+				{
+					Name:    "ports",
+					Type:    "list",
+					Default: "22, 80",
+				},
+			},
+			argKvStrs: []string{
+				"hosts=alpha,beta,,gamma",
+			},
+			expectedResult: map[string]any{
+				"hosts": []string{"alpha", "beta", "gamma"},
+				"ports": []string{"22", "80"},
+			},
+			wantError: false,
+		},
 		{
 			name: "Parse String and Integer Arguments",
 			specs: []Spec{
@@ -305,3 +327,63 @@ func TestValidateArgs(t *testing.T) {
 	}
 
 }
+
+func TestWithPlaceholders(t *testing.T) {
+	testCases := []struct {
+		name           string
+		specs          []Spec
+		argKvStrs      []string
+		expectedResult map[string]any
+	}{
+		{
+			name: "Placeholders by type",
+			specs: []Spec{
+				{Name: "str", Format: "^[0-9]+$"},
+				{Name: "num", Type: "int"},
+				{Name: "flag", Type: "bool"},
+				{Name: "choice", Choices: []string{"a", "b"}},
+				{Name: "defaulted", Default: "value"},
+			},
+			expectedResult: map[string]any{
+				"str":       "placeholder",
+				"num":       0,
+				"flag":      false,
+				"choice":    "a",
+				"defaulted": "value",
+			},
+		},
+		{
+			name: "Provided arguments are kept",
+			specs: []Spec{
+				{Name: "given", Format: "^[0-9]+$"},
+				{Name: "missing", Type: "int"},
+			},
+			argKvStrs: []string{"given=42"},
+			expectedResult: map[string]any{
+				"given":   "42",
+				"missing": 0,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			specs, argKvStrs := WithPlaceholders(tc.specs, tc.argKvStrs)
+			args, err := ParseAndValidate(specs, argKvStrs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, args)
+		})
+	}
+}
+
+func TestMissingArgumentDescription(t *testing.T) {
+	specs := []Spec{
+		{
+			Name:        "target",
+			Description: "the host to connect to\n",
+		},
+	}
+	_, err := ParseAndValidate(specs, nil)
+	require.Error(t, err)
+	assert.Equal(t, "value for required argument 'target' (the host to connect to) was not provided and no default value was specified", err.Error())
+}
@@ -19,14 +19,16 @@ THE SOFTWARE.
 
 package blocks
 
+import "context"
+
 // Action is an interface that is implemented
 // by all action types used in steps/cleanups
 // (such as create_file, inline, etc)
 type Action interface {
 	IsNil() bool
-	Validate(execCtx TTPExecutionContext) error
-	Template(execCtx TTPExecutionContext) error
-	Execute(execCtx TTPExecutionContext) (*ActResult, error)
+	Validate(ctx context.Context, execCtx TTPExecutionContext) error
+	Template(ctx context.Context, execCtx TTPExecutionContext) error
+	Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error)
 	GetDescription() string
 	GetDefaultCleanupAction() Action
 	CanBeUsedInCompositeAction() bool
@@ -25,16 +25,12 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/facebookincubator/ttpforge/pkg/logging"
 	"github.com/facebookincubator/ttpforge/pkg/outputs"
 	"go.uber.org/zap"
 )
 
-// DefaultExecutionTimeout is the default timeout for step execution.
-const DefaultExecutionTimeout = 100 * time.Minute
-
 // BasicStep is a type that represents a basic execution step.
 type BasicStep struct {
 	actionDefaults `yaml:",inline"`
@@ -60,7 +56,7 @@ func (b *BasicStep) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies.
-func (b *BasicStep) Validate(execCtx TTPExecutionContext) error {
+func (b *BasicStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	// Check if Inline is provided
 	if b.Inline == "" {
 		err := errors.New("inline must be provided")
@@ -92,20 +88,17 @@ func (b *BasicStep) Validate(execCtx TTPExecutionContext) error {
 }
 
 // Template takes each applicable field in the step and replaces any template strings with their resolved values.
-func (b *BasicStep) Template(execCtx TTPExecutionContext) error {
+func (b *BasicStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
-	b.Inline, err = execCtx.templateStep(b.Inline)
+	b.Inline, err = execCtx.templateLegacyStep(b.Inline)
 	if err != nil {
 		return err
 	}
-	return nil
+	return execCtx.templateEnvironment(b.Environment)
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (b *BasicStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), DefaultExecutionTimeout)
-	defer cancel()
-
+func (b *BasicStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	if b.Inline == "" {
 		return nil, fmt.Errorf("empty inline value in Execute(...)")
 	}
@@ -113,7 +106,11 @@ func (b *BasicStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
 	executor := NewExecutor(b.ExecutorName, b.Inline, "", nil, b.Environment)
 	result, err := executor.Execute(ctx, execCtx)
 	if err != nil {
-		return nil, err
+		if result != nil {
+			// outputs of failed commands are parsed on a best-effort basis
+			result.Outputs, _ = outputs.Parse(b.Outputs, result.Stdout)
+		}
+		return result, err
 	}
 	result.Outputs, err = outputs.Parse(b.Outputs, result.Stdout)
 	if err != nil {
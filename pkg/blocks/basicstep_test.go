@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -87,11 +88,11 @@ outputs:
 	execCtx := NewTTPExecutionContext()
 	err := yaml.Unmarshal([]byte(content), &s)
 	require.NoError(t, err)
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
 
 	// execute and check result
-	result, err := s.Execute(execCtx)
+	result, err := s.Execute(context.Background(), execCtx)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(result.Outputs))
 	assert.Equal(t, "baz", result.Outputs["first"], "first output should be correct")
@@ -107,11 +108,11 @@ inline: echo "this is {[{.StepVars.foo}]}"`
 	}
 	err := yaml.Unmarshal([]byte(content), &s)
 	require.NoError(t, err)
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
-	err = s.Template(execCtx)
+	err = s.Template(context.Background(), execCtx)
 	require.NoError(t, err)
-	result, err := s.Execute(execCtx)
+	result, err := s.Execute(context.Background(), execCtx)
 	require.NoError(t, err)
 	assert.Equal(t, "this is successfully templated\n", result.Stdout, "stdout should be templated")
 }
@@ -123,9 +124,9 @@ inline: echo "this is {[{.StepVars.foo}]}"`
 	execCtx := NewTTPExecutionContext()
 	err := yaml.Unmarshal([]byte(content), &s)
 	require.NoError(t, err)
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
-	err = s.Template(execCtx)
+	err = s.Template(context.Background(), execCtx)
 	require.Error(t, err)
 }
 
@@ -137,11 +138,11 @@ outputvar: foo`
 	execCtx := NewTTPExecutionContext()
 	err := yaml.Unmarshal([]byte(content), &s)
 	require.NoError(t, err)
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
-	err = s.Template(execCtx)
+	err = s.Template(context.Background(), execCtx)
 	require.NoError(t, err)
-	_, err = s.Execute(execCtx)
+	_, err = s.Execute(context.Background(), execCtx)
 	require.NoError(t, err)
 	assert.Equal(t, "bar", execCtx.Vars.StepVars["foo"], "outputvar should be set")
 }
@@ -154,11 +155,11 @@ outputvar: foo`
 	execCtx := NewTTPExecutionContext()
 	err := yaml.Unmarshal([]byte(content), &s)
 	require.NoError(t, err)
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
-	err = s.Template(execCtx)
+	err = s.Template(context.Background(), execCtx)
 	require.NoError(t, err)
-	_, err = s.Execute(execCtx)
+	_, err = s.Execute(context.Background(), execCtx)
 	require.NoError(t, err)
 	assert.Equal(t, "line1\nline2\n\nline4\n", execCtx.Vars.StepVars["foo"], "outputvar should be set")
 }
@@ -174,11 +175,11 @@ outputvar: foo`
 	}
 	err := yaml.Unmarshal([]byte(content), &s)
 	require.NoError(t, err)
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
-	err = s.Template(execCtx)
+	err = s.Template(context.Background(), execCtx)
 	require.NoError(t, err)
-	_, err = s.Execute(execCtx)
+	_, err = s.Execute(context.Background(), execCtx)
 	require.NoError(t, err)
 	assert.Equal(t, "bar", execCtx.Vars.StepVars["foo"], "outputvar should be set")
 }
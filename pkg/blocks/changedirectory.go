@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -30,10 +31,10 @@ import (
 // ChangeDirectoryStep is a step that changes the current working directory
 type ChangeDirectoryStep struct {
 	actionDefaults `yaml:",inline"`
-	Cd             string `yaml:"cd"`
-	PreviousDir    string
-	PreviousCDStep *ChangeDirectoryStep
-	FileSystem     afero.Fs `yaml:"-,omitempty"`
+	Cd             string               `yaml:"cd"`
+	PreviousDir    string               `yaml:"-"`
+	PreviousCDStep *ChangeDirectoryStep `yaml:"-"`
+	FileSystem     afero.Fs             `yaml:"-,omitempty"`
 }
 
 // NewChangeDirectoryStep creates a new ChangeDirectoryStep instance with an initialized Act struct.
@@ -51,7 +52,7 @@ func (step *ChangeDirectoryStep) IsNil() bool {
 // **Returns:**
 //
 // error: error if validation fails, nil otherwise
-func (step *ChangeDirectoryStep) Validate(_ TTPExecutionContext) error {
+func (step *ChangeDirectoryStep) Validate(_ context.Context, _ TTPExecutionContext) error {
 	// If this has a parent cd step, hold off on validation until execute
 	if step.PreviousCDStep != nil {
 		return nil
@@ -71,7 +72,7 @@ func (step *ChangeDirectoryStep) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (step *ChangeDirectoryStep) Template(execCtx TTPExecutionContext) error {
+func (step *ChangeDirectoryStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	step.Cd, err = execCtx.templateStep(step.Cd)
 	if err != nil {
@@ -86,7 +87,7 @@ func (step *ChangeDirectoryStep) Template(execCtx TTPExecutionContext) error {
 //
 // ActResult: the result of the action
 // error: error if execution fails, nil otherwise
-func (step *ChangeDirectoryStep) Execute(ctx TTPExecutionContext) (*ActResult, error) {
+func (step *ChangeDirectoryStep) Execute(_ context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	// If this has a parent, then it's a cleanup step, so we need to grab the previous dir from it
 	if step.PreviousCDStep != nil {
 		if step.PreviousCDStep.PreviousDir == "" {
@@ -105,24 +106,36 @@ func (step *ChangeDirectoryStep) Execute(ctx TTPExecutionContext) (*ActResult, e
 		}
 	}
 
-	exists, err := afero.DirExists(fsys, step.Cd)
+	if step.Cd == "" {
+		return nil, fmt.Errorf("empty cd value in Execute(...)")
+	}
+
+	// relative directories are resolved against the working
+	// directory of the TTP, not of the process - the cleanup
+	// restores the previous working directory as it was
+	cdPath := step.Cd
+	if step.PreviousCDStep == nil {
+		var err error
+		cdPath, err = FetchAbs(step.Cd, execCtx.Vars.WorkDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := afero.DirExists(fsys, cdPath)
 	if err != nil {
 		return nil, err
 	}
 
 	if !exists {
-		return nil, fmt.Errorf("directory \"%s\" does not exist", step.Cd)
+		return nil, fmt.Errorf("directory \"%s\" does not exist", cdPath)
 	}
 
-	logging.L().Infof("Changing directory to %s", step.Cd)
-
-	if step.Cd == "" {
-		return nil, fmt.Errorf("empty cd value in Execute(...)")
-	}
+	logging.L().Infof("Changing directory to %s", cdPath)
 
 	// Set workdir to the current cd value and store the previous workdir
-	step.PreviousDir = ctx.Vars.WorkDir
-	ctx.Vars.WorkDir = step.Cd
+	step.PreviousDir = execCtx.Vars.WorkDir
+	execCtx.Vars.WorkDir = cdPath
 
 	return &ActResult{}, nil
 }
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/facebookincubator/ttpforge/pkg/testutils"
@@ -38,6 +39,7 @@ func TestChangeDirectoryExecute(t *testing.T) {
 		expectTemplateError    bool
 		expectedExecutionError bool
 		startingDir            string
+		expectedWorkDir        string
 	}{
 		{
 			name:        "Change directory to valid directory",
@@ -84,6 +86,19 @@ func TestChangeDirectoryExecute(t *testing.T) {
 			expectedExecutionError: true,
 			startingDir:            "/home/testuser/",
 		},
+		{
+			name:        "Change directory to relative directory",
+			description: "Change directory relative to the working directory of the TTP",
+			step: &ChangeDirectoryStep{
+				Cd: "sub",
+			},
+			fsysContents: map[string][]byte{
+				"/home/testuser/sub/test": []byte("test"),
+			},
+			stepVars:        map[string]string{},
+			startingDir:     "/home/testuser/",
+			expectedWorkDir: "/home/testuser/sub",
+		},
 		{
 			name:        "Change directory with templated directory",
 			description: "Try to change directory to templated directory and expect successful change of workdir",
@@ -131,7 +146,7 @@ func TestChangeDirectoryExecute(t *testing.T) {
 			execCtx.Vars.StepVars = tc.stepVars
 
 			// validate and check error
-			err = tc.step.Validate(execCtx)
+			err = tc.step.Validate(context.Background(), execCtx)
 
 			if tc.expectedExecutionError && err != nil {
 				require.Error(t, err)
@@ -140,7 +155,7 @@ func TestChangeDirectoryExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// template and check error
-			err = tc.step.Template(execCtx)
+			err = tc.step.Template(context.Background(), execCtx)
 
 			if tc.expectTemplateError && err != nil {
 				require.Error(t, err)
@@ -149,7 +164,7 @@ func TestChangeDirectoryExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// execute and check error
-			_, err = tc.step.Execute(execCtx)
+			_, err = tc.step.Execute(context.Background(), execCtx)
 
 			if tc.expectedExecutionError && err != nil {
 				require.Error(t, err)
@@ -158,12 +173,16 @@ func TestChangeDirectoryExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// check current working directory
-			assert.Equal(t, tc.step.Cd, execCtx.Vars.WorkDir)
+			expectedWorkDir := tc.expectedWorkDir
+			if expectedWorkDir == "" {
+				expectedWorkDir = tc.step.Cd
+			}
+			assert.Equal(t, expectedWorkDir, execCtx.Vars.WorkDir)
 
 			// cleanup and check error
-			err = tc.step.GetDefaultCleanupAction().Validate(execCtx)
+			err = tc.step.GetDefaultCleanupAction().Validate(context.Background(), execCtx)
 			require.NoError(t, err)
-			_, err = tc.step.GetDefaultCleanupAction().Execute(execCtx)
+			_, err = tc.step.GetDefaultCleanupAction().Execute(context.Background(), execCtx)
 			require.NoError(t, err)
 
 			// expect working directory to be rolled back to starting directory
@@ -19,7 +19,10 @@ THE SOFTWARE.
 
 package blocks
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 // CompositeAction is an action that executes multiple actions
 type CompositeAction struct {
@@ -28,12 +31,12 @@ type CompositeAction struct {
 }
 
 // Validate validates the CompositeAction, checking for the necessary attributes and dependencies
-func (ca *CompositeAction) Validate(execCtx TTPExecutionContext) error {
+func (ca *CompositeAction) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	for _, a := range ca.actions {
 		if !a.CanBeUsedInCompositeAction() {
 			return errors.New("cannot use action in composite")
 		}
-		if err := a.Validate(execCtx); err != nil {
+		if err := a.Validate(ctx, execCtx); err != nil {
 			return err
 		}
 	}
@@ -41,9 +44,9 @@ func (ca *CompositeAction) Validate(execCtx TTPExecutionContext) error {
 }
 
 // Template each action in the composite action
-func (ca *CompositeAction) Template(execCtx TTPExecutionContext) error {
+func (ca *CompositeAction) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	for _, a := range ca.actions {
-		if err := a.Template(execCtx); err != nil {
+		if err := a.Template(ctx, execCtx); err != nil {
 			return err
 		}
 	}
@@ -51,9 +54,9 @@ func (ca *CompositeAction) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (ca *CompositeAction) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+func (ca *CompositeAction) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	for _, a := range ca.actions {
-		if _, err := a.Execute(execCtx); err != nil {
+		if _, err := a.Execute(ctx, execCtx); err != nil {
 			return nil, err
 		}
 	}
@@ -20,15 +20,10 @@ THE SOFTWARE.
 package blocks
 
 import (
-	"bytes"
-	"errors"
 	"fmt"
-	"github.com/Masterminds/sprig/v3"
 	"github.com/facebookincubator/ttpforge/pkg/repos"
 	"io"
-	"regexp"
 	"strings"
-	"text/template"
 )
 
 const contextVariablePrefix = "$forge."
@@ -37,48 +32,101 @@ const stepTemplateRightDelim = "}]}"
 
 // TTPExecutionConfig - pass this into RunSteps to control TTP execution
 type TTPExecutionConfig struct {
+	// DryRun is set when the TTP is only loaded and validated
+	// (for example, to print its plan) and will not be executed,
+	// so validating its actions must not run anything
 	DryRun              bool
 	NoCleanup           bool
 	CleanupDelaySeconds uint
 	Repo                repos.Repo
 	Stdout              io.Writer
 	Stderr              io.Writer
+	// Debugger (if set) pauses execution before steps
+	Debugger *Debugger
+	// PluginPaths are the directories searched
+	// for the executables of `plugin:` steps
+	PluginPaths []string
+
+	// loadChain holds the paths of the TTPs that are currently being
+	// loaded (outermost first) so that cyclic sub-TTP references
+	// are detected instead of recursing forever
+	loadChain []string
 }
 
 // TTPExecutionVars - mutable store to carry variables between steps
 type TTPExecutionVars struct {
 	WorkDir  string
 	StepVars map[string]string
+	Args     map[string]any
+	Loop     *LoopVars
+	Run      RunInfo
+	// Env holds environment variables set by earlier steps
+	// (such as the `response:` of an http_request step).
+	// They are passed to every command that the TTP runs
+	// instead of modifying the environment of the process.
+	Env map[string]string
+}
+
+// copy returns a copy of the variables whose StepVars
+// can be modified without affecting the original
+func (v *TTPExecutionVars) copy() *TTPExecutionVars {
+	varsCopy := *v
+	varsCopy.StepVars = make(map[string]string, len(v.StepVars))
+	for k, val := range v.StepVars {
+		varsCopy.StepVars[k] = val
+	}
+	varsCopy.Env = make(map[string]string, len(v.Env))
+	for k, val := range v.Env {
+		varsCopy.Env[k] = val
+	}
+	return &varsCopy
+}
+
+// setEnv sets an environment variable for all
+// commands subsequently run by the TTP
+func (v *TTPExecutionVars) setEnv(name, value string) {
+	if v.Env == nil {
+		v.Env = make(map[string]string)
+	}
+	v.Env[name] = value
 }
 
 // TTPExecutionContext - holds config and context for the currently executing TTP
 type TTPExecutionContext struct {
-	Cfg               TTPExecutionConfig
-	Vars              *TTPExecutionVars
-	StepResults       *StepResultsRecord
-	actionResultsChan chan *ActResult
-	errorsChan        chan error
-	shutdownChan      chan bool
+	Cfg         TTPExecutionConfig
+	Vars        *TTPExecutionVars
+	StepResults *StepResultsRecord
+
+	// state records the progress of the top-level
+	// TTP if a state file was requested
+	state *stateRecorder
+
+	// observers are notified of the progress of the execution
+	observers *observerSet
+
+	// ttp is the TTP whose steps are run with this context,
+	// which observers are notified of
+	ttp *TTP
 }
 
-// NewTTPExecutionContext creates a new TTPExecutionContext with empty config and created channels
+// NewTTPExecutionContext creates a new TTPExecutionContext with empty config
 func NewTTPExecutionContext() TTPExecutionContext {
+	SetupSignalHandler()
 	return TTPExecutionContext{
 		Vars: &TTPExecutionVars{
 			WorkDir:  "/",
 			StepVars: make(map[string]string),
+			Env:      make(map[string]string),
 		},
-		StepResults:       NewStepResultsRecord(),
-		actionResultsChan: make(chan *ActResult, 1),
-		errorsChan:        make(chan error, 1),
-		shutdownChan:      SetupSignalHandler(),
+		StepResults: NewStepResultsRecord(),
 	}
 }
 
-// ExpandVariables takes a string containing the following types of variables
-// and expands all of them to their appropriate values:
-//
-// * Step outputs: ($forge.steps.bar.outputs.baz)
+// ExpandVariables expands the legacy `$forge.steps.bar.stdout` and
+// `$forge.steps.bar.outputs.baz` references in the given strings.
+// Each reference is evaluated as the equivalent step expression
+// (such as `{[{ output "bar" "baz" }]}`) - any other text, including
+// `{[{ }]}` expressions, is left unchanged.
 //
 // **Parameters:**
 //
@@ -89,20 +137,23 @@ func NewTTPExecutionContext() TTPExecutionContext {
 // []string: the corresponding strings with variables expanded
 // error: an error if there is a problem
 func (c TTPExecutionContext) ExpandVariables(inStrs []string) ([]string, error) {
-	re := regexp.MustCompile(
-		`\$*` + regexp.QuoteMeta(contextVariablePrefix) + `[\w\.]*`,
-	)
 	var expandedStrs []string
 	for _, inStr := range inStrs {
 		var failedMatch string
 		var failedMatchError error
-		expandedStr := re.ReplaceAllStringFunc(inStr, func(match string) string {
-			result, err := c.processMatch(match)
+		expandedStr := legacyReferenceRegexp.ReplaceAllStringFunc(inStr, func(match string) string {
+			if strings.HasPrefix(match, "$$") {
+				return strings.TrimPrefix(match, "$")
+			}
+			expr, err := legacyReferenceExpression(match)
+			if err == nil {
+				expr, err = c.evaluate("reference", expr)
+			}
 			if err != nil {
 				failedMatch = match
 				failedMatchError = err
 			}
-			return result
+			return expr
 		})
 		if failedMatchError != nil {
 			return nil, fmt.Errorf("invalid variable expression %v: %v", failedMatch, failedMatchError)
@@ -112,7 +163,8 @@ func (c TTPExecutionContext) ExpandVariables(inStrs []string) ([]string, error)
 	return expandedStrs, nil
 }
 
-// templateStep takes a string and templates it with variables from the context at this point in the TTP
+// templateStep takes a string and evaluates the step expressions
+// (`{[{ }]}`) within it against the state of the TTP at this point.
 //
 // **Parameters:**
 //
@@ -123,75 +175,24 @@ func (c TTPExecutionContext) ExpandVariables(inStrs []string) ([]string, error)
 // string: the templated string
 // error: an error if there is a problem
 func (c TTPExecutionContext) templateStep(input string) (string, error) {
-	tmpl, err := template.New("BasicStep").Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Delims(stepTemplateLeftDelim, stepTemplateRightDelim).Parse(input)
-	if err != nil {
-		return "", err
+	if !c.containsStepTemplating(input) {
+		return input, nil
 	}
-	var output bytes.Buffer
-	err = tmpl.Execute(&output, c.Vars)
+	return c.evaluate("BasicStep", input)
+}
+
+// templateLegacyStep is templateStep for the fields that supported
+// legacy `$forge.steps.` references before step expressions were
+// introduced (commands, their args and env, print_str messages and
+// sub TTP args). Elsewhere `$forge.` is left as literal text.
+func (c TTPExecutionContext) templateLegacyStep(input string) (string, error) {
+	rewritten, err := rewriteLegacyReferences(input)
 	if err != nil {
 		return "", err
 	}
-	return output.String(), nil
+	return c.templateStep(rewritten)
 }
 
 func (c TTPExecutionContext) containsStepTemplating(input string) bool {
 	return strings.Contains(input, stepTemplateLeftDelim)
 }
-
-func (c TTPExecutionContext) processStepsVariable(path string) (string, error) {
-	tokens := strings.Split(path, ".")
-	if len(tokens) < 2 {
-		return "", fmt.Errorf("invalid step result reference: %v", "steps."+path)
-	}
-
-	stepName := tokens[0]
-	stepResult, ok := c.StepResults.ByName[stepName]
-	if !ok {
-		return "", fmt.Errorf("invalid step name in variable path: %v", "steps."+path)
-	}
-
-	fieldSelector := tokens[1]
-	switch fieldSelector {
-	case "stdout":
-		if len(tokens) != 2 {
-			return "", fmt.Errorf("invalid step result reference (should end at stdout): %v", "steps."+path)
-		}
-		return stepResult.Stdout, nil
-	case "outputs":
-		if len(tokens) != 3 {
-			return "", fmt.Errorf("step output reference %v should be exactly one level deep (e.g. steps.foo.outputs.bar)", "steps."+path)
-		}
-		key := tokens[2]
-		val, ok := stepResult.Outputs[key]
-		if !ok {
-			return "", fmt.Errorf("key %v not found in output of step %v", key, stepName)
-		}
-		return val, nil
-	}
-	return "", fmt.Errorf("invalid step result field selector: %v", fieldSelector)
-}
-
-func (c TTPExecutionContext) processMatch(match string) (string, error) {
-	if strings.HasPrefix(match, "$$") {
-		return strings.TrimPrefix(match, "$"), nil
-	}
-	variableSpecifier := strings.TrimPrefix(match, contextVariablePrefix)
-	tokens := strings.Split(variableSpecifier, ".")
-	for _, token := range tokens {
-		// happens if we have a something like {{steps.wut.}} or {{.steps.wut}}
-		if token == "" {
-			return "", errors.New("leading or trailing '.' in variable expression")
-		}
-	}
-	if len(tokens) < 2 {
-		return "", fmt.Errorf("invalid variable expression: %v", match)
-	}
-
-	prefix := tokens[0]
-	path := strings.Join(tokens[1:], ".")
-	if prefix == "steps" {
-		return c.processStepsVariable(path)
-	}
-	return "", fmt.Errorf("invalid variable prefix: %v", prefix)
-}
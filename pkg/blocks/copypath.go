@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/facebookincubator/ttpforge/pkg/logging"
@@ -60,7 +61,7 @@ func (s *CopyPathStep) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies
-func (s *CopyPathStep) Validate(_ TTPExecutionContext) error {
+func (s *CopyPathStep) Validate(_ context.Context, _ TTPExecutionContext) error {
 	if s.Source == "" {
 		return fmt.Errorf("src field cannot be empty")
 	}
@@ -75,7 +76,7 @@ func (s *CopyPathStep) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *CopyPathStep) Template(execCtx TTPExecutionContext) error {
+func (s *CopyPathStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	s.Source, err = execCtx.templateStep(s.Source)
 	if err != nil {
@@ -89,41 +90,50 @@ func (s *CopyPathStep) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (s *CopyPathStep) Execute(_ TTPExecutionContext) (*ActResult, error) {
+func (s *CopyPathStep) Execute(_ context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	logging.L().Infof("Copying file(s) from %v to %v", s.Source, s.Destination)
+	source, destination := s.Source, s.Destination
 	fsys := s.FileSystem
 	if fsys == nil {
 		fsys = afero.NewOsFs()
+		// relative paths are relative to the working directory of the TTP
+		var err error
+		if source, err = FetchAbs(source, execCtx.Vars.WorkDir); err != nil {
+			return nil, err
+		}
+		if destination, err = FetchAbs(destination, execCtx.Vars.WorkDir); err != nil {
+			return nil, err
+		}
 	}
 
 	// check if source exists.
-	sourceExists, err := afero.Exists(fsys, s.Source)
+	sourceExists, err := afero.Exists(fsys, source)
 	if err != nil {
 		return nil, err
 	}
 
 	// if source does not exist.
 	if !sourceExists {
-		return nil, fmt.Errorf("source %v does not exist", s.Source)
+		return nil, fmt.Errorf("source %v does not exist", source)
 	}
 
 	// if source is a directory but recursive is false
-	srcInfo, err := fsys.Stat(s.Source)
+	srcInfo, err := fsys.Stat(source)
 	if err != nil {
 		return nil, err
 	}
 	if srcInfo.IsDir() && !s.Recursive {
-		return nil, fmt.Errorf("source %v is a directory, but the recursive flag is set to false", s.Source)
+		return nil, fmt.Errorf("source %v is a directory, but the recursive flag is set to false", source)
 	}
 
 	// check if destination exists.
-	destExists, err := afero.Exists(fsys, s.Destination)
+	destExists, err := afero.Exists(fsys, destination)
 	if err != nil {
 		return nil, err
 	}
 	// if destination exits, return error if overwrite flag is not true.
 	if destExists && !s.Overwrite {
-		return nil, fmt.Errorf("dest %v already exists and overwrite was not set", s.Destination)
+		return nil, fmt.Errorf("dest %v already exists and overwrite was not set", destination)
 	}
 
 	// use the default umask
@@ -134,7 +144,7 @@ func (s *CopyPathStep) Execute(_ TTPExecutionContext) (*ActResult, error) {
 	}
 
 	// Copy a file
-	err = copy.Copy(s.Source, s.Destination)
+	err = copy.Copy(source, destination)
 	if err != nil {
 		return nil, err
 	}
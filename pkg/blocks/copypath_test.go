@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -147,7 +148,7 @@ func TestCopyPathExecute(t *testing.T) {
 			}
 
 			// template and check error
-			err = copyTestPathStep.Template(execCtx)
+			err = copyTestPathStep.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				require.Error(t, err)
 				return
@@ -155,7 +156,7 @@ func TestCopyPathExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// execute and check error
-			_, err = copyTestPathStep.Execute(execCtx)
+			_, err = copyTestPathStep.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				require.Error(t, err)
 				return
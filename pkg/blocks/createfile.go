@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -59,7 +60,7 @@ func (s *CreateFileStep) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies.
-func (s *CreateFileStep) Validate(_ TTPExecutionContext) error {
+func (s *CreateFileStep) Validate(_ context.Context, _ TTPExecutionContext) error {
 	if s.Path == "" {
 		return fmt.Errorf("path field cannot be empty")
 	}
@@ -71,7 +72,7 @@ func (s *CreateFileStep) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *CreateFileStep) Template(execCtx TTPExecutionContext) error {
+func (s *CreateFileStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	s.Path, err = execCtx.templateStep(s.Path)
 	if err != nil {
@@ -85,19 +86,24 @@ func (s *CreateFileStep) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (s *CreateFileStep) Execute(_ TTPExecutionContext) (*ActResult, error) {
+func (s *CreateFileStep) Execute(_ context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	logging.L().Infof("Creating file %v", s.Path)
+	pathToCreate, err := fileutils.ExpandTilde(s.Path)
+	if err != nil {
+		return nil, err
+	}
 	fsys := s.FileSystem
 	if fsys == nil {
 		fsys = afero.NewOsFs()
+		// relative paths are relative to the working directory of the TTP
+		pathToCreate, err = FetchAbs(pathToCreate, execCtx.Vars.WorkDir)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// check whether path already exists and
 	// whether that is ok given the overwrite flag status
-	pathToCreate, err := fileutils.ExpandTilde(s.Path)
-	if err != nil {
-		return nil, err
-	}
 	exists, err := afero.Exists(fsys, pathToCreate)
 	if err != nil {
 		return nil, err
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -44,6 +45,7 @@ func TestCreateFileExecute(t *testing.T) {
 		stepVars            map[string]string
 		expectTemplateError bool
 		expectExecuteError  bool
+		expectedContents    string
 	}{
 		{
 			name:        "Create Valid File",
@@ -117,6 +119,15 @@ func TestCreateFileExecute(t *testing.T) {
 				"contents": "hello world",
 			},
 		},
+		{
+			name:        "Legacy references are not expanded",
+			description: "$forge. is literal text outside the fields that supported legacy references",
+			step: &CreateFileStep{
+				Path:     "script.sh",
+				Contents: "echo $forge.foo $forge.steps.first.stdout",
+			},
+			expectedContents: "echo $forge.foo $forge.steps.first.stdout",
+		},
 		{
 			name:        "Raises error when template fails",
 			description: "Raise a template error when templated variables don't exist",
@@ -144,7 +155,7 @@ func TestCreateFileExecute(t *testing.T) {
 			execCtx.Vars.StepVars = tc.stepVars
 
 			// template and check error
-			err := tc.step.Template(execCtx)
+			err := tc.step.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				require.Error(t, err)
 				return
@@ -152,7 +163,7 @@ func TestCreateFileExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// execute and check error
-			_, err = tc.step.Execute(execCtx)
+			_, err = tc.step.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				require.Error(t, err)
 				return
@@ -165,6 +176,9 @@ func TestCreateFileExecute(t *testing.T) {
 			contentBytes, err := afero.ReadFile(tc.step.FileSystem, pathToCheck)
 			require.NoError(t, err)
 			assert.Equal(t, tc.step.Contents, string(contentBytes))
+			if tc.expectedContents != "" {
+				assert.Equal(t, tc.expectedContents, string(contentBytes))
+			}
 
 			// check permissions
 			if tc.step.Mode != 0 {
@@ -0,0 +1,329 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// debugAction is the decision made by the user when the debugger pauses
+type debugAction int
+
+const (
+	debugContinue debugAction = iota
+	debugSkip
+	debugRetry
+	debugCleanup
+)
+
+const debuggerBeforeStepHelp = `Commands:
+  c, continue        run this step
+  s, skip            skip this step (it will not be cleaned up)
+  show               print the rendered action of this step again
+  vars               print the current step variables
+  set NAME=VALUE     set a step variable
+  results [STEP]     print the results of all steps or of the named step
+  detach             stop pausing, except at --break-at steps
+  q, cleanup         skip the remaining steps and start cleanup
+  h, help            print this message
+`
+
+const debuggerAfterFailureHelp = `Commands:
+  r, retry           run this step again
+  s, skip            ignore the failure and move on to the next step
+  vars               print the current step variables
+  set NAME=VALUE     set a step variable
+  results [STEP]     print the results of all steps or of the named step
+  q, cleanup         stop the TTP and start cleanup
+  h, help            print this message
+`
+
+// Debugger pauses the execution of a TTP before its steps so that
+// the user can inspect and change its state. It is used by
+// `ttpforge run --interactive` and `ttpforge run --break-at`.
+type Debugger struct {
+	in          *bufio.Scanner
+	out         io.Writer
+	stepping    bool
+	breakpoints map[string]bool
+}
+
+// NewDebugger creates a Debugger that reads commands from in and
+// writes prompts to out.
+//
+// **Parameters:**
+//
+// in: where commands are read from (usually os.Stdin)
+// out: where prompts and inspected state are written (usually os.Stdout)
+// interactive: whether to pause before every step
+// breakAt: the names of the steps before which to pause
+//
+// **Returns:**
+//
+// *Debugger: the configured debugger
+func NewDebugger(in io.Reader, out io.Writer, interactive bool, breakAt []string) *Debugger {
+	breakpoints := make(map[string]bool)
+	for _, name := range breakAt {
+		breakpoints[name] = true
+	}
+	return &Debugger{
+		in:          bufio.NewScanner(in),
+		out:         out,
+		stepping:    interactive,
+		breakpoints: breakpoints,
+	}
+}
+
+// CheckBreakpoints verifies that every breakpoint
+// refers to one of the steps of the TTP
+func (d *Debugger) CheckBreakpoints(ttp *TTP) error {
+	stepNames := make(map[string]bool)
+	for _, step := range ttp.Steps {
+		stepNames[step.Name] = true
+	}
+	for name := range d.breakpoints {
+		if !stepNames[name] {
+			return fmt.Errorf("cannot break at step %q: TTP %q has no such step", name, ttp.Name)
+		}
+	}
+	return nil
+}
+
+// beforeStep pauses before the step if requested
+// and returns what should be done with the step
+func (d *Debugger) beforeStep(ctx context.Context, stepIdx int, step *Step, execCtx TTPExecutionContext) debugAction {
+	if !d.stepping && !d.breakpoints[step.Name] {
+		return debugContinue
+	}
+	fmt.Fprintf(d.out, "\nPaused before step #%d: %q\n", stepIdx+1, step.Name)
+	d.showStep(ctx, step, execCtx)
+	for {
+		command, ok := d.prompt()
+		if !ok {
+			logging.L().Warn("No more debugger input - running the remaining steps without pausing")
+			d.stepping = false
+			d.breakpoints = nil
+			return debugContinue
+		}
+		switch command {
+		case "c", "continue":
+			return debugContinue
+		case "s", "skip":
+			return debugSkip
+		case "q", "cleanup":
+			return debugCleanup
+		case "detach":
+			d.stepping = false
+			return debugContinue
+		case "show":
+			d.showStep(ctx, step, execCtx)
+		case "h", "help":
+			fmt.Fprint(d.out, debuggerBeforeStepHelp)
+		default:
+			if !d.inspect(ctx, command, execCtx) {
+				fmt.Fprintf(d.out, "Unknown command %q\n%s", command, debuggerBeforeStepHelp)
+			}
+		}
+	}
+}
+
+// afterStepFailure asks the user what to do about a failed step
+func (d *Debugger) afterStepFailure(ctx context.Context, stepIdx int, step *Step, stepErr error, execCtx TTPExecutionContext) debugAction {
+	fmt.Fprintf(d.out, "\nStep #%d: %q failed: %v\n", stepIdx+1, step.Name, stepErr)
+	for {
+		command, ok := d.prompt()
+		if !ok {
+			return debugCleanup
+		}
+		switch command {
+		case "r", "retry":
+			return debugRetry
+		case "s", "skip":
+			return debugSkip
+		case "q", "cleanup":
+			return debugCleanup
+		case "h", "help":
+			fmt.Fprint(d.out, debuggerAfterFailureHelp)
+		default:
+			if !d.inspect(ctx, command, execCtx) {
+				fmt.Fprintf(d.out, "Unknown command %q\n%s", command, debuggerAfterFailureHelp)
+			}
+		}
+	}
+}
+
+// prompt reads the next non-empty command - it returns false
+// if there is no more input
+func (d *Debugger) prompt() (string, bool) {
+	for {
+		fmt.Fprint(d.out, "(ttpforge) ")
+		if !d.in.Scan() {
+			fmt.Fprintln(d.out)
+			return "", false
+		}
+		if command := strings.TrimSpace(d.in.Text()); command != "" {
+			return command, true
+		}
+	}
+}
+
+// inspect handles the commands that are shared by all prompts -
+// it returns false if the command is not one of them
+func (d *Debugger) inspect(ctx context.Context, command string, execCtx TTPExecutionContext) bool {
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+	switch name {
+	case "vars":
+		d.showVars(execCtx)
+	case "set":
+		varName, value, found := strings.Cut(arg, "=")
+		if !found || varName == "" {
+			fmt.Fprintln(d.out, "Usage: set NAME=VALUE")
+			return true
+		}
+		execCtx.Vars.StepVars[varName] = value
+		fmt.Fprintf(d.out, "Set step variable %q to %q\n", varName, value)
+	case "results":
+		d.showResults(arg, execCtx)
+	default:
+		return false
+	}
+	return true
+}
+
+// showStep prints the step's action (and cleanup)
+// as they will look after templating
+func (d *Debugger) showStep(ctx context.Context, step *Step, execCtx TTPExecutionContext) {
+	rendered, err := step.renderPreview(ctx, execCtx)
+	if err != nil {
+		fmt.Fprintf(d.out, "Could not render step: %v\n", err)
+		return
+	}
+	fmt.Fprint(d.out, rendered)
+}
+
+func (d *Debugger) showVars(execCtx TTPExecutionContext) {
+	if len(execCtx.Vars.StepVars) == 0 {
+		fmt.Fprintln(d.out, "No step variables are set")
+		return
+	}
+	names := make([]string, 0, len(execCtx.Vars.StepVars))
+	for name := range execCtx.Vars.StepVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(d.out, "%v=%v\n", name, execCtx.Vars.StepVars[name])
+	}
+}
+
+func (d *Debugger) showResults(stepName string, execCtx TTPExecutionContext) {
+	if stepName == "" {
+		if len(execCtx.StepResults.ByIndex) == 0 {
+			fmt.Fprintln(d.out, "No steps have run yet")
+			return
+		}
+		for idx, result := range execCtx.StepResults.ByIndex {
+			status := fmt.Sprintf("exit code %d", result.ExitCode)
+			if result.Skipped {
+				status = "skipped"
+			} else if result.Error != nil {
+				status = fmt.Sprintf("failed (ignored): %v", result.Error)
+			}
+			fmt.Fprintf(d.out, "#%d %q: %v\n", idx+1, result.Name, status)
+		}
+		return
+	}
+
+	for idx, result := range execCtx.StepResults.ByIndex {
+		if result.Name != stepName {
+			continue
+		}
+		resultBytes, err := json.MarshalIndent(newReportStep(idx, result.Name, result), "", "  ")
+		if err != nil {
+			fmt.Fprintf(d.out, "Could not serialize results: %v\n", err)
+			return
+		}
+		fmt.Fprintln(d.out, string(resultBytes))
+		return
+	}
+	fmt.Fprintf(d.out, "Step %q has not run yet\n", stepName)
+}
+
+// renderPreview returns the YAML of the step's action and cleanup
+// after templating, without affecting the step itself
+func (s *Step) renderPreview(ctx context.Context, execCtx TTPExecutionContext) (string, error) {
+	if s.node == nil {
+		return "", nil
+	}
+	if s.Loop != nil {
+		// iterations are templated separately
+		// once their loop items are known
+		raw, err := yaml.Marshal(s.node)
+		if err != nil {
+			return "", err
+		}
+		return string(raw), nil
+	}
+
+	action, cleanup, err := s.parseActions(s.node)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, a := range []Action{action, cleanup} {
+		if a == nil {
+			continue
+		}
+		if err := a.Validate(ctx, execCtx); err != nil {
+			return "", err
+		}
+	}
+	// the cleanup is only templated once the step has run
+	if err := action.Template(ctx, execCtx); err != nil {
+		return "", err
+	}
+	actionBytes, err := yaml.Marshal(action)
+	if err != nil {
+		return "", err
+	}
+	sb.Write(actionBytes)
+	if cleanup != nil {
+		cleanupBytes, err := yaml.Marshal(cleanup)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("cleanup:\n")
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(cleanupBytes), "\n"), "\n") {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
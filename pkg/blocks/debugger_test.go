@@ -0,0 +1,213 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugger(t *testing.T) {
+	testCases := []struct {
+		name             string
+		content          string
+		interactive      bool
+		breakAt          []string
+		input            string
+		expectedStdout   string
+		expectedResults  int
+		expectedOutput   []string
+		unexpectedOutput []string
+		wantError        bool
+	}{
+		{
+			name: "Continue and skip steps",
+			content: `name: debugger_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: second
+    inline: echo second
+    cleanup:
+      inline: echo cleanup second
+  - name: third
+    inline: echo third`,
+			interactive:    true,
+			input:          "c\nskip\ncontinue\n",
+			expectedStdout: "first\nthird\ncleanup first\n",
+			expectedOutput: []string{
+				`Paused before step #1: "first"`,
+				`Paused before step #2: "second"`,
+				`Paused before step #3: "third"`,
+			},
+		},
+		{
+			name: "Rendered action and edited step variables",
+			content: `name: debugger_test
+steps:
+  - name: first
+    inline: echo hello
+    outputvar: greeting
+  - name: second
+    inline: echo "{[{ .StepVars.greeting }]} {[{ .StepVars.target }]}"`,
+			breakAt:        []string{"second"},
+			input:          "set target=world\nshow\nvars\nc\n",
+			expectedStdout: "hello\nhello world\n",
+			expectedOutput: []string{
+				`Paused before step #2: "second"`,
+				`inline: echo "hello world"`,
+				"greeting=hello\ntarget=world\n",
+			},
+			unexpectedOutput: []string{
+				`Paused before step #1`,
+			},
+		},
+		{
+			name: "Inspect results and jump to cleanup",
+			content: `name: debugger_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: second
+    inline: echo second`,
+			breakAt:        []string{"second"},
+			input:          "results\nresults first\ncleanup\n",
+			expectedStdout: "first\ncleanup first\n",
+			expectedOutput: []string{
+				`#1 "first": exit code 0`,
+				`"stdout": "first\n"`,
+			},
+		},
+		{
+			name: "Retry a failed step",
+			content: `name: debugger_test
+steps:
+  - name: flaky
+    inline: |
+      if [ -f MARKER ]; then echo succeeded; else touch MARKER && exit 1; fi`,
+			interactive:    true,
+			input:          "c\nretry\n",
+			expectedStdout: "succeeded\n",
+			expectedOutput: []string{
+				`Step #1: "flaky" failed`,
+			},
+		},
+		{
+			name: "Retry a step whose cleanup could not be templated",
+			content: `name: debugger_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup {[{ .StepVars.target }]}
+  - name: second
+    inline: echo second`,
+			interactive:     true,
+			input:           "c\nset target=world\nretry\nc\n",
+			expectedStdout:  "first\nfirst\nsecond\ncleanup world\n",
+			expectedResults: 2,
+			expectedOutput: []string{
+				`Step #1: "first" failed`,
+			},
+		},
+		{
+			name: "Ignore a failed step",
+			content: `name: debugger_test
+steps:
+  - name: fails
+    inline: exit 1
+    cleanup:
+      inline: echo cleanup fails
+  - name: after
+    inline: echo after`,
+			breakAt:        []string{"fails"},
+			input:          "c\nskip\n",
+			expectedStdout: "after\n",
+		},
+		{
+			name: "Failure without further input stops the TTP",
+			content: `name: debugger_test
+steps:
+  - name: fails
+    inline: exit 1
+  - name: after
+    inline: echo after`,
+			interactive: true,
+			input:       "c\n",
+			wantError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			markerPath := filepath.Join(t.TempDir(), "marker")
+			content := strings.ReplaceAll(tc.content, "MARKER", markerPath)
+			ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf, debuggerBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			execCtx.Cfg.Debugger = NewDebugger(strings.NewReader(tc.input), &debuggerBuf, tc.interactive, tc.breakAt)
+			require.NoError(t, execCtx.Cfg.Debugger.CheckBreakpoints(ttp))
+			require.NoError(t, ttp.Validate(execCtx))
+
+			err = ttp.Execute(execCtx)
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, ttp.RunCleanup(execCtx))
+			if !tc.wantError {
+				assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+			}
+			if tc.expectedResults > 0 {
+				assert.Len(t, execCtx.StepResults.ByIndex, tc.expectedResults)
+			}
+			for _, expected := range tc.expectedOutput {
+				assert.Contains(t, debuggerBuf.String(), expected)
+			}
+			for _, unexpected := range tc.unexpectedOutput {
+				assert.NotContains(t, debuggerBuf.String(), unexpected)
+			}
+		})
+	}
+}
+
+func TestDebuggerCheckBreakpoints(t *testing.T) {
+	ttp, err := RenderTemplatedTTP(`name: debugger_test
+steps:
+  - name: first
+    inline: echo first`, RenderParameters{})
+	require.NoError(t, err)
+
+	assert.NoError(t, NewDebugger(strings.NewReader(""), &bytes.Buffer{}, false, []string{"first"}).CheckBreakpoints(ttp))
+	assert.Error(t, NewDebugger(strings.NewReader(""), &bytes.Buffer{}, false, []string{"missing"}).CheckBreakpoints(ttp))
+}
@@ -20,19 +20,24 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/spf13/afero"
 )
 
 // Edit represents a single old+new find-and-replace pair
 type Edit struct {
-	Old    string `yaml:"old,omitempty"`
-	New    string `yaml:"new,omitempty"`
-	Append string `yaml:"append,omitempty"`
-	Delete string `yaml:"delete,omitempty"`
-	Regexp bool   `yaml:"regexp,omitempty"`
+	// Description documents the purpose of the edit and
+	// is included in the error if the edit cannot be applied
+	Description string `yaml:"description,omitempty"`
+	Old         string `yaml:"old,omitempty"`
+	New         string `yaml:"new,omitempty"`
+	Append      string `yaml:"append,omitempty"`
+	Delete      string `yaml:"delete,omitempty"`
+	Regexp      bool   `yaml:"regexp,omitempty"`
 
 	oldRegexp *regexp.Regexp
 }
@@ -62,7 +67,7 @@ func (s *EditStep) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies
-func (s *EditStep) Validate(execCtx TTPExecutionContext) error {
+func (s *EditStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	if len(s.Edits) == 0 {
 		return fmt.Errorf("no edits specified")
 	}
@@ -126,7 +131,7 @@ func (s *EditStep) Validate(execCtx TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *EditStep) Template(execCtx TTPExecutionContext) error {
+func (s *EditStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	s.FileToEdit, err = execCtx.templateStep(s.FileToEdit)
 	if err != nil {
@@ -140,7 +145,7 @@ func (s *EditStep) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (s *EditStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+func (s *EditStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	fileSystem := s.FileSystem
 	targetPath := s.FileToEdit
 	backupPath := s.BackupFile
@@ -188,10 +193,14 @@ func (s *EditStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
 		// failures if the format of the file they're trying to edit changes
 		// and their regexes no longer work
 		if len(matches) == 0 {
+			editName := fmt.Sprintf("edit #%d", editIdx+1)
+			if edit.Description != "" {
+				editName += fmt.Sprintf(" (%v)", strings.TrimSpace(edit.Description))
+			}
 			return nil, fmt.Errorf(
-				"pattern '%v' from edit #%d was not found in file %v",
+				"pattern '%v' from %v was not found in file %v",
 				edit.Old,
-				editIdx+1,
+				editName,
 				s.FileToEdit,
 			)
 		}
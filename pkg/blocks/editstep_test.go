@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/facebookincubator/ttpforge/pkg/testutils"
@@ -165,6 +166,18 @@ edits:
 			expectExecuteError: true,
 			expectedErrTxt:     "pattern 'not_going_to_find_this' from edit #1 was not found in file b.txt",
 		},
+		{
+			name: "Test Execute Not Found With Description",
+			content: `name: delete_function
+edit_file: b.txt
+edits:
+  - description: remove the marker
+    old: not_going_to_find_this
+    new: will_not_be_used`,
+			fsysContents:       map[string][]byte{"b.txt": []byte("not_goung_to_find_this")},
+			expectExecuteError: true,
+			expectedErrTxt:     "pattern 'not_going_to_find_this' from edit #1 (remove the marker) was not found in file b.txt",
+		},
 		{
 			name: "Test Append Old",
 			content: `name: test_append_old
@@ -303,7 +316,7 @@ edits:
 			require.NoError(t, err)
 
 			// validate the step
-			err = editStep.Validate(execCtx)
+			err = editStep.Validate(context.Background(), execCtx)
 			if tc.expectValidateError {
 				assert.Equal(t, tc.expectedErrTxt, err.Error())
 				return
@@ -311,7 +324,7 @@ edits:
 			require.NoError(t, err)
 
 			// template the step
-			err = editStep.Template(execCtx)
+			err = editStep.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				assert.Equal(t, tc.expectedErrTxt, err.Error())
 				return
@@ -330,7 +343,7 @@ edits:
 			require.NoError(t, err)
 
 			// execute the step and check output
-			_, err = editStep.Execute(execCtx)
+			_, err = editStep.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				assert.Equal(t, tc.expectedErrTxt, err.Error())
 				return
@@ -87,27 +87,14 @@ func (e *ScriptExecutor) buildCommand(ctx context.Context) *exec.Cmd {
 
 // Execute runs the command
 func (e *ScriptExecutor) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
-	// expand variables in command
-	expandedInlines, err := execCtx.ExpandVariables([]string{e.Inline})
-	if err != nil {
-		return nil, err
-	}
-
-	body := expandedInlines[0]
+	body := e.Inline
 	if e.Name == ExecutorPowershellOnLinux || e.Name == ExecutorPowershell {
 		// Wrap the PowerShell command in a script block
 		body = fmt.Sprintf("$ErrorActionPreference = 'Stop' ; &{%s}\n\n", body)
 	}
 
-	// expand variables in environment
-	envAsList := append(FetchEnv(e.Environment), os.Environ()...)
-	expandedEnvAsList, err := execCtx.ExpandVariables(envAsList)
-	if err != nil {
-		return nil, err
-	}
-
 	cmd := e.buildCommand(ctx)
-	cmd.Env = expandedEnvAsList
+	cmd.Env = execCtx.commandEnv(e.Environment)
 	cmd.Dir = execCtx.Vars.WorkDir
 	cmd.Stdin = strings.NewReader(body)
 
@@ -116,32 +103,30 @@ func (e *ScriptExecutor) Execute(ctx context.Context, execCtx TTPExecutionContex
 
 // Execute runs the binary with arguments
 func (e *FileExecutor) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
-	// expand variables in command line arguments
-	expandedArgs, err := execCtx.ExpandVariables(e.Args)
-	if err != nil {
-		return nil, err
-	}
-
-	// expand variables in environment
-	envAsList := append(FetchEnv(e.Environment), os.Environ()...)
-	expandedEnvAsList, err := execCtx.ExpandVariables(envAsList)
-	if err != nil {
-		return nil, err
-	}
-
 	var cmd *exec.Cmd
 	if e.Name == ExecutorBinary {
-		cmd = exec.CommandContext(ctx, e.FilePath, expandedArgs...)
+		cmd = exec.CommandContext(ctx, e.FilePath, e.Args...)
 	} else {
-		args := append([]string{e.FilePath}, expandedArgs...)
+		args := append([]string{e.FilePath}, e.Args...)
 		cmd = exec.CommandContext(ctx, e.Name, args...)
 	}
 
-	cmd.Env = expandedEnvAsList
+	cmd.Env = execCtx.commandEnv(e.Environment)
 	cmd.Dir = execCtx.Vars.WorkDir
 	return streamAndCapture(*cmd, execCtx.Cfg.Stdout, execCtx.Cfg.Stderr)
 }
 
+// commandEnv returns the environment of a command run by a step:
+// the environment of the process, overridden by the variables set
+// by earlier steps of the TTP and then by the `env:` of the step itself.
+func (c TTPExecutionContext) commandEnv(stepEnv map[string]string) []string {
+	env := os.Environ()
+	if c.Vars != nil {
+		env = append(env, FetchEnv(c.Vars.Env)...)
+	}
+	return append(env, FetchEnv(stepEnv)...)
+}
+
 // InferExecutor infers the executor based on the file extension and
 // returns it as a string.
 func InferExecutor(filePath string) string {
@@ -73,3 +73,44 @@ func TestBashExecutor(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutorEnvironment(t *testing.T) {
+	t.Setenv("TTPFORGE_ENV_TEST", "process")
+
+	testCases := []struct {
+		name           string
+		ttpEnv         map[string]string
+		stepEnv        map[string]string
+		expectedResult string
+	}{
+		{
+			name:           "process environment",
+			expectedResult: "process\n",
+		},
+		{
+			name:           "TTP environment overrides the process",
+			ttpEnv:         map[string]string{"TTPFORGE_ENV_TEST": "ttp"},
+			expectedResult: "ttp\n",
+		},
+		{
+			name:           "step environment overrides the TTP",
+			ttpEnv:         map[string]string{"TTPFORGE_ENV_TEST": "ttp"},
+			stepEnv:        map[string]string{"TTPFORGE_ENV_TEST": "step"},
+			expectedResult: "step\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			execCtx := TTPExecutionContext{Vars: &TTPExecutionVars{Env: tc.ttpEnv}}
+			executor := NewExecutor("bash", "echo $TTPFORGE_ENV_TEST", "", []string{}, tc.stepEnv)
+			result, err := executor.Execute(context.Background(), execCtx)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if result.Stdout != tc.expectedResult {
+				t.Fatalf("expected output %#v, got %#v", tc.expectedResult, result.Stdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"gopkg.in/yaml.v3"
+)
+
+// ExitCodes is the set of exit codes with which
+// a step's command is considered to have succeeded.
+// It may be specified in YAML as a single integer or as a list.
+type ExitCodes []int
+
+// UnmarshalYAML accepts both `expect_exit_code: 1` and `expect_exit_code: [0, 1]`
+func (codes *ExitCodes) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var code int
+		if err := node.Decode(&code); err != nil {
+			return fmt.Errorf("invalid exit code: %w", err)
+		}
+		*codes = ExitCodes{code}
+		return nil
+	}
+	var list []int
+	if err := node.Decode(&list); err != nil {
+		return fmt.Errorf("exit codes must be an integer or a list of integers: %w", err)
+	}
+	if len(list) == 0 {
+		return errors.New("at least one exit code must be specified")
+	}
+	*codes = list
+	return nil
+}
+
+// checkExitCode decides whether the execution of the step's action succeeded
+// based on its exit code. If the step does not specify `expect_exit_code:`,
+// the result and error of the action are returned unchanged.
+func (s *Step) checkExitCode(result *ActResult, err error) (*ActResult, error) {
+	if s.ExpectExitCode == nil || result == nil {
+		return result, err
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		// the command did not get to exit on its own
+		return result, err
+	}
+	if !slices.Contains(s.ExpectExitCode, result.ExitCode) {
+		return result, fmt.Errorf("step %q exited with code %d but expected one of %v", s.Name, result.ExitCode, []int(s.ExpectExitCode))
+	}
+	if err != nil {
+		logging.L().Infof("Step %q exited with expected code %d", s.Name, result.ExitCode)
+	}
+	return result, nil
+}
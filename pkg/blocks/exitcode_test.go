@@ -0,0 +1,202 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExitCodesUnmarshalYAML(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		expected  ExitCodes
+		wantError bool
+	}{
+		{
+			name:     "Single exit code",
+			content:  "1",
+			expected: ExitCodes{1},
+		},
+		{
+			name:     "List of exit codes",
+			content:  "[0, 137]",
+			expected: ExitCodes{0, 137},
+		},
+		{
+			name:      "Empty list",
+			content:   "[]",
+			wantError: true,
+		},
+		{
+			name:      "Not an integer",
+			content:   "blocked",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var codes ExitCodes
+			err := yaml.Unmarshal([]byte(tc.content), &codes)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, codes)
+		})
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           string
+		expectedStdout    string
+		expectedError     string
+		wantValidateErr   bool
+		checkStep         string
+		expectedExitCode  int
+		expectedOutputs   map[string]string
+		expectIgnoredErr  bool
+		expectedStepCount int
+	}{
+		{
+			name: "Expected non-zero exit code succeeds",
+			content: `name: exit_code_test
+steps:
+  - name: blocked
+    inline: echo blocked && exit 3
+    expect_exit_code: 3
+  - name: after
+    inline: echo after`,
+			expectedStdout:    "blocked\nafter\n",
+			checkStep:         "blocked",
+			expectedExitCode:  3,
+			expectedStepCount: 2,
+		},
+		{
+			name: "Zero exit code fails if not expected",
+			content: `name: exit_code_test
+steps:
+  - name: not_blocked
+    inline: echo not blocked
+    expect_exit_code: [1, 137]
+  - name: never
+    inline: echo never`,
+			expectedStdout: "not blocked\n",
+			expectedError:  `step "not_blocked" exited with code 0 but expected one of [1 137]`,
+		},
+		{
+			name: "Failed step with continue_on_error keeps its output",
+			content: `name: exit_code_test
+steps:
+  - name: fails
+    inline: echo '{"foo":"bar"}' && exit 5
+    continue_on_error: true
+    outputs:
+      foo:
+        filters:
+        - json_path: foo
+  - name: after
+    inline: echo after`,
+			expectedStdout:    "{\"foo\":\"bar\"}\nafter\n",
+			checkStep:         "fails",
+			expectedExitCode:  5,
+			expectedOutputs:   map[string]string{"foo": "bar"},
+			expectIgnoredErr:  true,
+			expectedStepCount: 2,
+		},
+		{
+			name: "Unexpected exit code with continue_on_error",
+			content: `name: exit_code_test
+steps:
+  - name: loop
+    loop: [0, 2, 4]
+    inline: echo {[{ .Loop.Item }]} && exit {[{ .Loop.Item }]}
+    expect_exit_code: [0, 2]
+    continue_on_error: true
+  - name: after
+    inline: echo after`,
+			expectedStdout:    "0\n2\n4\nafter\n",
+			checkStep:         "loop",
+			expectIgnoredErr:  true,
+			expectedStepCount: 2,
+		},
+		{
+			name: "Expected exit code on an action without exit codes",
+			content: `name: exit_code_test
+steps:
+  - name: create
+    create_file: /tmp/ttpforge-exit-code-test
+    contents: foo
+    expect_exit_code: 1`,
+			wantValidateErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			err = ttp.Validate(execCtx)
+			if tc.wantValidateErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			err = ttp.Execute(execCtx)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+			if tc.checkStep == "" {
+				return
+			}
+
+			assert.Len(t, execCtx.StepResults.ByIndex, tc.expectedStepCount)
+			result := execCtx.StepResults.ByName[tc.checkStep]
+			require.NotNil(t, result)
+			assert.Equal(t, tc.expectedExitCode, result.ExitCode)
+			if tc.expectedOutputs != nil {
+				assert.Equal(t, tc.expectedOutputs, result.Outputs)
+			}
+			if tc.expectIgnoredErr {
+				assert.Error(t, result.Error)
+			} else {
+				assert.NoError(t, result.Error)
+			}
+		})
+	}
+}
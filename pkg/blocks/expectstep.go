@@ -105,7 +105,7 @@ func (s *ExpectStep) IsNil() bool {
 // **Returns:**
 //
 // error: An error if validation fails.
-func (s *ExpectStep) Validate(_ TTPExecutionContext) error {
+func (s *ExpectStep) Validate(_ context.Context, _ TTPExecutionContext) error {
 	if s.Expect == nil {
 		return fmt.Errorf("expectStep is nil")
 	}
@@ -132,7 +132,7 @@ func (s *ExpectStep) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *ExpectStep) Template(execCtx TTPExecutionContext) error {
+func (s *ExpectStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	s.Chdir, err = execCtx.templateStep(s.Chdir)
 	if err != nil {
@@ -170,29 +170,14 @@ func (s *ExpectStep) Template(execCtx TTPExecutionContext) error {
 //
 // *ActResult: A pointer to the action result.
 // error: An error if execution fails.
-func (s *ExpectStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+func (s *ExpectStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	if s == nil || s.Expect == nil {
 		return nil, fmt.Errorf("expect block must be provided")
 	}
 
-	originalDir, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get current directory: %w", err)
-	}
-	if err := s.Validate(execCtx); err != nil {
+	if err := s.Validate(ctx, execCtx); err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := os.Chdir(originalDir); err != nil {
-			fmt.Printf("failed to change back to original directory: %v\n", err)
-		}
-	}()
-
-	if s.Chdir != "" {
-		if err := os.Chdir(s.Chdir); err != nil {
-			return nil, fmt.Errorf("failed to change directory: %w", err)
-		}
-	}
 
 	console, err := expect.NewConsole(expect.WithStdout(os.Stdout), expect.WithStdin(os.Stdin))
 	if err != nil {
@@ -200,23 +185,19 @@ func (s *ExpectStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
 	}
 	defer console.Close()
 
-	if s.Environment != nil {
-		for k, v := range s.Environment {
-			if err := os.Setenv(k, v); err != nil {
-				return nil, fmt.Errorf("failed to set environment variable: %w", err)
-			}
-		}
+	envAsList := execCtx.commandEnv(s.Environment)
+	cmd, err := s.prepareCommand(ctx, execCtx, envAsList, s.Expect.Inline)
+	if err != nil {
+		return nil, err
 	}
-
-	envAsList := os.Environ()
-	cmd := s.prepareCommand(context.Background(), execCtx, envAsList, s.Expect.Inline)
 	cmd.Stdin = console.Tty()
 	cmd.Stdout = console.Tty()
 	cmd.Stderr = console.Tty()
 
-	if err := cmd.Start(); err != nil {
+	if err := startTrackedProcess(cmd); err != nil {
 		return nil, fmt.Errorf("failed to start command: %w", err)
 	}
+	defer untrackProcess(cmd)
 
 	done := make(chan error, 1)
 	go func() {
@@ -260,8 +241,8 @@ func (s *ExpectStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
 		if err != nil {
 			return nil, err
 		}
-	case <-time.After(120 * time.Second):
-		return nil, fmt.Errorf("command timed out")
+	case <-ctx.Done():
+		return nil, fmt.Errorf("command timed out: %w", ctx.Err())
 	}
 
 	if _, err := console.ExpectEOF(); err != nil {
@@ -284,13 +265,23 @@ func (s *ExpectStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
 // **Returns:**
 //
 // *exec.Cmd: The prepared command.
-func (s *ExpectStep) prepareCommand(ctx context.Context, execCtx TTPExecutionContext, envAsList []string, inline string) *exec.Cmd {
+// error: An error if the `chdir:` directory cannot be resolved.
+func (s *ExpectStep) prepareCommand(ctx context.Context, execCtx TTPExecutionContext, envAsList []string, inline string) (*exec.Cmd, error) {
 	/* #nosec G204 */
 	cmd := exec.CommandContext(ctx, s.Executor, "-c", inline)
 	cmd.Env = envAsList
 	cmd.Dir = execCtx.Vars.WorkDir
 
-	return cmd
+	// the chdir of the step only applies to its command
+	// (relative paths are resolved against the working directory)
+	if s.Chdir != "" {
+		dir, err := FetchAbs(s.Chdir, execCtx.Vars.WorkDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve chdir %q: %w", s.Chdir, err)
+		}
+		cmd.Dir = dir
+	}
+	return cmd, nil
 }
 
 // CanBeUsedInCompositeAction enables this action to be used in a composite
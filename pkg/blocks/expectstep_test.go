@@ -169,7 +169,7 @@ steps:
 			}
 
 			if tc.wantValidateError {
-				validateErr := config.Steps[0].Expect.Validate(TTPExecutionContext{})
+				validateErr := config.Steps[0].Expect.Validate(context.Background(), TTPExecutionContext{})
 				if validateErr == nil || validateErr.Error() != tc.expectedErrTxt {
 					t.Fatalf("Validate() error = %v, expectedErrTxt %v", validateErr, tc.expectedErrTxt)
 				}
@@ -452,7 +452,7 @@ steps:
 			require.NotNil(t, expectStep, "expectStep is nil")
 
 			// validate and check error
-			err = expectStep.Validate(execCtx)
+			err = expectStep.Validate(context.Background(), execCtx)
 			if tc.wantValidateError {
 				assert.Equal(t, tc.expectedErrTxt, err.Error())
 				return
@@ -460,7 +460,7 @@ steps:
 			require.NoError(t, err)
 
 			// template and check error
-			err = expectStep.Template(execCtx)
+			err = expectStep.Template(context.Background(), execCtx)
 			if tc.wantTemplateError {
 				assert.Equal(t, tc.expectedErrTxt, err.Error())
 				return
@@ -495,7 +495,7 @@ steps:
 					console.Tty().Close() // Close the tcY to signal EOF
 				}()
 
-				_, err = expectStep.Execute(execCtx)
+				_, err = expectStep.Execute(context.Background(), execCtx)
 				require.NoError(t, err)
 				<-done
 
@@ -540,7 +540,7 @@ steps:
 					console.Tty().Close() // Close the tcY to signal EOF
 				}()
 
-				_, err = expectStep.Execute(execCtx)
+				_, err = expectStep.Execute(context.Background(), execCtx)
 				require.NoError(t, err)
 				<-done
 
@@ -594,7 +594,7 @@ steps:
 					console.Tty().Close() // Close the tcY to signal EOF
 				}()
 
-				_, err = expectStep.Execute(execCtx)
+				_, err = expectStep.Execute(context.Background(), execCtx)
 				require.NoError(t, err)
 				<-done
 
@@ -622,8 +622,8 @@ steps:
 }
 
 // Execute is a mock implementation of the Execute method.
-func (m *MockExpectStep) Execute(ctx TTPExecutionContext) (string, error) {
-	args := m.Called(ctx)
+func (m *MockExpectStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (string, error) {
+	args := m.Called(ctx, execCtx)
 	return args.String(0), args.Error(1)
 }
 
@@ -704,7 +704,7 @@ func TestExpectSSH(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockStep := new(MockExpectStep)
-			mockStep.On("Execute", mock.Anything).Return(tc.mockRet, tc.mockErr)
+			mockStep.On("Execute", mock.Anything, mock.Anything).Return(tc.mockRet, tc.mockErr)
 
 			execCtx := TTPExecutionContext{
 				Vars: &TTPExecutionVars{
@@ -712,7 +712,7 @@ func TestExpectSSH(t *testing.T) {
 				},
 			}
 			fmt.Println("Executing command:", tc.step.Expect.Inline)
-			_, err := mockStep.Execute(execCtx)
+			_, err := mockStep.Execute(context.Background(), execCtx)
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Execute() error = %v, wantErr %v", err, tc.wantErr)
 			}
@@ -0,0 +1,348 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/facebookincubator/ttpforge/pkg/platforms"
+)
+
+// ExpressionScope contains the values that can be referenced from
+// step templates (`{[{ }]}`) and from the `if:` conditions of steps,
+// for example:
+//
+//	inline: echo {[{ .Steps.whoami.Outputs.user }]}
+//	if: eq (exitCode "probe") 0
+//
+// Args: the TTP argument values (with their declared types)
+// Env: the environment variables of the TTPForge process
+// Steps: the results of the steps that have already run, by step name
+// StepVars: variables set by earlier steps via `outputvar`
+// Platform: the OS and architecture of the current platform
+// Loop: the current loop item and index (only set inside loops)
+// WorkDir: the working directory of the TTP
+// Run: metadata about the current TTP run
+type ExpressionScope struct {
+	Args     map[string]any
+	Env      map[string]string
+	Steps    map[string]StepScope
+	StepVars map[string]string
+	Platform platforms.Spec
+	Loop     *LoopVars
+	WorkDir  string
+	Run      RunInfo
+}
+
+// StepScope exposes the results of a step that has already run.
+// Error is set if the step failed but has `continue_on_error:` set.
+type StepScope struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Outputs  map[string]string
+	Skipped  bool
+	Error    string
+}
+
+// RunInfo describes the TTP that is currently running
+type RunInfo struct {
+	TTP       string
+	Path      string
+	StartTime time.Time
+	DryRun    bool
+}
+
+// legacyReferenceRegexp matches the `$forge.steps.x.outputs.y`
+// syntax that predates step templating
+var legacyReferenceRegexp = regexp.MustCompile(
+	`\$*` + regexp.QuoteMeta(contextVariablePrefix) + `[\w\.]*`,
+)
+
+// legacyConditionReferenceRegexp matches legacy references
+// in `if:` expressions along with any surrounding quotes
+var legacyConditionReferenceRegexp = regexp.MustCompile(
+	`"?` + legacyReferenceRegexp.String() + `"?`,
+)
+
+// scope collects the values that expressions can
+// reference at this point in the TTP execution
+func (c TTPExecutionContext) scope() ExpressionScope {
+	scope := ExpressionScope{
+		Env:      make(map[string]string),
+		Steps:    make(map[string]StepScope),
+		Platform: platforms.GetCurrentPlatformSpec(),
+	}
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			scope.Env[name] = value
+		}
+	}
+	if c.Vars != nil {
+		for name, value := range c.Vars.Env {
+			scope.Env[name] = value
+		}
+		scope.Args = c.Vars.Args
+		scope.StepVars = c.Vars.StepVars
+		scope.Loop = c.Vars.Loop
+		scope.WorkDir = c.Vars.WorkDir
+		scope.Run = c.Vars.Run
+	}
+	scope.Run.DryRun = c.Cfg.DryRun
+	if c.StepResults != nil {
+		for name, result := range c.StepResults.ByName {
+			stepScope := StepScope{
+				Stdout:   result.Stdout,
+				Stderr:   result.Stderr,
+				ExitCode: result.ExitCode,
+				Outputs:  result.Outputs,
+				Skipped:  result.Skipped,
+			}
+			if result.Error != nil {
+				stepScope.Error = result.Error.Error()
+			}
+			scope.Steps[name] = stepScope
+		}
+	}
+	return scope
+}
+
+// expressionFuncs returns the functions available in expressions:
+// all sprig functions plus helpers for accessing step results
+// that fail with a clear error if the step has not run yet
+func expressionFuncs(scope ExpressionScope) template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	step := func(name string) (StepScope, error) {
+		stepScope, ok := scope.Steps[name]
+		if !ok {
+			return StepScope{}, fmt.Errorf("step %q has not run", name)
+		}
+		return stepScope, nil
+	}
+	funcs["step"] = step
+	funcs["stdout"] = func(name string) (string, error) {
+		stepScope, err := step(name)
+		return stepScope.Stdout, err
+	}
+	funcs["stderr"] = func(name string) (string, error) {
+		stepScope, err := step(name)
+		return stepScope.Stderr, err
+	}
+	funcs["exitCode"] = func(name string) (int, error) {
+		stepScope, err := step(name)
+		return stepScope.ExitCode, err
+	}
+	funcs["output"] = func(name, key string) (string, error) {
+		stepScope, err := step(name)
+		if err != nil {
+			return "", err
+		}
+		val, ok := stepScope.Outputs[key]
+		if !ok {
+			return "", fmt.Errorf("key %v not found in output of step %v", key, name)
+		}
+		return val, nil
+	}
+	return funcs
+}
+
+// evaluate renders the `{[{ }]}` expressions in the input
+// against the current scope
+func (c TTPExecutionContext) evaluate(name, input string) (string, error) {
+	scope := c.scope()
+	tmpl, err := template.New(name).Funcs(expressionFuncs(scope)).Option("missingkey=error").Delims(stepTemplateLeftDelim, stepTemplateRightDelim).Parse(input)
+	if err != nil {
+		return "", err
+	}
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, scope); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// rewriteLegacyReferences converts `$forge.steps.x.stdout` and
+// `$forge.steps.x.outputs.y` references in the input into the
+// equivalent `{[{ }]}` expressions. `$$forge.` is an escape
+// sequence that produces a literal `$forge.`.
+func rewriteLegacyReferences(input string) (string, error) {
+	if !strings.Contains(input, contextVariablePrefix) {
+		return input, nil
+	}
+	var failedMatch string
+	var failedMatchError error
+	rewritten := legacyReferenceRegexp.ReplaceAllStringFunc(input, func(match string) string {
+		expr, err := legacyReferenceExpression(match)
+		if err != nil {
+			failedMatch = match
+			failedMatchError = err
+		}
+		return expr
+	})
+	if failedMatchError != nil {
+		return "", fmt.Errorf("invalid variable expression %v: %v", failedMatch, failedMatchError)
+	}
+	return rewritten, nil
+}
+
+// rewriteLegacyConditionReferences converts `$forge.steps.x.stdout` and
+// `$forge.steps.x.outputs.y` references in an `if:` expression into the
+// equivalent function calls, so that the step results are never pasted
+// into the expression source. A reference wrapped in double quotes
+// (as in `eq "$forge.steps.x.stdout" "y"`) is replaced along with its quotes.
+func rewriteLegacyConditionReferences(expr string) (string, error) {
+	if !strings.Contains(expr, contextVariablePrefix) {
+		return expr, nil
+	}
+	var failedMatch string
+	var failedMatchError error
+	rewritten := legacyConditionReferenceRegexp.ReplaceAllStringFunc(expr, func(match string) string {
+		leadingQuote := strings.HasPrefix(match, `"`)
+		trailingQuote := len(match) > 1 && strings.HasSuffix(match, `"`)
+		reference := strings.TrimSuffix(strings.TrimPrefix(match, `"`), `"`)
+		if strings.HasPrefix(reference, "$$") {
+			return strings.Replace(match, "$$", "$", 1)
+		}
+		call, err := legacyReferenceCall(reference)
+		if err != nil {
+			failedMatch = reference
+			failedMatchError = err
+			return match
+		}
+		if leadingQuote && trailingQuote {
+			return "(" + call + ")"
+		}
+		return strings.Replace(match, reference, "("+call+")", 1)
+	})
+	if failedMatchError != nil {
+		return "", fmt.Errorf("invalid variable expression %v: %v", failedMatch, failedMatchError)
+	}
+	return rewritten, nil
+}
+
+// legacyReferenceExpression returns the `{[{ }]}` expression
+// equivalent to a single `$forge.` reference
+func legacyReferenceExpression(match string) (string, error) {
+	if strings.HasPrefix(match, "$$") {
+		return strings.TrimPrefix(match, "$"), nil
+	}
+	call, err := legacyReferenceCall(match)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s %s %s", stepTemplateLeftDelim, call, stepTemplateRightDelim), nil
+}
+
+// legacyReferenceCall returns the function call (such as
+// `stdout "x"`) equivalent to a single `$forge.` reference
+func legacyReferenceCall(match string) (string, error) {
+	variableSpecifier := strings.TrimPrefix(match, contextVariablePrefix)
+	tokens := strings.Split(variableSpecifier, ".")
+	for _, token := range tokens {
+		// happens if we have a something like {{steps.wut.}} or {{.steps.wut}}
+		if token == "" {
+			return "", errors.New("leading or trailing '.' in variable expression")
+		}
+	}
+	if len(tokens) < 2 {
+		return "", fmt.Errorf("invalid variable expression: %v", match)
+	}
+	if prefix := tokens[0]; prefix != "steps" {
+		return "", fmt.Errorf("invalid variable prefix: %v", prefix)
+	}
+
+	path := strings.Join(tokens[1:], ".")
+	if len(tokens) < 3 {
+		return "", fmt.Errorf("invalid step result reference: %v", path)
+	}
+	stepName := tokens[1]
+	switch fieldSelector := tokens[2]; fieldSelector {
+	case "stdout":
+		if len(tokens) != 3 {
+			return "", fmt.Errorf("invalid step result reference (should end at stdout): %v", path)
+		}
+		return fmt.Sprintf("stdout %q", stepName), nil
+	case "outputs":
+		if len(tokens) != 4 {
+			return "", fmt.Errorf("step output reference %v should be exactly one level deep (e.g. steps.foo.outputs.bar)", path)
+		}
+		return fmt.Sprintf("output %q %q", stepName, tokens[3]), nil
+	default:
+		return "", fmt.Errorf("invalid step result field selector: %v", fieldSelector)
+	}
+}
+
+// templateEnvironment evaluates the step expressions
+// in the values of a step's `env:` in place
+func (c TTPExecutionContext) templateEnvironment(env map[string]string) error {
+	for name, value := range env {
+		templated, err := c.templateLegacyStep(value)
+		if err != nil {
+			return fmt.Errorf("could not template env var %v: %w", name, err)
+		}
+		env[name] = templated
+	}
+	return nil
+}
+
+// templateValue evaluates the step expressions in all strings
+// within a value decoded from YAML (such as the `args:` of a
+// plugin step) and returns the templated copy of the value
+func (c TTPExecutionContext) templateValue(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return c.templateStep(v)
+	case map[string]any:
+		templated := make(map[string]any, len(v))
+		for key, item := range v {
+			var err error
+			if templated[key], err = c.templateValue(item); err != nil {
+				return nil, fmt.Errorf("could not template %v: %w", key, err)
+			}
+		}
+		return templated, nil
+	case []any:
+		templated := make([]any, len(v))
+		for idx, item := range v {
+			var err error
+			if templated[idx], err = c.templateValue(item); err != nil {
+				return nil, err
+			}
+		}
+		return templated, nil
+	default:
+		return value, nil
+	}
+}
+
+// validateExpression checks that the step expressions
+// in the input are syntactically valid
+func validateExpression(input string) error {
+	_, err := template.New("validate").Funcs(expressionFuncs(ExpressionScope{})).Delims(stepTemplateLeftDelim, stepTemplateRightDelim).Parse(input)
+	return err
+}
@@ -0,0 +1,226 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressions(t *testing.T) {
+	t.Setenv("TTPFORGE_TEST_ENV", "from-env")
+
+	execCtx := NewTTPExecutionContext()
+	execCtx.Vars.WorkDir = "/tmp/work"
+	execCtx.Vars.Args = map[string]any{
+		"count":   3,
+		"verbose": true,
+		"targets": []string{"a", "b"},
+	}
+	execCtx.Vars.StepVars = map[string]string{"user": "alice"}
+	execCtx.Vars.Loop = &LoopVars{Item: "x", Index: 1}
+	execCtx.Vars.Run = RunInfo{TTP: "my-ttp"}
+	execCtx.StepResults.ByName["probe"] = &ExecutionResult{
+		ActResult: ActResult{
+			Stdout:   "probe out\n",
+			Stderr:   "probe err\n",
+			ExitCode: 2,
+			Outputs:  map[string]string{"host": "example.com"},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		expectedResult string
+		legacy         bool
+		wantError      bool
+	}{
+		{
+			name:           "Typed Args",
+			input:          "{[{ add .Args.count 1 }]} {[{ if .Args.verbose }]}verbose{[{ end }]} {[{ join \",\" .Args.targets }]}",
+			expectedResult: "4 verbose a,b",
+		},
+		{
+			name:           "Env",
+			input:          "{[{ .Env.TTPFORGE_TEST_ENV }]}",
+			expectedResult: "from-env",
+		},
+		{
+			name:           "Step Results",
+			input:          "{[{ trim .Steps.probe.Stdout }]}|{[{ trim .Steps.probe.Stderr }]}|{[{ .Steps.probe.ExitCode }]}|{[{ .Steps.probe.Outputs.host }]}",
+			expectedResult: "probe out|probe err|2|example.com",
+		},
+		{
+			name:           "Step Helper Functions",
+			input:          "{[{ stdout \"probe\" | trim }]} {[{ exitCode \"probe\" }]} {[{ output \"probe\" \"host\" }]}",
+			expectedResult: "probe out 2 example.com",
+		},
+		{
+			name:           "Step Vars, Loop, WorkDir and Run",
+			input:          "{[{ .StepVars.user }]} {[{ .Loop.Item }]}{[{ .Loop.Index }]} {[{ .WorkDir }]} {[{ .Run.TTP }]}",
+			expectedResult: "alice x1 /tmp/work my-ttp",
+		},
+		{
+			name:           "Platform",
+			input:          "{[{ .Platform.OS }]}/{[{ .Platform.Arch }]}",
+			expectedResult: runtime.GOOS + "/" + runtime.GOARCH,
+		},
+		{
+			name:           "Legacy Step References",
+			input:          "$forge.steps.probe.outputs.host and {[{ .StepVars.user }]}",
+			expectedResult: "example.com and alice",
+			legacy:         true,
+		},
+		{
+			name:           "Legacy Escape",
+			input:          "$$forge.steps.probe.stdout",
+			expectedResult: "$forge.steps.probe.stdout",
+			legacy:         true,
+		},
+		{
+			name:      "Missing Step",
+			input:     "{[{ .Steps.missing.Stdout }]}",
+			wantError: true,
+		},
+		{
+			name:      "Missing Step in Helper Function",
+			input:     "{[{ stdout \"missing\" }]}",
+			wantError: true,
+		},
+		{
+			name:      "Missing Output",
+			input:     "{[{ output \"probe\" \"missing\" }]}",
+			wantError: true,
+		},
+		{
+			name:      "Invalid Legacy Reference",
+			input:     "$forge.steps.probe.stderr",
+			legacy:    true,
+			wantError: true,
+		},
+		{
+			name:           "Legacy References Are Literal Outside Legacy Fields",
+			input:          "echo $forge.foo $forge.steps.probe.stdout {[{ .StepVars.user }]}",
+			expectedResult: "echo $forge.foo $forge.steps.probe.stdout alice",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			templateStep := execCtx.templateStep
+			if tc.legacy {
+				templateStep = execCtx.templateLegacyStep
+			}
+			result, err := templateStep(tc.input)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedResult, result)
+		})
+	}
+}
+
+func TestExpressionsInTTP(t *testing.T) {
+	content := `name: expressions
+steps:
+  - name: probe
+    inline: echo '{"host":"example.com"}' && exit 3
+    expect_exit_code: 3
+    outputs:
+      host:
+        filters:
+          - json_path: host
+  - name: use_env
+    inline: echo "$GREETING from $HOST"
+    env:
+      GREETING: "{[{ .Args.greeting }]}"
+      HOST: "{[{ .Steps.probe.Outputs.host }]}"
+  - name: conditional
+    if: eq (exitCode "probe") 3
+    print_str: "probe exited with {[{ .Steps.probe.ExitCode }]}"
+    cleanup:
+      print_str: "cleaning up after {[{ .Steps.conditional.Stdout | trim }]}"`
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	var stdoutBuf bytes.Buffer
+	execCtx := NewTTPExecutionContext()
+	execCtx.Cfg.Stdout = &stdoutBuf
+	execCtx.Vars.Args = map[string]any{"greeting": "hello"}
+	require.NoError(t, ttp.Validate(execCtx))
+
+	require.NoError(t, ttp.Execute(execCtx))
+	require.NoError(t, ttp.RunCleanup(execCtx))
+	assert.Equal(t, "{\"host\":\"example.com\"}\nhello from example.com\nprobe exited with 3\ncleaning up after probe exited with 3\n", stdoutBuf.String())
+}
+
+func TestRewriteLegacyConditionReferences(t *testing.T) {
+	testCases := []struct {
+		name      string
+		expr      string
+		expected  string
+		wantError bool
+	}{
+		{
+			name:     "No references",
+			expr:     `eq .Args.user "root"`,
+			expected: `eq .Args.user "root"`,
+		},
+		{
+			name:     "Bare stdout reference",
+			expr:     `ne $forge.steps.step1.stdout ""`,
+			expected: `ne (stdout "step1") ""`,
+		},
+		{
+			name:     "Quoted output reference",
+			expr:     `eq "$forge.steps.step1.outputs.user" "root"`,
+			expected: `eq (output "step1" "user") "root"`,
+		},
+		{
+			name:     "Escaped reference",
+			expr:     `eq "$$forge.steps.step1.stdout" .Args.literal`,
+			expected: `eq "$forge.steps.step1.stdout" .Args.literal`,
+		},
+		{
+			name:      "Invalid reference",
+			expr:      `eq $forge.steps.step1.stderr ""`,
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rewritten, err := rewriteLegacyConditionReferences(tc.expr)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rewritten)
+		})
+	}
+}
@@ -20,11 +20,13 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/facebookincubator/ttpforge/pkg/logging"
 	"github.com/spf13/afero"
@@ -66,7 +68,7 @@ func (f *FetchURIStep) IsNil() bool {
 //
 // If Location is set, it ensures that the path exists and retrieves
 // its absolute path.
-func (f *FetchURIStep) Validate(execCtx TTPExecutionContext) error {
+func (f *FetchURIStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	// Validate URI exists
 	if f.FetchURI == "" {
 		err := errors.New("require FetchURI to be set with fetchURI")
@@ -89,6 +91,15 @@ func (f *FetchURIStep) Validate(execCtx TTPExecutionContext) error {
 		}
 	}
 
+	// Validate retries is a non-negative integer
+	if f.Retries != "" && !execCtx.containsStepTemplating(f.Retries) {
+		if retries, err := strconv.Atoi(f.Retries); err != nil || retries < 0 {
+			err := fmt.Errorf("invalid retries value %q: must be a non-negative integer", f.Retries)
+			logging.L().Error(zap.Error(err))
+			return err
+		}
+	}
+
 	// Retrieve the absolute path to the file, if location doesn't contain templating
 	if !execCtx.containsStepTemplating(f.Location) {
 		err := f.validateLocation(execCtx)
@@ -104,7 +115,7 @@ func (f *FetchURIStep) Validate(execCtx TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (f *FetchURIStep) Template(execCtx TTPExecutionContext) error {
+func (f *FetchURIStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 
 	// Template URI
@@ -147,10 +158,10 @@ func (f *FetchURIStep) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (f *FetchURIStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+func (f *FetchURIStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	logging.L().Info("========= Executing ==========")
 	logging.L().Infof("FetchURI: %s", f.FetchURI)
-	if err := f.fetchURI(execCtx); err != nil {
+	if err := f.fetchURI(ctx, execCtx); err != nil {
 		logging.L().Error(zap.Error(err))
 		return nil, err
 	}
@@ -173,7 +184,7 @@ func (f *FetchURIStep) Execute(execCtx TTPExecutionContext) (*ActResult, error)
 
 // fetchURI executes the FetchURIStep with the specified Location, Uri, and additional arguments,
 // and an error if any errors occur.
-func (f *FetchURIStep) fetchURI(execCtx TTPExecutionContext) error {
+func (f *FetchURIStep) fetchURI(ctx context.Context, execCtx TTPExecutionContext) error {
 	appFs := f.FileSystem
 	absLocal := f.Location
 
@@ -205,7 +216,11 @@ func (f *FetchURIStep) fetchURI(execCtx TTPExecutionContext) error {
 		client = &http.Client{Transport: tr}
 	}
 
-	resp, err := client.Get(f.FetchURI)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.FetchURI, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/afero"
 	"net/http"
@@ -266,7 +267,7 @@ location: /tmp/{[{.StepVars.location}]}.txt
 			}
 
 			// validate
-			err = step.Validate(execCtx)
+			err = step.Validate(context.Background(), execCtx)
 			if tc.expectValidateError {
 				assert.Error(t, err)
 				return
@@ -274,7 +275,7 @@ location: /tmp/{[{.StepVars.location}]}.txt
 			assert.NoError(t, err)
 
 			// template
-			err = step.Template(execCtx)
+			err = step.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				assert.Error(t, err)
 				return
@@ -288,7 +289,7 @@ location: /tmp/{[{.StepVars.location}]}.txt
 			step.Proxy = ""
 
 			// execute
-			_, err = step.Execute(execCtx)
+			_, err = step.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				assert.Error(t, err)
 				return
@@ -325,11 +326,11 @@ overwrite: true
 
 	s.FetchURI = ts.URL
 
-	err = s.Validate(execCtx)
+	err = s.Validate(context.Background(), execCtx)
 	require.NoError(t, err)
 
 	// execute and check result
-	_, err = s.Execute(execCtx)
+	_, err = s.Execute(context.Background(), execCtx)
 	require.NoError(t, err)
 
 	f, err := os.Stat(s.Location)
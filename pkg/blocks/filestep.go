@@ -66,7 +66,7 @@ func (f *FileStep) IsNil() bool {
 // It then checks that the executor is in the system path, and if CleanupStep
 // is not nil, it validates the cleanup step as well.
 // It logs any errors and returns them.
-func (f *FileStep) Validate(execCtx TTPExecutionContext) error {
+func (f *FileStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	if f.FilePath == "" {
 		err := errors.New("a TTP must include inline logic or path to a file with the logic")
 		logging.L().Error(zap.Error(err))
@@ -111,7 +111,7 @@ func (f *FileStep) Validate(execCtx TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (f *FileStep) Template(execCtx TTPExecutionContext) error {
+func (f *FileStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	f.FilePath, err = execCtx.templateStep(f.FilePath)
 	if err != nil {
@@ -122,23 +122,24 @@ func (f *FileStep) Template(execCtx TTPExecutionContext) error {
 		return err
 	}
 	for index, value := range f.Args {
-		f.Args[index], err = execCtx.templateStep(value)
+		f.Args[index], err = execCtx.templateLegacyStep(value)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return execCtx.templateEnvironment(f.Environment)
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (f *FileStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), DefaultExecutionTimeout)
-	defer cancel()
-
+func (f *FileStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	executor := NewExecutor(f.Executor, "", f.FilePath, f.Args, f.Environment)
 	result, err := executor.Execute(ctx, execCtx)
 	if err != nil {
-		return nil, err
+		if result != nil {
+			// outputs of failed commands are parsed on a best-effort basis
+			result.Outputs, _ = outputs.Parse(f.Outputs, result.Stdout)
+		}
+		return result, err
 	}
 	result.Outputs, err = outputs.Parse(f.Outputs, result.Stdout)
 	// Send stdout to the output variable
@@ -151,7 +152,7 @@ func (f *FileStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
 // Cleanup is a method to establish a link with the Cleanup interface.
 // Assumes that the type is the cleanup step and is invoked by
 // f.CleanupStep.Cleanup.
-func (f *FileStep) Cleanup(execCtx TTPExecutionContext) (*ActResult, error) {
+func (f *FileStep) Cleanup(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	// TODO: why call Execute on a cleanup??
-	return f.Execute(execCtx)
+	return f.Execute(ctx, execCtx)
 }
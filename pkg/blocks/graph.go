@@ -0,0 +1,167 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/args"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"github.com/facebookincubator/ttpforge/pkg/platforms"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+)
+
+// GraphNode is a TTP in the dependency graph of a composite TTP.
+// Ref is the `ttp:` reference by which the TTP was included and
+// Step is the name of the step that included it (both are empty
+// for the root of the graph). Children holds the sub-TTPs
+// referenced by this TTP, in step order.
+type GraphNode struct {
+	Name     string       `json:"name"`
+	Path     string       `json:"path"`
+	Ref      string       `json:"ref,omitempty"`
+	Step     string       `json:"step,omitempty"`
+	Children []*GraphNode `json:"children,omitempty"`
+}
+
+// LoadGraph builds the sub-TTP dependency tree of the TTP at the
+// given path. The TTPs are only rendered and decoded - they are not
+// validated, so building the graph never runs any code. Arguments
+// that are not provided (including sub-TTP arguments that are only
+// known once the steps run) are given placeholder values.
+//
+// **Parameters:**
+//
+// ttpFilePath: the path to the TTP within the repo
+// repo: the repo containing the TTP, used to resolve `ttp:` references
+// argsKvStrs: slice of arguments in "ARG_NAME=ARG_VALUE" format
+//
+// **Returns:**
+//
+// *GraphNode: the root node of the dependency tree
+// error: an error if a TTP cannot be rendered or references itself
+func LoadGraph(ttpFilePath string, repo repos.Repo, argsKvStrs []string) (*GraphNode, error) {
+	return loadGraph(ttpFilePath, repo, argsKvStrs, nil)
+}
+
+func loadGraph(ttpFilePath string, repo repos.Repo, argsKvStrs []string, loadChain []string) (*GraphNode, error) {
+	loadChain, err := extendLoadChain(loadChain, ttpFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ttpBytes, err := readTTPBytes(ttpFilePath, repo.GetFs())
+	if err != nil {
+		return nil, err
+	}
+
+	argSpecs, err := parseArgSpecs(ttpBytes)
+	if err != nil {
+		return nil, err
+	}
+	argSpecs, argsKvStrs = args.WithPlaceholders(argSpecs, argsKvStrs)
+	argValues, err := args.ParseAndValidate(argSpecs, argsKvStrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse and validate arguments of %v: %w", ttpFilePath, err)
+	}
+
+	ttp, err := renderTTP(string(ttpBytes), RenderParameters{
+		Args:     argValues,
+		Platform: platforms.GetCurrentPlatformSpec(),
+	})
+	if err != nil {
+		return nil, parseutils.Locate(err, ttpFilePath)
+	}
+
+	node := &GraphNode{
+		Name: ttp.Name,
+		Path: ttpFilePath,
+	}
+	if err := node.loadChildren(ttp.Steps, repo, loadChain); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (n *GraphNode) loadChildren(steps []Step, repo repos.Repo, loadChain []string) error {
+	for idx := range steps {
+		switch action := steps[idx].action.(type) {
+		case *SubTTPStep:
+			subTTPPath, err := repo.FindTTP(action.TtpRef)
+			if err != nil {
+				return err
+			}
+			child, err := loadGraph(subTTPPath, repo, action.staticArgs(), loadChain)
+			if err != nil {
+				return err
+			}
+			child.Ref = action.TtpRef
+			child.Step = steps[idx].Name
+			n.Children = append(n.Children, child)
+		case *ParallelStep:
+			if err := n.loadChildren(action.Steps, repo, loadChain); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the dependency tree as indented JSON
+func (n *GraphNode) WriteJSON(w io.Writer) error {
+	graphBytes, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize graph: %w", err)
+	}
+	_, err = w.Write(append(graphBytes, '\n'))
+	return err
+}
+
+// WriteDOT writes the dependency graph in the Graphviz DOT format.
+// Each TTP file appears as a single node, so building blocks that are
+// shared by several TTPs have multiple incoming edges. Edges are labeled
+// with the name of the step that references the sub-TTP.
+func (n *GraphNode) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph ttps {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	seen := make(map[string]bool)
+	n.writeDOT(&sb, seen)
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (n *GraphNode) writeDOT(sb *strings.Builder, seen map[string]bool) {
+	// the edges of shared TTPs only need to be written once
+	if seen[n.Path] {
+		return
+	}
+	seen[n.Path] = true
+	fmt.Fprintf(sb, "  %q [label=%q];\n", n.Path, n.Name+"\n"+n.Path)
+	for _, child := range n.Children {
+		child.writeDOT(sb, seen)
+		fmt.Fprintf(sb, "  %q -> %q [label=%q];\n", n.Path, child.Path, child.Step)
+	}
+}
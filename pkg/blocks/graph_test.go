@@ -0,0 +1,250 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/facebookincubator/ttpforge/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestRepoForGraph(t *testing.T) repos.Repo {
+	fsys, err := testutils.MakeAferoTestFs(map[string][]byte{
+		"repos/g/" + repos.RepoConfigFileName: []byte(`ttp_search_paths: ["ttps"]`),
+		"repos/g/ttps/leaf.yaml": []byte(`name: leaf
+steps:
+  - name: leaf_step
+    inline: echo leaf`),
+		"repos/g/ttps/middle.yaml": []byte(`name: middle
+steps:
+  - name: use_leaf
+    ttp: leaf.yaml`),
+		"repos/g/ttps/root.yaml": []byte(`name: root
+steps:
+  - name: first
+    ttp: middle.yaml
+  - name: concurrent
+    parallel:
+      - name: second
+        ttp: leaf.yaml
+      - name: not_a_sub_ttp
+        inline: echo hello`),
+		"repos/g/ttps/with-args.yaml": []byte(`name: with-args
+args:
+  - name: fixed
+  - name: runtime
+    regexp: ^[0-9]+$
+steps:
+  - name: leaf_for_{{.Args.fixed}}
+    ttp: leaf.yaml`),
+		"repos/g/ttps/needs-args.yaml": []byte(`name: needs-args
+args:
+  - name: target
+    regexp: ^[0-9]+$
+  - name: mode
+    choices: [fast, slow]
+steps:
+  - name: produce
+    inline: echo 42
+  - name: pass_{{.Args.mode}}
+    ttp: with-args.yaml
+    args:
+      fixed: "{{.Args.target}}"
+      runtime: '{[{ stdout "produce" }]}'
+  - name: missing_script
+    file: does-not-exist.sh`),
+		"repos/g/ttps/self.yaml": []byte(`name: self
+steps:
+  - name: recurse
+    ttp: self.yaml`),
+		"repos/g/ttps/cycle-a.yaml": []byte(`name: cycle-a
+steps:
+  - name: to_b
+    ttp: cycle-b.yaml`),
+		"repos/g/ttps/cycle-b.yaml": []byte(`name: cycle-b
+steps:
+  - name: back_to_a
+    ttp: cycle-a.yaml`),
+	})
+	require.NoError(t, err)
+	repoSpec := repos.Spec{
+		Name: "graph",
+		Path: "repos/g",
+	}
+	repo, err := repoSpec.Load(fsys, "")
+	require.NoError(t, err)
+	return repo
+}
+
+func TestSubTTPCycles(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ttpPath   string
+		wantChain string
+	}{
+		{
+			name:      "TTP referencing itself",
+			ttpPath:   "repos/g/ttps/self.yaml",
+			wantChain: "repos/g/ttps/self.yaml -> repos/g/ttps/self.yaml",
+		},
+		{
+			name:      "Indirect cycle",
+			ttpPath:   "repos/g/ttps/cycle-a.yaml",
+			wantChain: "repos/g/ttps/cycle-a.yaml -> repos/g/ttps/cycle-b.yaml -> repos/g/ttps/cycle-a.yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := makeTestRepoForGraph(t)
+			execCfg := TTPExecutionConfig{Repo: repo}
+			_, _, err := LoadTTP(tc.ttpPath, repo.GetFs(), &execCfg, map[string]string{}, nil)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrSubTTPCycle), "unexpected error: %v", err)
+			assert.Contains(t, err.Error(), tc.wantChain)
+
+			_, err = LoadGraph(tc.ttpPath, repo, nil)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrSubTTPCycle), "unexpected error: %v", err)
+			assert.Contains(t, err.Error(), tc.wantChain)
+		})
+	}
+}
+
+func TestGraph(t *testing.T) {
+	repo := makeTestRepoForGraph(t)
+	graph, err := LoadGraph("repos/g/ttps/root.yaml", repo, nil)
+	require.NoError(t, err)
+
+	expected := &GraphNode{
+		Name: "root",
+		Path: "repos/g/ttps/root.yaml",
+		Children: []*GraphNode{
+			{
+				Name: "middle",
+				Path: "repos/g/ttps/middle.yaml",
+				Ref:  "middle.yaml",
+				Step: "first",
+				Children: []*GraphNode{
+					{
+						Name: "leaf",
+						Path: "repos/g/ttps/leaf.yaml",
+						Ref:  "leaf.yaml",
+						Step: "use_leaf",
+					},
+				},
+			},
+			{
+				Name: "leaf",
+				Path: "repos/g/ttps/leaf.yaml",
+				Ref:  "leaf.yaml",
+				Step: "second",
+			},
+		},
+	}
+	assert.Equal(t, expected, graph)
+
+	var jsonBuf bytes.Buffer
+	require.NoError(t, graph.WriteJSON(&jsonBuf))
+	var decoded GraphNode
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &decoded))
+	assert.Equal(t, expected, &decoded)
+
+	var dotBuf bytes.Buffer
+	require.NoError(t, graph.WriteDOT(&dotBuf))
+	assert.Equal(t, `digraph ttps {
+  rankdir=LR;
+  node [shape=box];
+  "repos/g/ttps/root.yaml" [label="root\nrepos/g/ttps/root.yaml"];
+  "repos/g/ttps/middle.yaml" [label="middle\nrepos/g/ttps/middle.yaml"];
+  "repos/g/ttps/leaf.yaml" [label="leaf\nrepos/g/ttps/leaf.yaml"];
+  "repos/g/ttps/middle.yaml" -> "repos/g/ttps/leaf.yaml" [label="use_leaf"];
+  "repos/g/ttps/root.yaml" -> "repos/g/ttps/middle.yaml" [label="first"];
+  "repos/g/ttps/root.yaml" -> "repos/g/ttps/leaf.yaml" [label="second"];
+}
+`, dotBuf.String())
+}
+
+func TestGraphWithArgs(t *testing.T) {
+	testCases := []struct {
+		name      string
+		argKvStrs []string
+		wantStep  string
+		wantLeaf  string
+		wantError bool
+	}{
+		{
+			name:     "Required arguments use placeholders",
+			wantStep: "pass_fast",
+			wantLeaf: "leaf_for_placeholder",
+		},
+		{
+			name:      "Provided arguments are used",
+			argKvStrs: []string{"target=7", "mode=slow"},
+			wantStep:  "pass_slow",
+			wantLeaf:  "leaf_for_7",
+		},
+		{
+			name:      "Provided arguments are still validated",
+			argKvStrs: []string{"target=seven"},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := makeTestRepoForGraph(t)
+			graph, err := LoadGraph("repos/g/ttps/needs-args.yaml", repo, tc.argKvStrs)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			expected := &GraphNode{
+				Name: "needs-args",
+				Path: "repos/g/ttps/needs-args.yaml",
+				Children: []*GraphNode{
+					{
+						Name: "with-args",
+						Path: "repos/g/ttps/with-args.yaml",
+						Ref:  "with-args.yaml",
+						Step: tc.wantStep,
+						Children: []*GraphNode{
+							{
+								Name: "leaf",
+								Path: "repos/g/ttps/leaf.yaml",
+								Ref:  "leaf.yaml",
+								Step: tc.wantLeaf,
+							},
+						},
+					},
+				},
+			}
+			assert.Equal(t, expected, graph)
+		})
+	}
+}
@@ -20,11 +20,11 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"regexp"
 	"strings"
 
@@ -69,7 +69,7 @@ func (r *HTTPRequestStep) IsNil() bool {
 }
 
 // Validate validates the HTTPRequestStep.
-func (r *HTTPRequestStep) Validate(execCtx TTPExecutionContext) error {
+func (r *HTTPRequestStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	// Validate the target URL, skip if contains template
 	if r.HTTPRequest != "" && !execCtx.containsStepTemplating(r.HTTPRequest) {
 		err := r.validateURL()
@@ -124,7 +124,7 @@ func (r *HTTPRequestStep) Validate(execCtx TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (r *HTTPRequestStep) Template(execCtx TTPExecutionContext) error {
+func (r *HTTPRequestStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 
 	// Template and revalidate httprequest
@@ -197,10 +197,10 @@ func (r *HTTPRequestStep) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (r *HTTPRequestStep) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+func (r *HTTPRequestStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	logging.L().Info("========= Executing ==========")
 	logging.L().Infof("HTTPRequest to: %s", r.HTTPRequest)
-	if err := r.SendRequest(execCtx); err != nil {
+	if err := r.SendRequest(ctx, execCtx); err != nil {
 		logging.L().Error(zap.Error(err))
 		return nil, err
 	}
@@ -209,7 +209,7 @@ func (r *HTTPRequestStep) Execute(execCtx TTPExecutionContext) (*ActResult, erro
 }
 
 // HTTPRequest executes the HTTPRequestStep.
-func (r *HTTPRequestStep) SendRequest(execCtx TTPExecutionContext) error {
+func (r *HTTPRequestStep) SendRequest(ctx context.Context, execCtx TTPExecutionContext) error {
 
 	// Gather the parameters
 	params := url.Values{}
@@ -223,7 +223,7 @@ func (r *HTTPRequestStep) SendRequest(execCtx TTPExecutionContext) error {
 	trimBody := strings.TrimSuffix(r.Body, "\n")
 
 	// Create a new request with the specified method, URL, and body.
-	req, err := http.NewRequest(r.Type, fullURL, strings.NewReader(trimBody))
+	req, err := http.NewRequestWithContext(ctx, r.Type, fullURL, strings.NewReader(trimBody))
 	if err != nil {
 		return fmt.Errorf("Error creating request: %v", err)
 	}
@@ -284,12 +284,10 @@ func (r *HTTPRequestStep) SendRequest(execCtx TTPExecutionContext) error {
 		}
 	}
 
-	// Store the response as an environment variable.
+	// Store the response as an environment variable
+	// of the commands run by subsequent steps.
 	if r.Response != "" {
-		err = os.Setenv(r.Response, finalResponse)
-		if err != nil {
-			return fmt.Errorf("Error setting environment variable: %v", err)
-		}
+		execCtx.Vars.setEnv(r.Response, finalResponse)
 	}
 
 	logging.L().Infof("Response: %s", finalResponse)
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -380,7 +381,7 @@ outputvar: testvar
 			execCtx.Vars.StepVars = tc.stepVars
 
 			// validate
-			err = step.Validate(execCtx)
+			err = step.Validate(context.Background(), execCtx)
 			if tc.expectValidateError {
 				assert.Error(t, err)
 				return
@@ -388,7 +389,7 @@ outputvar: testvar
 			assert.NoError(t, err)
 
 			// template
-			err = step.Template(execCtx)
+			err = step.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				assert.Error(t, err)
 				return
@@ -402,7 +403,7 @@ outputvar: testvar
 			step.Proxy = ""
 
 			// execute
-			_, err = step.Execute(execCtx)
+			_, err = step.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				assert.Error(t, err)
 				return
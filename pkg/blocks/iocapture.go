@@ -88,33 +88,37 @@ func (zw *zapWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
-func streamAndCapture(cmd exec.Cmd, stdout, stderr io.Writer) (*ActResult, error) {
-	if stdout == nil {
-		stdout = &bufferedWriter{
-			writer: &zapWriter{
-				prefix: "[STDOUT] ",
-			},
-		}
+// outputWriter returns w, or a writer that logs each
+// line with the given prefix if w is nil
+func outputWriter(w io.Writer, prefix string) io.Writer {
+	if w != nil {
+		return w
 	}
-	if stderr == nil {
-		stderr = &bufferedWriter{
-			writer: &zapWriter{
-				prefix: "[STDERR] ",
-			},
-		}
+	return &bufferedWriter{
+		writer: &zapWriter{
+			prefix: prefix,
+		},
 	}
+}
+
+func streamAndCapture(cmd exec.Cmd, stdout, stderr io.Writer) (*ActResult, error) {
+	stdout = outputWriter(stdout, "[STDOUT] ")
+	stderr = outputWriter(stderr, "[STDERR] ")
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = io.MultiWriter(stdout, &stdoutBuf)
 	cmd.Stderr = io.MultiWriter(stderr, &stderrBuf)
 
-	err := cmd.Run()
-	if err != nil {
-		return nil, err
+	err := runTrackedProcess(&cmd)
+	// the output of failed commands is returned
+	// along with the error so that it can still be inspected
+	result := ActResult{
+		Stdout:   stdoutBuf.String(),
+		Stderr:   stderrBuf.String(),
+		ExitCode: -1,
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
 	}
-	outStr, errStr := stdoutBuf.String(), stderrBuf.String()
-	result := ActResult{}
-	result.Stdout = outStr
-	result.Stderr = errStr
-	return &result, nil
+	return &result, err
 }
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -55,7 +56,7 @@ func (s *KillProcessStep) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies.
-func (s *KillProcessStep) Validate(_ TTPExecutionContext) error {
+func (s *KillProcessStep) Validate(_ context.Context, _ TTPExecutionContext) error {
 	if s.IsNil() {
 		return fmt.Errorf("Both Process ID and Process Name cannot be empty")
 	}
@@ -81,7 +82,7 @@ func (s *KillProcessStep) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *KillProcessStep) Template(execCtx TTPExecutionContext) error {
+func (s *KillProcessStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	s.ProcessID, err = execCtx.templateStep(s.ProcessID)
 	if err != nil {
@@ -167,7 +168,7 @@ func (s *KillProcessStep) killProcesses(pids []int) error {
 }
 
 // Execute runs the step and returns an error if one occurs while extracting PIDs or killing processes.
-func (s *KillProcessStep) Execute(_ TTPExecutionContext) (*ActResult, error) {
+func (s *KillProcessStep) Execute(_ context.Context, _ TTPExecutionContext) (*ActResult, error) {
 	pids, err := s.extractPIDs()
 	if err != nil {
 		return nil, err
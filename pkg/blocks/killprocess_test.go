@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"os"
 	"strconv"
 	"testing"
@@ -190,7 +191,7 @@ func TestKillProcessExecute(t *testing.T) {
 			execCtx.Vars.StepVars = tc.stepVars
 
 			// template and check error
-			err := tc.step.Template(execCtx)
+			err := tc.step.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				require.Error(t, err)
 				return
@@ -198,7 +199,7 @@ func TestKillProcessExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// validate
-			err2 := tc.step.Validate(execCtx)
+			err2 := tc.step.Validate(context.Background(), execCtx)
 			if tc.expectValidateError {
 				require.Error(t, err2)
 				return
@@ -216,7 +217,7 @@ func TestKillProcessExecute(t *testing.T) {
 				require.NoError(t, err)
 			}
 			// execute and check error
-			_, err = tc.step.Execute(execCtx)
+			_, err = tc.step.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				require.Error(t, err)
 				return
@@ -21,22 +21,30 @@ package blocks
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
 	"github.com/facebookincubator/ttpforge/pkg/args"
 	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
 	"github.com/facebookincubator/ttpforge/pkg/platforms"
 	"github.com/facebookincubator/ttpforge/pkg/preprocess"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrSubTTPCycle is returned when a TTP (directly or indirectly)
+// references itself through `ttp:` steps
+var ErrSubTTPCycle = errors.New("cyclic sub-TTP reference")
+
 // RenderParameters is a container for all of the
 // runtime parameters used in the
 // TTP template rendering process
@@ -60,31 +68,64 @@ type RenderParameters struct {
 // *TTP: A pointer to the TTP object created from the template.
 // error: An error if the rendering or unmarshaling process fails.
 func RenderTemplatedTTP(ttpStr string, rp RenderParameters) (*TTP, error) {
-	tmpl, err := template.New("ttp").Funcs(sprig.TxtFuncMap()).Parse(ttpStr)
-
-	if err != nil {
-		return nil, err
-	}
+	ttp, err := renderTTP(ttpStr, rp)
+	return ttp, parseutils.Locate(err, "")
+}
 
-	var result bytes.Buffer
-	err = tmpl.Execute(&result, rp)
+// renderTTP renders and decodes the TTP - errors are
+// not yet located as the path of the TTP is not known
+func renderTTP(ttpStr string, rp RenderParameters) (*TTP, error) {
+	result, err := RenderTemplate(ttpStr, rp)
 	if err != nil {
 		return nil, err
 	}
 
+	// the positions recorded in errors must refer to the lines of
+	// the file, which templates such as `{{ range }}` may shift
+	var node yaml.Node
 	var ttp TTP
-	err = yaml.Unmarshal(result.Bytes(), &ttp)
+	err = yaml.Unmarshal(result, &node)
+	if err == nil && node.Kind != 0 {
+		parseutils.RemapLines(&node, parseutils.SourceLines([]byte(ttpStr), result))
+		err = node.Decode(&ttp)
+	}
 	if err != nil {
 		// important - errors from template rendering are often
 		// opaque so we need to log the real thing
 		logging.L().Errorf("failed to decode TTP YAML - received error: %v", err)
-		logging.L().Error("inspect the rendered TTP below (with all templates such as `{{.Args.foo}}` expanded):\n", result.String())
+		logging.L().Error("inspect the rendered TTP below (with all templates such as `{{.Args.foo}}` expanded):\n", string(result))
 		logging.DividerThin()
 		return nil, err
 	}
 	return &ttp, nil
 }
 
+// RenderTemplate expands the `{{ ... }}` template expressions
+// of a TTP without decoding the result - this is used by tools
+// such as `ttpforge lint` that inspect the YAML of the TTP itself.
+//
+// **Parameters:**
+//
+// ttpStr: A string containing the TTP template to be rendered.
+// rp: The parameters (argument values and platform) used for rendering.
+//
+// **Returns:**
+//
+// []byte: The rendered TTP YAML.
+// error: An error if the template is invalid or cannot be rendered.
+func RenderTemplate(ttpStr string, rp RenderParameters) ([]byte, error) {
+	tmpl, err := template.New("ttp").Funcs(sprig.TxtFuncMap()).Parse(ttpStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, rp); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
+
 // LoadTTP reads a TTP file and creates a TTP instance based on its contents.
 // If the file is empty or contains invalid data, it returns an error.
 //
@@ -99,28 +140,22 @@ func RenderTemplatedTTP(ttpStr string, rp RenderParameters) (*TTP, error) {
 // TTPExecutionContext: the initialized TTPExecutionContext suitable for passing to TTP.Execute(...)
 // err: An error if the file contains invalid data or cannot be read.
 func LoadTTP(ttpFilePath string, fsys afero.Fs, execCfg *TTPExecutionConfig, stepVars map[string]string, argsKvStrs []string) (*TTP, *TTPExecutionContext, error) {
-	ttpBytes, err := readTTPBytes(ttpFilePath, fsys)
+	loadChain, err := extendLoadChain(execCfg.loadChain, ttpFilePath)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	result, err := preprocess.Parse(ttpBytes)
+	ttpBytes, err := readTTPBytes(ttpFilePath, fsys)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// linting above establishes that the TTP yaml will be
-	// compatible with our rendering process
-	type ArgSpecContainer struct {
-		ArgSpecs []args.Spec `yaml:"args"`
-	}
-	var tmpContainer ArgSpecContainer
-	err = yaml.Unmarshal(result.PreambleBytes, &tmpContainer)
+	argSpecs, err := parseArgSpecs(ttpBytes)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to unmarshal YAML preamble section: %w", err)
+		return nil, nil, err
 	}
 
-	argValues, err := args.ParseAndValidate(tmpContainer.ArgSpecs, argsKvStrs)
+	argValues, err := args.ParseAndValidate(argSpecs, argsKvStrs)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse and validate arguments: %v", err)
 	}
@@ -129,11 +164,13 @@ func LoadTTP(ttpFilePath string, fsys afero.Fs, execCfg *TTPExecutionConfig, ste
 		Args:     argValues,
 		Platform: platforms.GetCurrentPlatformSpec(),
 	}
-	ttp, err := RenderTemplatedTTP(string(ttpBytes), rp)
+	ttp, err := renderTTP(string(ttpBytes), rp)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, parseutils.Locate(err, ttpFilePath)
 	}
 
+	ttp.FilePath = ttpFilePath
+
 	// embedded fs has no notion of workdirs
 	// so we should only set workdir to the TTP's directory
 	// if we are using an OsFs
@@ -154,16 +191,53 @@ func LoadTTP(ttpFilePath string, fsys afero.Fs, execCfg *TTPExecutionConfig, ste
 
 	execCtx := NewTTPExecutionContext()
 	execCtx.Cfg = *execCfg
+	execCtx.Cfg.loadChain = loadChain
 	execCtx.Vars.WorkDir = ttp.WorkDir
 	execCtx.Vars.StepVars = stepVars
+	execCtx.Vars.Args = argValues
 
 	err = ttp.Validate(execCtx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, parseutils.Locate(err, ttpFilePath)
 	}
 	return ttp, &execCtx, nil
 }
 
+// parseArgSpecs reads the argument specifications
+// from the preamble of the (unrendered) TTP
+func parseArgSpecs(ttpBytes []byte) ([]args.Spec, error) {
+	result, err := preprocess.Parse(ttpBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// linting above establishes that the TTP yaml will be
+	// compatible with our rendering process
+	type ArgSpecContainer struct {
+		ArgSpecs []args.Spec `yaml:"args"`
+	}
+	var tmpContainer ArgSpecContainer
+	err = yaml.Unmarshal(result.PreambleBytes, &tmpContainer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML preamble section: %w", err)
+	}
+	return tmpContainer.ArgSpecs, nil
+}
+
+// extendLoadChain appends the TTP at the given path to the chain of
+// TTPs that are currently being loaded, or returns an error
+// describing the full chain of references if doing so creates a cycle
+func extendLoadChain(loadChain []string, ttpFilePath string) ([]string, error) {
+	ttpFilePath = filepath.Clean(ttpFilePath)
+	for _, loadingPath := range loadChain {
+		if loadingPath == ttpFilePath {
+			chain := append(slices.Clone(loadChain), ttpFilePath)
+			return nil, fmt.Errorf("%w: %v", ErrSubTTPCycle, strings.Join(chain, " -> "))
+		}
+	}
+	return append(slices.Clone(loadChain), ttpFilePath), nil
+}
+
 func readTTPBytes(ttpFilePath string, system afero.Fs) ([]byte, error) {
 	var file fs.File
 	var err error
@@ -0,0 +1,142 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"gopkg.in/yaml.v3"
+)
+
+// LoopSpec specifies the list of items over which
+// a step's action should be repeated. Exactly one of the
+// following sources must be given:
+//
+// Items: a literal list of items
+// Arg: the name of a `type: list` argument
+// Lines: a string (usually an expression such as `{[{ .Steps.foo.Stdout }]}`)
+// that is split into one item per non-empty line at run time
+//
+// As a shorthand, a YAML list may be provided in place of the LoopSpec,
+// in which case it is treated as Items.
+type LoopSpec struct {
+	Items []string `yaml:"items,omitempty"`
+	Arg   string   `yaml:"arg,omitempty"`
+	Lines string   `yaml:"lines,omitempty"`
+}
+
+// LoopVars exposes the current loop item and index
+// to step templating as `{[{ .Loop.Item }]}` and `{[{ .Loop.Index }]}`
+type LoopVars struct {
+	Item  string
+	Index int
+}
+
+// loopIteration records the actions used for a single
+// iteration of a loop so that it can be cleaned up later
+type loopIteration struct {
+	item    string
+	result  *ActResult
+	cleanup Action
+}
+
+// UnmarshalYAML allows a LoopSpec to be specified
+// either as a mapping or as a literal list of items
+func (l *LoopSpec) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.SequenceNode {
+		return node.Decode(&l.Items)
+	}
+
+	// Use of this auxiliary type prevents infinite recursion
+	type loopSpecTmp LoopSpec
+	var tmp loopSpecTmp
+	if err := node.Decode(&tmp); err != nil {
+		return err
+	}
+	if err := parseutils.CheckKnownFields(node, &tmp); err != nil {
+		return err
+	}
+	*l = LoopSpec(tmp)
+	return nil
+}
+
+// Validate checks that exactly one item source is specified
+func (l *LoopSpec) Validate() error {
+	sourceCount := 0
+	if l.Items != nil {
+		sourceCount++
+	}
+	if l.Arg != "" {
+		sourceCount++
+	}
+	if l.Lines != "" {
+		sourceCount++
+	}
+	if sourceCount != 1 {
+		return errors.New("loop must specify exactly one of `items`, `arg`, or `lines`")
+	}
+	return nil
+}
+
+// resolveItems computes the list of items over which to loop
+// based on the current state of the TTP execution
+func (l *LoopSpec) resolveItems(execCtx TTPExecutionContext) ([]string, error) {
+	switch {
+	case l.Items != nil:
+		items := make([]string, len(l.Items))
+		for idx, item := range l.Items {
+			templated, err := execCtx.templateStep(item)
+			if err != nil {
+				return nil, err
+			}
+			items[idx] = templated
+		}
+		return items, nil
+	case l.Arg != "":
+		var argVal any
+		var ok bool
+		if execCtx.Vars != nil {
+			argVal, ok = execCtx.Vars.Args[l.Arg]
+		}
+		if !ok {
+			return nil, fmt.Errorf("loop references unknown argument %q", l.Arg)
+		}
+		items, ok := argVal.([]string)
+		if !ok {
+			return nil, fmt.Errorf("loop argument %q must have `type: list`", l.Arg)
+		}
+		return items, nil
+	default:
+		templated, err := execCtx.templateStep(l.Lines)
+		if err != nil {
+			return nil, err
+		}
+		var items []string
+		for _, line := range strings.Split(templated, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				items = append(items, line)
+			}
+		}
+		return items, nil
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoopSpecUnmarshalYAML(t *testing.T) {
+	testCases := []struct {
+		name              string
+		content           string
+		expected          LoopSpec
+		wantValidateError bool
+	}{
+		{
+			name:     "Literal list shorthand",
+			content:  `[a, b, c]`,
+			expected: LoopSpec{Items: []string{"a", "b", "c"}},
+		},
+		{
+			name:     "Argument source",
+			content:  `arg: hosts`,
+			expected: LoopSpec{Arg: "hosts"},
+		},
+		{
+			name:     "Lines source",
+			content:  `lines: $forge.steps.list.stdout`,
+			expected: LoopSpec{Lines: "$forge.steps.list.stdout"},
+		},
+		{
+			name: "Multiple sources",
+			content: `arg: hosts
+lines: foo`,
+			expected:          LoopSpec{Arg: "hosts", Lines: "foo"},
+			wantValidateError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var spec LoopSpec
+			require.NoError(t, yaml.Unmarshal([]byte(tc.content), &spec))
+			assert.Equal(t, tc.expected, spec)
+			err := spec.Validate()
+			if tc.wantValidateError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoopSteps(t *testing.T) {
+	testCases := []struct {
+		name               string
+		content            string
+		args               map[string]any
+		expectedStdout     string
+		expectedIterations int
+		wantUnmarshalError bool
+		wantExecuteError   bool
+	}{
+		{
+			name: "Literal items with per-iteration cleanup",
+			content: `name: loop_test
+steps:
+  - name: spray
+    loop: [alpha, beta, gamma]
+    inline: echo "{[{ .Loop.Index }]} {[{ .Loop.Item }]}"
+    cleanup:
+      inline: echo "cleanup {[{ .Loop.Item }]}"`,
+			expectedStdout:     "0 alpha\n1 beta\n2 gamma\ncleanup gamma\ncleanup beta\ncleanup alpha\n",
+			expectedIterations: 3,
+		},
+		{
+			name: "List argument via for_each",
+			content: `name: loop_test
+steps:
+  - name: spray
+    for_each:
+      arg: hosts
+    print_str: "host {[{ .Loop.Item }]}"`,
+			args: map[string]any{
+				"hosts": []string{"h1", "h2"},
+			},
+			expectedStdout:     "host h1\nhost h2\n",
+			expectedIterations: 2,
+		},
+		{
+			name: "Lines from previous step output",
+			content: `name: loop_test
+steps:
+  - name: list_files
+    inline: printf "one\ntwo\n\n"
+  - name: use_files
+    loop:
+      lines: "{[{ .Steps.list_files.Stdout }]}"
+    inline: echo "file {[{ .Loop.Item }]}"`,
+			expectedStdout:     "one\ntwo\n\nfile one\nfile two\n",
+			expectedIterations: 2,
+		},
+		{
+			name: "Failed iteration cleans up earlier iterations",
+			content: `name: loop_test
+steps:
+  - name: spray
+    loop: ["true", "false", "true"]
+    inline: '{[{ .Loop.Item }]} && echo "ran {[{ .Loop.Index }]}"'
+    cleanup:
+      inline: echo "cleanup {[{ .Loop.Index }]}"`,
+			expectedStdout:   "ran 0\ncleanup 0\n",
+			wantExecuteError: true,
+		},
+		{
+			name: "Argument is not a list",
+			content: `name: loop_test
+steps:
+  - name: spray
+    loop:
+      arg: host
+    inline: echo {[{ .Loop.Item }]}`,
+			args: map[string]any{
+				"host": "h1",
+			},
+			wantExecuteError: true,
+		},
+		{
+			name: "Both loop and for_each",
+			content: `name: loop_test
+steps:
+  - name: spray
+    loop: [a]
+    for_each: [b]
+    inline: echo {[{ .Loop.Item }]}`,
+			wantUnmarshalError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			if tc.wantUnmarshalError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			execCtx.Vars.Args = tc.args
+			require.NoError(t, ttp.Validate(execCtx))
+
+			err = ttp.Execute(execCtx)
+			if tc.wantExecuteError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, ttp.RunCleanup(execCtx))
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+
+			if tc.wantExecuteError {
+				return
+			}
+			lastResult := execCtx.StepResults.ByIndex[len(execCtx.StepResults.ByIndex)-1]
+			assert.Len(t, lastResult.Iterations, tc.expectedIterations)
+			assert.Nil(t, execCtx.Vars.Loop)
+		})
+	}
+}
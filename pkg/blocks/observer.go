@@ -0,0 +1,120 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"sync"
+)
+
+// Observer is notified of the progress of a TTP execution. Observers can
+// be used to add telemetry, notifications or custom reports without
+// modifying the engine. Register observers with
+// TTPExecutionContext.AddObserver before executing the TTP.
+//
+// Observers are also notified of the steps of sub TTPs (including sub
+// TTPs run by parallel steps) - the ttp parameter of each method
+// identifies which TTP the event belongs to. Observer methods are
+// called synchronously, so they should return quickly. Calls are
+// serialized, so observers need no locking of their own even though
+// the children of parallel steps may notify them from several goroutines.
+type Observer interface {
+	// OnTTPStart is called before the first step of the TTP runs
+	OnTTPStart(ttp *TTP)
+	// OnStepStart is called before the step runs
+	OnStepStart(ttp *TTP, stepIdx int, step *Step)
+	// OnStepResult is called once the step has finished and its checks
+	// have been verified. The result is nil if the step failed - err is
+	// the error of the step or of its checks.
+	// Steps that were skipped are reported with a Skipped result
+	// and without a preceding call to OnStepStart.
+	OnStepResult(ttp *TTP, stepIdx int, step *Step, result *ExecutionResult, err error)
+	// OnCleanupStart is called before the cleanup of the step runs.
+	// This includes the cleanup of failed steps, of attempts that are
+	// retried and of the children of parallel steps - for the latter,
+	// stepIdx is the index of the child within the parallel step.
+	OnCleanupStart(ttp *TTP, stepIdx int, step *Step)
+	// OnCleanupResult is called once the cleanup of the step has finished
+	OnCleanupResult(ttp *TTP, stepIdx int, step *Step, result *ActResult, err error)
+	// OnTTPEnd is called once all steps of the TTP have run (or the TTP
+	// has stopped early) but before cleanup starts
+	OnTTPEnd(ttp *TTP, err error)
+}
+
+// BaseObserver implements every method of Observer by doing nothing.
+// Embed it in observers that are only interested in some of the events.
+type BaseObserver struct{}
+
+// OnTTPStart does nothing
+func (BaseObserver) OnTTPStart(*TTP) {}
+
+// OnStepStart does nothing
+func (BaseObserver) OnStepStart(*TTP, int, *Step) {}
+
+// OnStepResult does nothing
+func (BaseObserver) OnStepResult(*TTP, int, *Step, *ExecutionResult, error) {}
+
+// OnCleanupStart does nothing
+func (BaseObserver) OnCleanupStart(*TTP, int, *Step) {}
+
+// OnCleanupResult does nothing
+func (BaseObserver) OnCleanupResult(*TTP, int, *Step, *ActResult, error) {}
+
+// OnTTPEnd does nothing
+func (BaseObserver) OnTTPEnd(*TTP, error) {}
+
+// observerSet holds the observers of an execution. It is shared
+// with the contexts of sub TTPs and of the children of parallel steps.
+type observerSet struct {
+	mu        sync.Mutex
+	observers []Observer
+}
+
+// AddObserver registers an observer that is notified of the
+// progress of TTPs executed with this context
+func (c *TTPExecutionContext) AddObserver(observer Observer) {
+	if c.observers == nil {
+		c.observers = &observerSet{}
+	}
+	c.observers.mu.Lock()
+	defer c.observers.mu.Unlock()
+	c.observers.observers = append(c.observers.observers, observer)
+}
+
+// notify calls the provided function for every registered observer
+func (c TTPExecutionContext) notify(event func(Observer)) {
+	if c.observers == nil {
+		return
+	}
+	c.observers.mu.Lock()
+	defer c.observers.mu.Unlock()
+	for _, observer := range c.observers.observers {
+		event(observer)
+	}
+}
+
+// cleanupAndNotify runs the cleanup of the step, notifying
+// the observers of the context before and after it runs
+func (s *Step) cleanupAndNotify(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	execCtx.notify(func(o Observer) { o.OnCleanupStart(execCtx.ttp, s.index, s) })
+	result, err := s.Cleanup(ctx, execCtx)
+	execCtx.notify(func(o Observer) { o.OnCleanupResult(execCtx.ttp, s.index, s, result, err) })
+	return result, err
+}
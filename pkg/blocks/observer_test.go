@@ -0,0 +1,285 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver records every event as a string
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) OnTTPStart(ttp *TTP) {
+	o.events = append(o.events, "ttp start "+ttp.Name)
+}
+
+func (o *recordingObserver) OnStepStart(ttp *TTP, stepIdx int, step *Step) {
+	o.events = append(o.events, fmt.Sprintf("step start %v #%d %v", ttp.Name, stepIdx+1, step.Name))
+}
+
+func (o *recordingObserver) OnStepResult(ttp *TTP, stepIdx int, step *Step, result *ExecutionResult, err error) {
+	status := "ok"
+	switch {
+	case err != nil:
+		status = "failed"
+	case result.Skipped:
+		status = "skipped"
+	}
+	o.events = append(o.events, fmt.Sprintf("step result %v #%d %v %v", ttp.Name, stepIdx+1, step.Name, status))
+}
+
+func (o *recordingObserver) OnCleanupStart(ttp *TTP, stepIdx int, step *Step) {
+	o.events = append(o.events, fmt.Sprintf("cleanup start %v #%d %v", ttp.Name, stepIdx+1, step.Name))
+}
+
+func (o *recordingObserver) OnCleanupResult(ttp *TTP, stepIdx int, step *Step, result *ActResult, err error) {
+	var stdout string
+	if result != nil {
+		stdout = result.Stdout
+	}
+	o.events = append(o.events, fmt.Sprintf("cleanup result %v #%d %v %q", ttp.Name, stepIdx+1, step.Name, stdout))
+}
+
+func (o *recordingObserver) OnTTPEnd(ttp *TTP, err error) {
+	o.events = append(o.events, fmt.Sprintf("ttp end %v %v", ttp.Name, err != nil))
+}
+
+// stepCountObserver only counts steps and relies on BaseObserver for everything else
+type stepCountObserver struct {
+	BaseObserver
+	steps int
+}
+
+func (o *stepCountObserver) OnStepStart(*TTP, int, *Step) {
+	o.steps++
+}
+
+func TestObservers(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		expectedEvents []string
+		expectedSteps  int
+		wantError      bool
+	}{
+		{
+			name: "Steps, skipped steps and cleanup",
+			content: `name: parent
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: skipped
+    if: false
+    inline: echo skipped
+  - name: third
+    inline: echo third`,
+			expectedEvents: []string{
+				"ttp start parent",
+				"step start parent #1 first",
+				"step result parent #1 first ok",
+				"step result parent #2 skipped skipped",
+				"step start parent #3 third",
+				"step result parent #3 third ok",
+				"ttp end parent false",
+				"cleanup start parent #3 third",
+				`cleanup result parent #3 third ""`,
+				"cleanup start parent #1 first",
+				`cleanup result parent #1 first "cleanup first\n"`,
+			},
+			expectedSteps: 2,
+		},
+		{
+			name: "Failed step",
+			content: `name: parent
+steps:
+  - name: fails
+    inline: exit 1
+  - name: never
+    inline: echo never`,
+			expectedEvents: []string{
+				"ttp start parent",
+				"step start parent #1 fails",
+				"step result parent #1 fails failed",
+				"ttp end parent true",
+			},
+			expectedSteps: 1,
+			wantError:     true,
+		},
+		{
+			name: "Sub TTP",
+			content: `name: parent
+steps:
+  - name: sub
+    ttp: with/cleanup.yaml`,
+			expectedEvents: []string{
+				"ttp start parent",
+				"step start parent #1 sub",
+				"ttp start with-cleanup",
+				"step start with-cleanup #1 sub_step_1",
+				"step result with-cleanup #1 sub_step_1 ok",
+				"step start with-cleanup #2 sub_step_2",
+				"step result with-cleanup #2 sub_step_2 ok",
+				"ttp end with-cleanup false",
+				"step result parent #1 sub ok",
+				"ttp end parent false",
+				"cleanup start parent #1 sub",
+				"cleanup start with-cleanup #2 sub_step_2",
+				`cleanup result with-cleanup #2 sub_step_2 "cleanup_sub_step_2\n"`,
+				"cleanup start with-cleanup #1 sub_step_1",
+				`cleanup result with-cleanup #1 sub_step_1 "cleanup_sub_step_1\n"`,
+				`cleanup result parent #1 sub "cleanup_sub_step_1\ncleanup_sub_step_2\n"`,
+			},
+			expectedSteps: 3,
+		},
+		{
+			name: "Failed sub TTP is cleaned up",
+			content: `name: parent
+steps:
+  - name: sub
+    ttp: with/failure.yaml`,
+			expectedEvents: []string{
+				"ttp start parent",
+				"step start parent #1 sub",
+				"ttp start with-failure",
+				"step start with-failure #1 sub_step_1",
+				"step result with-failure #1 sub_step_1 ok",
+				"step start with-failure #2 sub_step_2",
+				"step result with-failure #2 sub_step_2 failed",
+				"ttp end with-failure true",
+				"cleanup start parent #1 sub",
+				"cleanup start with-failure #1 sub_step_1",
+				`cleanup result with-failure #1 sub_step_1 "cleanup_sub_step_1\n"`,
+				`cleanup result parent #1 sub "cleanup_sub_step_1\n"`,
+				"step result parent #1 sub failed",
+				"ttp end parent true",
+			},
+			expectedSteps: 3,
+			wantError:     true,
+		},
+		{
+			name: "Retried attempts are cleaned up",
+			content: `name: parent
+steps:
+  - name: retried
+    inline: test -f MARKER && touch MARKER.done; touch MARKER
+    retry:
+      attempts: 2
+      retry_on: [checks]
+    checks:
+      - msg: second attempt
+        path_exists: MARKER.done
+    cleanup:
+      inline: echo cleanup retried`,
+			expectedEvents: []string{
+				"ttp start parent",
+				"step start parent #1 retried",
+				"cleanup start parent #1 retried",
+				`cleanup result parent #1 retried "cleanup retried\n"`,
+				"step result parent #1 retried ok",
+				"ttp end parent false",
+				"cleanup start parent #1 retried",
+				`cleanup result parent #1 retried "cleanup retried\n"`,
+			},
+			expectedSteps: 1,
+		},
+		{
+			name: "Parallel steps",
+			content: `name: parent
+steps:
+  - name: group
+    parallel:
+      - name: sub
+        ttp: with/cleanup.yaml
+      - name: child
+        inline: echo child
+        cleanup:
+          inline: echo cleanup child
+    max_concurrency: 1`,
+			expectedEvents: []string{
+				"ttp start parent",
+				"step start parent #1 group",
+				"ttp start with-cleanup",
+				"step start with-cleanup #1 sub_step_1",
+				"step result with-cleanup #1 sub_step_1 ok",
+				"step start with-cleanup #2 sub_step_2",
+				"step result with-cleanup #2 sub_step_2 ok",
+				"ttp end with-cleanup false",
+				"step result parent #1 group ok",
+				"ttp end parent false",
+				"cleanup start parent #1 group",
+				"cleanup start parent #2 child",
+				`cleanup result parent #2 child "cleanup child\n"`,
+				"cleanup start parent #1 sub",
+				"cleanup start with-cleanup #2 sub_step_2",
+				`cleanup result with-cleanup #2 sub_step_2 "cleanup_sub_step_2\n"`,
+				"cleanup start with-cleanup #1 sub_step_1",
+				`cleanup result with-cleanup #1 sub_step_1 "cleanup_sub_step_1\n"`,
+				`cleanup result parent #1 sub "cleanup_sub_step_1\ncleanup_sub_step_2\n"`,
+				`cleanup result parent #1 group "cleanup child\ncleanup_sub_step_1\ncleanup_sub_step_2\n"`,
+			},
+			expectedSteps: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.content = strings.ReplaceAll(tc.content, "MARKER", filepath.Join(t.TempDir(), "marker"))
+			spec := repos.Spec{
+				Name: "b",
+				Path: "repos/b",
+			}
+			repo, err := spec.Load(makeTestFsForSubTTPs(t), "")
+			require.NoError(t, err)
+
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Repo = repo
+			observer := &recordingObserver{}
+			counter := &stepCountObserver{}
+			execCtx.AddObserver(observer)
+			execCtx.AddObserver(counter)
+			require.NoError(t, ttp.Validate(execCtx))
+
+			err = ttp.Execute(execCtx)
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, ttp.RunCleanup(execCtx))
+
+			assert.Equal(t, tc.expectedEvents, observer.events)
+			assert.Equal(t, tc.expectedSteps, counter.steps)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+)
+
+// ParallelStep runs a group of child steps concurrently.
+// At most MaxConcurrency children run at the same time
+// (if MaxConcurrency is zero, all children run at once).
+//
+// Each child is a regular step with its own checks and cleanup.
+// Child results are recorded under their own step names
+// so that later steps can reference them.
+type ParallelStep struct {
+	actionDefaults `yaml:",inline"`
+	Steps          []Step `yaml:"parallel,omitempty"`
+	MaxConcurrency int    `yaml:"max_concurrency,omitempty"`
+
+	children []*parallelChild
+}
+
+// parallelChild tracks the execution of a single child step
+type parallelChild struct {
+	step   *Step
+	result *ExecutionResult
+	err    error
+	vars   *TTPExecutionVars
+}
+
+// NewParallelStep creates a new ParallelStep and returns a pointer to it.
+func NewParallelStep() *ParallelStep {
+	return &ParallelStep{}
+}
+
+// IsNil checks if the step is nil or empty and returns a boolean value.
+func (p *ParallelStep) IsNil() bool {
+	return len(p.Steps) == 0
+}
+
+// Validate validates each of the child steps
+func (p *ParallelStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
+	if len(p.Steps) == 0 {
+		return errors.New("parallel must contain at least one step")
+	}
+	if p.MaxConcurrency < 0 {
+		return errors.New("max_concurrency cannot be negative")
+	}
+	names := make(map[string]bool)
+	for idx := range p.Steps {
+		child := &p.Steps[idx]
+		if names[child.Name] {
+			return fmt.Errorf("duplicate step name %q in parallel group", child.Name)
+		}
+		names[child.Name] = true
+		if err := child.Validate(ctx, execCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Template is a no-op - child steps are templated
+// when they begin executing
+func (p *ParallelStep) Template(_ context.Context, _ TTPExecutionContext) error {
+	return nil
+}
+
+// Execute runs all of the child steps concurrently and waits for them to finish.
+// Every child is run to completion even if some of them fail.
+func (p *ParallelStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	limit := p.MaxConcurrency
+	if limit == 0 || limit > len(p.Steps) {
+		limit = len(p.Steps)
+	}
+	logging.L().Infof("Running %d steps in parallel (max concurrency: %d)", len(p.Steps), limit)
+
+	childCfg := execCtx.Cfg
+	if childCfg.Stdout != nil {
+		childCfg.Stdout = &syncWriter{writer: childCfg.Stdout}
+	}
+	if childCfg.Stderr != nil {
+		childCfg.Stderr = &syncWriter{writer: childCfg.Stderr}
+	}
+
+	p.children = make([]*parallelChild, len(p.Steps))
+	semaphore := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for idx := range p.Steps {
+		child := &parallelChild{
+			step: &p.Steps[idx],
+			vars: execCtx.Vars.copy(),
+		}
+		p.children[idx] = child
+		childCtx := execCtx
+		childCtx.Cfg = childCfg
+		childCtx.Vars = child.vars
+		// the state of the group is recorded once all children finish
+		childCtx.state = nil
+		p.Steps[idx].index = idx
+
+		// acquiring the semaphore before starting the goroutine
+		// ensures that children start in the order they are declared
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			child.run(ctx, childCtx)
+		}()
+	}
+	wg.Wait()
+
+	// now that all children are done, it is safe
+	// to publish their results and variables
+	var actResults []*ActResult
+	var errs []error
+	for _, child := range p.children {
+		for k, v := range child.vars.StepVars {
+			execCtx.Vars.StepVars[k] = v
+		}
+		for k, v := range child.vars.Env {
+			execCtx.Vars.setEnv(k, v)
+		}
+		if child.result != nil {
+			execCtx.StepResults.ByName[child.step.Name] = child.result
+			actResults = append(actResults, &child.result.ActResult)
+		}
+		if child.err != nil {
+			errs = append(errs, fmt.Errorf("parallel step %q failed: %w", child.step.Name, child.err))
+		}
+	}
+	for _, child := range p.children {
+		if child.result != nil && child.err == nil {
+			if err := child.step.templateCleanup(ctx, execCtx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return aggregateResults(actResults), errors.Join(errs...)
+}
+
+// ChildResults returns the results of every child
+// step that completed (or was skipped), in step order
+func (p *ParallelStep) ChildResults() []*ExecutionResult {
+	var results []*ExecutionResult
+	for _, child := range p.children {
+		if child.result != nil {
+			results = append(results, child.result)
+		}
+	}
+	return results
+}
+
+func (c *parallelChild) run(ctx context.Context, execCtx TTPExecutionContext) {
+	shouldRun, err := c.step.ShouldRun(execCtx)
+	if err != nil {
+		c.err = err
+		return
+	}
+	if !shouldRun {
+		logging.L().Infof("Skipping parallel step %q - `if:` condition is false", c.step.Name)
+		c.result = &ExecutionResult{
+			Name:    c.step.Name,
+			Skipped: true,
+		}
+		return
+	}
+
+	logging.L().Infof("Starting parallel step %q", c.step.Name)
+	startTime := time.Now()
+	actResult, err := c.step.Run(ctx, execCtx)
+	if err != nil {
+		// some steps (such as sub-TTPs) must be cleaned up even on failure
+		if c.step.ShouldCleanupOnFailure() {
+			logging.L().Infof("[+] Cleaning up failed parallel step %s", c.step.Name)
+			if _, cleanupErr := c.step.cleanupAndNotify(ctx, execCtx); cleanupErr != nil {
+				logging.L().Errorf("Error cleaning up failed step %v: %v", c.step.Name, cleanupErr)
+			}
+		}
+		c.err = err
+		return
+	}
+	actResult.StartTime = startTime
+	actResult.EndTime = time.Now()
+	c.result = &ExecutionResult{
+		Name:       c.step.Name,
+		ActResult:  *actResult,
+		Attempts:   c.step.attempts,
+		Error:      c.step.IgnoredError(),
+		Iterations: c.step.IterationResults(),
+	}
+	// failed checks do not prevent cleanup of the child
+	c.err = c.step.VerifyChecks()
+}
+
+// GetDefaultCleanupAction will instruct the calling code
+// to clean up every child step that completed successfully
+func (p *ParallelStep) GetDefaultCleanupAction() Action {
+	return &parallelCleanupAction{
+		step: p,
+	}
+}
+
+// parallelCleanupAction cleans up the completed
+// children of a ParallelStep in reverse order
+type parallelCleanupAction struct {
+	actionDefaults
+	step *ParallelStep
+}
+
+// IsNil is not needed here, as this is not a user-accessible step type
+func (a *parallelCleanupAction) IsNil() bool {
+	return false
+}
+
+// Validate is not needed here, as this is not a user-accessible step type
+func (a *parallelCleanupAction) Validate(_ context.Context, _ TTPExecutionContext) error {
+	return nil
+}
+
+// Template is not needed here, as this is not a user-accessible step type
+func (a *parallelCleanupAction) Template(_ context.Context, _ TTPExecutionContext) error {
+	return nil
+}
+
+// Execute cleans up each completed child step
+func (a *parallelCleanupAction) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	var cleanupResults []*ActResult
+	var errs []error
+	children := a.step.children
+	for idx := len(children) - 1; idx >= 0; idx-- {
+		child := children[idx]
+		if child.result == nil || child.result.Skipped {
+			continue
+		}
+		logging.L().Infof("Cleaning up parallel step %q", child.step.Name)
+		startTime := time.Now()
+		cleanupResult, err := child.step.cleanupAndNotify(ctx, execCtx)
+		if err != nil {
+			logging.L().Errorf("error cleaning up parallel step %q: %v", child.step.Name, err)
+			errs = append(errs, err)
+		}
+		if cleanupResult != nil {
+			cleanupResult.StartTime = startTime
+			cleanupResult.EndTime = time.Now()
+			child.result.Cleanup = cleanupResult
+			cleanupResults = append(cleanupResults, cleanupResult)
+		}
+	}
+	return aggregateResults(cleanupResults), errors.Join(errs...)
+}
+
+// syncWriter serializes writes from concurrently executing steps
+type syncWriter struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func (w *syncWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writer.Write(b)
+}
@@ -0,0 +1,192 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelSteps(t *testing.T) {
+	testCases := []struct {
+		name             string
+		content          string
+		expectedStdout   string
+		expectedResults  map[string]string
+		expectedSkipped  []string
+		wantValidateErr  bool
+		wantExecuteError bool
+	}{
+		{
+			name: "Children run concurrently",
+			content: `name: parallel_test
+steps:
+  - name: group
+    parallel:
+      - name: slow
+        inline: sleep 0.5 && echo slow
+      - name: fast
+        inline: echo fast`,
+			expectedStdout: "fast\nslow\n",
+			expectedResults: map[string]string{
+				"slow":  "slow\n",
+				"fast":  "fast\n",
+				"group": "slow\nfast\n",
+			},
+		},
+		{
+			name: "Later steps can reference child results",
+			content: `name: parallel_test
+steps:
+  - name: group
+    max_concurrency: 1
+    parallel:
+      - name: first
+        inline: echo one
+        outputvar: first_var
+      - name: second
+        inline: echo two
+  - name: after
+    inline: echo "$forge.steps.second.stdout {[{ .StepVars.first_var }]}"`,
+			expectedStdout: "one\ntwo\ntwo\n one\n",
+			expectedResults: map[string]string{
+				"after": "two\n one\n",
+			},
+		},
+		{
+			name: "Children are cleaned up in reverse order",
+			content: `name: parallel_test
+steps:
+  - name: group
+    max_concurrency: 1
+    parallel:
+      - name: first
+        inline: echo first
+        cleanup:
+          inline: echo cleanup first
+      - name: second
+        inline: echo second
+        cleanup:
+          inline: echo cleanup second`,
+			expectedStdout: "first\nsecond\ncleanup second\ncleanup first\n",
+		},
+		{
+			name: "Skipped children are not cleaned up",
+			content: `name: parallel_test
+steps:
+  - name: group
+    parallel:
+      - name: skipped
+        if: false
+        inline: echo skipped
+        cleanup:
+          inline: echo cleanup skipped
+      - name: ran
+        inline: echo ran`,
+			expectedStdout:  "ran\n",
+			expectedSkipped: []string{"skipped"},
+		},
+		{
+			name: "Failed child does not stop its siblings",
+			content: `name: parallel_test
+steps:
+  - name: group
+    max_concurrency: 1
+    parallel:
+      - name: fails
+        inline: exit 1
+      - name: succeeds
+        inline: echo succeeds
+        cleanup:
+          inline: echo cleanup succeeds
+  - name: never
+    inline: echo never`,
+			expectedStdout:   "succeeds\ncleanup succeeds\n",
+			wantExecuteError: true,
+		},
+		{
+			name: "Duplicate child names",
+			content: `name: parallel_test
+steps:
+  - name: group
+    parallel:
+      - name: dup
+        inline: echo a
+      - name: dup
+        inline: echo b`,
+			wantValidateErr: true,
+		},
+		{
+			name: "Negative max_concurrency",
+			content: `name: parallel_test
+steps:
+  - name: group
+    max_concurrency: -1
+    parallel:
+      - name: a
+        inline: echo a`,
+			wantValidateErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			err = ttp.Validate(execCtx)
+			if tc.wantValidateErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			err = ttp.Execute(execCtx)
+			if tc.wantExecuteError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, ttp.RunCleanup(execCtx))
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+
+			for name, expectedStdout := range tc.expectedResults {
+				result, ok := execCtx.StepResults.ByName[name]
+				require.True(t, ok, "missing result for step %q", name)
+				assert.Equal(t, expectedStdout, result.Stdout)
+			}
+			for _, name := range tc.expectedSkipped {
+				result, ok := execCtx.StepResults.ByName[name]
+				require.True(t, ok, "missing result for step %q", name)
+				assert.True(t, result.Skipped)
+			}
+			if !tc.wantExecuteError {
+				// only top-level steps are indexed
+				assert.Len(t, execCtx.StepResults.ByIndex, len(ttp.Steps))
+			}
+		})
+	}
+}
@@ -0,0 +1,367 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runtimeReferenceRegexp matches the parts of a step that
+// can only be resolved once the TTP is running
+var runtimeReferenceRegexp = regexp.MustCompile(`\{\[\{.*?\}\]\}|\$forge\.[A-Za-z0-9_.]+`)
+
+// Plan describes what a TTP will do when it is executed. It is built
+// from a loaded TTP (with all arguments rendered) without running any
+// of its steps, for use by `ttpforge run --plan`.
+type Plan struct {
+	TTP         ReportTTPInfo     `json:"ttp"`
+	Args        map[string]any    `json:"args"`
+	MaxDuration string            `json:"max_duration,omitempty"`
+	Outputs     map[string]string `json:"outputs,omitempty"`
+	Steps       []PlanStep        `json:"steps"`
+}
+
+// PlanStep describes an individual step of a Plan.
+// RuntimeReferences lists the step templates (`{[{ }]}`) and `$forge.`
+// references that can only be resolved once the TTP is running.
+// SubTTP holds the plan of the TTP run by a sub TTP step and
+// Children holds the steps of a parallel group.
+type PlanStep struct {
+	Index             int            `json:"index"`
+	Name              string         `json:"name"`
+	If                string         `json:"if,omitempty"`
+	Loop              map[string]any `json:"loop,omitempty"`
+	Retry             map[string]any `json:"retry,omitempty"`
+	Timeout           string         `json:"timeout,omitempty"`
+	ExpectExitCode    []int          `json:"expect_exit_code,omitempty"`
+	ContinueOnError   bool           `json:"continue_on_error,omitempty"`
+	Action            PlanAction     `json:"action"`
+	Cleanup           *PlanAction    `json:"cleanup,omitempty"`
+	Checks            []any          `json:"checks,omitempty"`
+	RuntimeReferences []string       `json:"runtime_references,omitempty"`
+	SubTTP            *Plan          `json:"sub_ttp,omitempty"`
+	Children          []PlanStep     `json:"children,omitempty"`
+}
+
+// PlanAction describes an action (or cleanup action) with its resolved fields.
+// Actions that consist of several actions list them in Actions.
+type PlanAction struct {
+	Type    string         `json:"type"`
+	Fields  map[string]any `json:"fields,omitempty"`
+	Actions []PlanAction   `json:"actions,omitempty"`
+}
+
+// NewPlan builds the execution plan of a loaded and validated TTP.
+//
+// **Parameters:**
+//
+// ttp: the TTP to plan, as returned by LoadTTP
+// execCtx: the execution context returned by LoadTTP
+//
+// **Returns:**
+//
+// *Plan: the execution plan
+// error: an error if the plan could not be built
+func NewPlan(ttp *TTP, execCtx TTPExecutionContext) (*Plan, error) {
+	plan := &Plan{
+		TTP: ReportTTPInfo{
+			UUID:        ttp.UUID,
+			Name:        ttp.Name,
+			Description: ttp.Description,
+			Path:        ttp.FilePath,
+			Mitre:       ttp.MitreAttackMapping,
+		},
+		Args:        map[string]any{},
+		MaxDuration: ttp.MaxDuration,
+		Outputs:     ttp.Outputs,
+		Steps:       []PlanStep{},
+	}
+	if execCtx.Vars != nil && execCtx.Vars.Args != nil {
+		plan.Args = execCtx.Vars.Args
+	}
+	for idx := range ttp.Steps {
+		planStep, err := newPlanStep(idx, &ttp.Steps[idx])
+		if err != nil {
+			return nil, fmt.Errorf("could not plan step %q: %w", ttp.Steps[idx].Name, err)
+		}
+		plan.Steps = append(plan.Steps, planStep)
+	}
+	return plan, nil
+}
+
+func newPlanStep(idx int, step *Step) (PlanStep, error) {
+	planStep := PlanStep{
+		Index:           idx,
+		Name:            step.Name,
+		If:              step.If,
+		Timeout:         step.Timeout,
+		ExpectExitCode:  step.ExpectExitCode,
+		ContinueOnError: step.ContinueOnError,
+	}
+	var err error
+	if step.Loop != nil {
+		if planStep.Loop, err = toPlanFields(step.Loop); err != nil {
+			return planStep, err
+		}
+	}
+	if step.Retry != nil {
+		if planStep.Retry, err = toPlanFields(step.Retry); err != nil {
+			return planStep, err
+		}
+	}
+	if planStep.Action, err = newPlanAction(step.actionKey, step.action); err != nil {
+		return planStep, err
+	}
+	if step.cleanup != nil {
+		cleanup, err := newPlanAction(step.cleanupKey, step.cleanup)
+		if err != nil {
+			return planStep, err
+		}
+		planStep.Cleanup = &cleanup
+	}
+	for _, check := range step.Checks {
+		var checkFields any
+		if err := roundTripYAML(check, &checkFields); err != nil {
+			return planStep, err
+		}
+		planStep.Checks = append(planStep.Checks, checkFields)
+	}
+	if step.node != nil {
+		raw, err := yaml.Marshal(step.node)
+		if err != nil {
+			return planStep, err
+		}
+		planStep.RuntimeReferences = findRuntimeReferences(string(raw))
+	}
+
+	switch action := step.action.(type) {
+	case *SubTTPStep:
+		if action.ttp != nil && action.subExecCtx != nil {
+			if planStep.SubTTP, err = NewPlan(action.ttp, *action.subExecCtx); err != nil {
+				return planStep, err
+			}
+		}
+	case *ParallelStep:
+		for childIdx := range action.Steps {
+			child, err := newPlanStep(childIdx, &action.Steps[childIdx])
+			if err != nil {
+				return planStep, fmt.Errorf("could not plan step %q: %w", action.Steps[childIdx].Name, err)
+			}
+			planStep.Children = append(planStep.Children, child)
+		}
+	}
+	return planStep, nil
+}
+
+// newPlanAction describes an action along with the key
+// that identified its type when the step was parsed - actions that
+// were not parsed from YAML (such as default cleanup actions) are
+// identified by the key under which their type is registered
+func newPlanAction(key string, action Action) (PlanAction, error) {
+	if key == "" {
+		key = registeredActionKey(action)
+	}
+	switch a := action.(type) {
+	case *CompositeAction:
+		planAction := PlanAction{Type: "composite"}
+		for _, subAction := range a.actions {
+			subPlanAction, err := newPlanAction("", subAction)
+			if err != nil {
+				return planAction, err
+			}
+			planAction.Actions = append(planAction.Actions, subPlanAction)
+		}
+		return planAction, nil
+	case *subTTPCleanupAction:
+		return PlanAction{Type: "sub_ttp_cleanup"}, nil
+	case *parallelCleanupAction:
+		return PlanAction{Type: "parallel_cleanup"}, nil
+	case *ParallelStep:
+		// the steps of the group are planned as children
+		planAction := PlanAction{Type: key}
+		if a.MaxConcurrency > 0 {
+			planAction.Fields = map[string]any{"max_concurrency": a.MaxConcurrency}
+		}
+		return planAction, nil
+	}
+
+	fields, err := toPlanFields(action)
+	if err != nil {
+		return PlanAction{}, err
+	}
+	return PlanAction{
+		Type:   key,
+		Fields: fields,
+	}, nil
+}
+
+// toPlanFields converts a struct to a map of its YAML fields
+func toPlanFields(v any) (map[string]any, error) {
+	fields := map[string]any{}
+	if err := roundTripYAML(v, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func roundTripYAML(v any, out any) error {
+	raw, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return yaml.Unmarshal(raw, out)
+}
+
+func findRuntimeReferences(s string) []string {
+	seen := map[string]bool{}
+	var refs []string
+	for _, ref := range runtimeReferenceRegexp.FindAllString(s, -1) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// WriteJSON writes the plan to w as indented JSON
+func (p *Plan) WriteJSON(w io.Writer) error {
+	planBytes, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize plan: %w", err)
+	}
+	_, err = w.Write(append(planBytes, '\n'))
+	return err
+}
+
+// WriteText writes a human-readable form of the plan to w
+func (p *Plan) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	p.writeText(&sb, "")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (p *Plan) writeText(sb *strings.Builder, indent string) {
+	fmt.Fprintf(sb, "%sTTP: %v\n", indent, p.TTP.Name)
+	if p.TTP.Path != "" {
+		fmt.Fprintf(sb, "%sPath: %v\n", indent, p.TTP.Path)
+	}
+	if len(p.Args) > 0 {
+		fmt.Fprintf(sb, "%sArgs:\n", indent)
+		writeIndentedYAML(sb, p.Args, indent+"  ")
+	}
+	if p.MaxDuration != "" {
+		fmt.Fprintf(sb, "%sMax Duration: %v\n", indent, p.MaxDuration)
+	}
+	fmt.Fprintf(sb, "%sSteps:\n", indent)
+	for _, step := range p.Steps {
+		step.writeText(sb, indent+"  ")
+	}
+	if len(p.Outputs) > 0 {
+		fmt.Fprintf(sb, "%sOutputs:\n", indent)
+		writeIndentedYAML(sb, p.Outputs, indent+"  ")
+	}
+}
+
+func (s *PlanStep) writeText(sb *strings.Builder, indent string) {
+	fmt.Fprintf(sb, "%s#%d %v\n", indent, s.Index+1, s.Name)
+	indent += "   "
+	if s.If != "" {
+		fmt.Fprintf(sb, "%sRuns if: %v\n", indent, s.If)
+	}
+	if s.Loop != nil {
+		fmt.Fprintf(sb, "%sLoop:\n", indent)
+		writeIndentedYAML(sb, s.Loop, indent+"  ")
+	}
+	if s.Retry != nil {
+		fmt.Fprintf(sb, "%sRetry:\n", indent)
+		writeIndentedYAML(sb, s.Retry, indent+"  ")
+	}
+	if s.Timeout != "" {
+		fmt.Fprintf(sb, "%sTimeout: %v\n", indent, s.Timeout)
+	}
+	if s.ExpectExitCode != nil {
+		fmt.Fprintf(sb, "%sExpected exit codes: %v\n", indent, s.ExpectExitCode)
+	}
+	if s.ContinueOnError {
+		fmt.Fprintf(sb, "%sContinues on error\n", indent)
+	}
+	fmt.Fprintf(sb, "%sAction:\n", indent)
+	s.Action.writeText(sb, indent+"  ")
+	if s.Cleanup != nil {
+		fmt.Fprintf(sb, "%sCleanup:\n", indent)
+		s.Cleanup.writeText(sb, indent+"  ")
+	}
+	if len(s.Checks) > 0 {
+		fmt.Fprintf(sb, "%sChecks:\n", indent)
+		writeIndentedYAML(sb, s.Checks, indent+"  ")
+	}
+	if len(s.RuntimeReferences) > 0 {
+		fmt.Fprintf(sb, "%sResolved at run time:\n", indent)
+		for _, ref := range s.RuntimeReferences {
+			fmt.Fprintf(sb, "%s  %v\n", indent, ref)
+		}
+	}
+	if s.SubTTP != nil {
+		fmt.Fprintf(sb, "%sSub TTP:\n", indent)
+		s.SubTTP.writeText(sb, indent+"  ")
+	}
+	if len(s.Children) > 0 {
+		fmt.Fprintf(sb, "%sParallel steps:\n", indent)
+		for _, child := range s.Children {
+			child.writeText(sb, indent+"  ")
+		}
+	}
+}
+
+func (a *PlanAction) writeText(sb *strings.Builder, indent string) {
+	switch {
+	case a.Actions != nil:
+		for _, action := range a.Actions {
+			action.writeText(sb, indent)
+		}
+	case a.Fields != nil:
+		writeIndentedYAML(sb, a.Fields, indent)
+	default:
+		fmt.Fprintf(sb, "%s%v\n", indent, a.Type)
+	}
+}
+
+func writeIndentedYAML(sb *strings.Builder, v any, indent string) {
+	var buf strings.Builder
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(sb, "%s<could not render: %v>\n", indent, err)
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		fmt.Fprintf(sb, "%s%s\n", indent, line)
+	}
+}
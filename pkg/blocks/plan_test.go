@@ -0,0 +1,173 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	content := `name: plan_test
+description: plans without side effects
+args:
+  - name: target
+    default: victim
+steps:
+  - name: first
+    inline: echo {{ .Args.target }}
+    outputvar: first_output
+    cleanup:
+      inline: echo cleanup {{ .Args.target }}
+    checks:
+      - msg: marker must exist
+        path_exists: /tmp/marker
+  - name: second
+    if: eq .Platform.OS "linux"
+    create_file: /tmp/{[{ .StepVars.first_output }]}
+    contents: '{[{ stdout "first" }]}'
+    cleanup: default
+  - name: group
+    parallel:
+      - name: child
+        inline: echo child
+  - name: sub
+    ttp: with/cleanup.yaml`
+
+	spec := repos.Spec{
+		Name: "b",
+		Path: "repos/b",
+	}
+	repo, err := spec.Load(makeTestFsForSubTTPs(t), "")
+	require.NoError(t, err)
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{
+		Args: map[string]any{"target": "victim"},
+	})
+	require.NoError(t, err)
+	execCtx := NewTTPExecutionContext()
+	execCtx.Cfg.Repo = repo
+	execCtx.Vars.Args = map[string]any{"target": "victim"}
+	require.NoError(t, ttp.Validate(execCtx))
+
+	var stdoutBuf bytes.Buffer
+	execCtx.Cfg.Stdout = &stdoutBuf
+	plan, err := NewPlan(ttp, execCtx)
+	require.NoError(t, err)
+	// planning must not run anything
+	assert.Empty(t, stdoutBuf.String())
+
+	require.Len(t, plan.Steps, 4)
+	first := plan.Steps[0]
+	assert.Equal(t, "inline", first.Action.Type)
+	assert.Equal(t, "echo victim", first.Action.Fields["inline"])
+	require.NotNil(t, first.Cleanup)
+	assert.Equal(t, "echo cleanup victim", first.Cleanup.Fields["inline"])
+	require.Len(t, first.Checks, 1)
+	assert.Empty(t, first.RuntimeReferences)
+
+	second := plan.Steps[1]
+	assert.Equal(t, "create_file", second.Action.Type)
+	assert.Equal(t, `eq .Platform.OS "linux"`, second.If)
+	assert.Equal(t, []string{"{[{ .StepVars.first_output }]}", `{[{ stdout "first" }]}`}, second.RuntimeReferences)
+	require.NotNil(t, second.Cleanup)
+	assert.Equal(t, "remove_path", second.Cleanup.Type)
+
+	group := plan.Steps[2]
+	assert.Equal(t, "parallel", group.Action.Type)
+	require.Len(t, group.Children, 1)
+	assert.Equal(t, "echo child", group.Children[0].Action.Fields["inline"])
+
+	sub := plan.Steps[3]
+	require.NotNil(t, sub.SubTTP)
+	assert.Equal(t, "with-cleanup", sub.SubTTP.TTP.Name)
+	require.Len(t, sub.SubTTP.Steps, 2)
+	assert.Equal(t, "sub_ttp_cleanup", sub.Cleanup.Type)
+
+	var textBuf bytes.Buffer
+	require.NoError(t, plan.WriteText(&textBuf))
+	text := textBuf.String()
+	assert.Contains(t, text, "TTP: plan_test\n")
+	assert.Contains(t, text, "  #1 first\n     Action:\n       executor: bash\n       inline: echo victim\n")
+	assert.Contains(t, text, "     Resolved at run time:\n       {[{ .StepVars.first_output }]}\n")
+	assert.Contains(t, text, "     Action:\n       parallel\n")
+	assert.Contains(t, text, "       TTP: with-cleanup\n")
+
+	var jsonBuf bytes.Buffer
+	require.NoError(t, plan.WriteJSON(&jsonBuf))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(jsonBuf.Bytes(), &decoded))
+	assert.Equal(t, map[string]any{"target": "victim"}, decoded["args"])
+}
+
+func TestPlanActionTypes(t *testing.T) {
+	testCases := []struct {
+		name                string
+		step                string
+		expectedActionType  string
+		expectedCleanupType string
+	}{
+		{
+			name: "Custom action type",
+			step: `registry_test_greet: world
+    cleanup:
+      registry_test_greet: cleanup`,
+			expectedActionType:  "registry_test_greet",
+			expectedCleanupType: "registry_test_greet",
+		},
+		{
+			name:               "Action type with several keys",
+			step:               `kill_process_name: not-a-real-process`,
+			expectedActionType: "kill_process_name",
+		},
+		{
+			name: "Default cleanup",
+			step: `create_file: /tmp/plan_test.txt
+    contents: hello
+    cleanup: default`,
+			expectedActionType:  "create_file",
+			expectedCleanupType: "remove_path",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := "name: plan_types_test\nsteps:\n  - name: step\n    " + tc.step
+			ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+			require.NoError(t, err)
+			plan, err := NewPlan(ttp, NewTTPExecutionContext())
+			require.NoError(t, err)
+
+			require.Len(t, plan.Steps, 1)
+			assert.Equal(t, tc.expectedActionType, plan.Steps[0].Action.Type)
+			if tc.expectedCleanupType == "" {
+				assert.Nil(t, plan.Steps[0].Cleanup)
+				return
+			}
+			require.NotNil(t, plan.Steps[0].Cleanup)
+			assert.Equal(t, tc.expectedCleanupType, plan.Steps[0].Cleanup.Type)
+		})
+	}
+}
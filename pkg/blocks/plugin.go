@@ -0,0 +1,261 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/plugins"
+)
+
+// PluginAction runs an action implemented by an external
+// plugin executable that is found in the configured plugin
+// search paths. TTPForge exchanges JSON messages with the
+// plugin over its stdin and stdout (see the plugins package).
+type PluginAction struct {
+	actionDefaults `yaml:",inline"`
+	Plugin         string         `yaml:"plugin,omitempty"`
+	Args           map[string]any `yaml:"args,omitempty"`
+
+	// state is returned by the plugin when the action
+	// is executed and passed back to it during cleanup
+	state any
+}
+
+// NewPluginAction creates a new PluginAction instance and returns a pointer to it.
+func NewPluginAction() *PluginAction {
+	return &PluginAction{}
+}
+
+// IsNil checks if the step is nil or empty and returns a boolean value.
+func (a *PluginAction) IsNil() bool {
+	return a.Plugin == ""
+}
+
+// Validate checks that the plugin exists and asks it
+// to validate the (not yet templated) arguments of the step.
+// The plugin is not run if the TTP will not be executed (such as
+// for a dry run), since validation must not have side effects then.
+func (a *PluginAction) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
+	if a.Plugin == "" {
+		return fmt.Errorf("plugin field cannot be empty")
+	}
+	if execCtx.containsStepTemplating(a.Plugin) {
+		return nil
+	}
+	if execCtx.Cfg.DryRun {
+		_, err := plugins.Find(execCtx.Cfg.PluginPaths, a.Plugin)
+		return err
+	}
+	_, err := callPlugin(ctx, execCtx, a.Plugin, execCtx.pluginRequest(plugins.MethodValidate, a.Args, nil))
+	return err
+}
+
+// Template takes each applicable field in the step and replaces any template strings with their resolved values.
+//
+// **Returns:**
+//
+// error: error if template resolution fails, nil otherwise
+func (a *PluginAction) Template(_ context.Context, execCtx TTPExecutionContext) error {
+	var err error
+	a.Plugin, err = execCtx.templateStep(a.Plugin)
+	if err != nil {
+		return err
+	}
+	args, err := execCtx.templateValue(a.Args)
+	if err != nil {
+		return err
+	}
+	a.Args, _ = args.(map[string]any)
+	return nil
+}
+
+// Execute sends an execute request to the plugin and returns the
+// output and outputs that it reports
+func (a *PluginAction) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	logging.L().Infof("Running plugin %v", a.Plugin)
+	resp, err := callPlugin(ctx, execCtx, a.Plugin, execCtx.pluginRequest(plugins.MethodExecute, a.Args, nil))
+	if err != nil {
+		return nil, err
+	}
+	a.state = resp.State
+	return pluginResult(execCtx, resp), nil
+}
+
+// GetDefaultCleanupAction will instruct the calling code
+// to send a cleanup request to the plugin once the step has run
+func (a *PluginAction) GetDefaultCleanupAction() Action {
+	return &pluginCleanupAction{step: a}
+}
+
+// pluginCleanupAction sends a cleanup request to a plugin.
+// When it is the default cleanup of a step, the plugin, arguments
+// and state are taken from the PluginAction of that step - the fields
+// are only set directly when the cleanup is recorded in a state file.
+type pluginCleanupAction struct {
+	actionDefaults `yaml:",inline"`
+	Plugin         string         `yaml:"plugin_cleanup,omitempty"`
+	Args           map[string]any `yaml:"args,omitempty"`
+	State          any            `yaml:"state,omitempty"`
+
+	step *PluginAction
+}
+
+// resolve copies the fields of the step being cleaned up (if any)
+func (a *pluginCleanupAction) resolve() {
+	if a.step != nil {
+		a.Plugin = a.step.Plugin
+		a.Args = a.step.Args
+		a.State = a.step.state
+	}
+}
+
+// MarshalYAML records the resolved cleanup action in state files
+func (a *pluginCleanupAction) MarshalYAML() (any, error) {
+	type recordedAction pluginCleanupAction
+	a.resolve()
+	return (*recordedAction)(a), nil
+}
+
+// IsNil checks if the step is nil or empty and returns a boolean value.
+func (a *pluginCleanupAction) IsNil() bool {
+	return a.Plugin == "" && a.step == nil
+}
+
+// Validate checks that the plugin to clean up with is known
+func (a *pluginCleanupAction) Validate(_ context.Context, _ TTPExecutionContext) error {
+	if a.Plugin == "" && a.step == nil {
+		return fmt.Errorf("plugin_cleanup field cannot be empty")
+	}
+	return nil
+}
+
+// Template resolves the fields of the cleanup action
+// from the step that it cleans up
+func (a *pluginCleanupAction) Template(_ context.Context, _ TTPExecutionContext) error {
+	a.resolve()
+	return nil
+}
+
+// Execute sends a cleanup request to the plugin
+func (a *pluginCleanupAction) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	a.resolve()
+	logging.L().Infof("Cleaning up with plugin %v", a.Plugin)
+	resp, err := callPlugin(ctx, execCtx, a.Plugin, execCtx.pluginRequest(plugins.MethodCleanup, a.Args, a.State))
+	if err != nil {
+		return nil, err
+	}
+	return pluginResult(execCtx, resp), nil
+}
+
+// pluginRequest assembles a request to a plugin
+// from the current state of the TTP
+func (c TTPExecutionContext) pluginRequest(method plugins.Method, args map[string]any, state any) plugins.Request {
+	scope := c.scope()
+	req := plugins.Request{
+		ProtocolVersion: plugins.ProtocolVersion,
+		Method:          method,
+		Args:            args,
+		Vars: plugins.Vars{
+			WorkDir:  scope.WorkDir,
+			Args:     scope.Args,
+			StepVars: scope.StepVars,
+		},
+		Steps: make(map[string]plugins.StepResult, len(scope.Steps)),
+		State: state,
+	}
+	if c.Vars != nil {
+		req.Vars.Env = c.Vars.Env
+	}
+	for name, step := range scope.Steps {
+		req.Steps[name] = plugins.StepResult{
+			Stdout:   step.Stdout,
+			Stderr:   step.Stderr,
+			ExitCode: step.ExitCode,
+			Outputs:  step.Outputs,
+			Skipped:  step.Skipped,
+		}
+	}
+	return req
+}
+
+// callPlugin runs the named plugin with the given request and
+// returns its response. The stderr of the plugin is streamed
+// like the output of any other command.
+func callPlugin(ctx context.Context, execCtx TTPExecutionContext, name string, req plugins.Request) (*plugins.Response, error) {
+	plugin, err := plugins.Find(execCtx.Cfg.PluginPaths, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdin, stdout bytes.Buffer
+	if err := plugins.EncodeRequest(&stdin, req); err != nil {
+		return nil, fmt.Errorf("failed to encode request for plugin %v: %w", name, err)
+	}
+	// @lint-ignore G204
+	cmd := exec.CommandContext(ctx, plugin.Path)
+	cmd.Env = execCtx.commandEnv(nil)
+	if execCtx.Vars != nil {
+		cmd.Dir = execCtx.Vars.WorkDir
+	}
+	cmd.Stdin = &stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = outputWriter(execCtx.Cfg.Stderr, "[STDERR] ")
+
+	runErr := runTrackedProcess(cmd)
+	resp, decodeErr := plugins.DecodeResponse(&stdout)
+	switch {
+	case decodeErr == nil && resp.Error != "":
+		return nil, fmt.Errorf("plugin %v failed to %v: %v", name, req.Method, resp.Error)
+	case runErr != nil:
+		return nil, fmt.Errorf("plugin %v failed to %v: %w", name, req.Method, runErr)
+	case decodeErr != nil:
+		return nil, fmt.Errorf("plugin %v: %w", name, decodeErr)
+	}
+	return resp, nil
+}
+
+// pluginResult converts the response of a plugin into the result
+// of the action, printing the output that the plugin reported
+func pluginResult(execCtx TTPExecutionContext, resp *plugins.Response) *ActResult {
+	if resp.Stdout != "" {
+		fmt.Fprint(outputWriter(execCtx.Cfg.Stdout, "[STDOUT] "), ensureTrailingNewline(resp.Stdout))
+	}
+	if resp.Stderr != "" {
+		fmt.Fprint(outputWriter(execCtx.Cfg.Stderr, "[STDERR] "), ensureTrailingNewline(resp.Stderr))
+	}
+	return &ActResult{
+		Stdout:   resp.Stdout,
+		Stderr:   resp.Stderr,
+		ExitCode: resp.ExitCode,
+		Outputs:  resp.Outputs,
+	}
+}
+
+func ensureTrailingNewline(s string) string {
+	if len(s) > 0 && s[len(s)-1] != '\n' {
+		return s + "\n"
+	}
+	return s
+}
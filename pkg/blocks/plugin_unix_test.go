@@ -0,0 +1,295 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/plugins"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestPlugin creates a plugin that records the requests
+// that it receives in logDir and answers with canned responses
+func writeTestPlugin(t *testing.T, pluginDir, name, logDir string) {
+	script := fmt.Sprintf(`#!/bin/sh
+req=$(cat)
+case "$req" in
+*'"method":"validate"'*)
+  case "$req" in
+  *'"domain"'*) echo '{}' ;;
+  *) echo '{"error":"domain is required"}'; exit 1 ;;
+  esac ;;
+*'"method":"execute"'*)
+  printf '%%s\n' "$req" > %[1]q/execute.json
+  echo "plugin diagnostics" >&2
+  echo '{"stdout":"joined domain","outputs":{"machine":"ws01"},"state":{"account":"ws01$"}}' ;;
+*'"method":"cleanup"'*)
+  printf '%%s\n' "$req" > %[1]q/cleanup.json
+  echo '{"stdout":"left domain"}' ;;
+esac
+`, logDir)
+	path := filepath.Join(pluginDir, plugins.ExecutablePrefix+name)
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+}
+
+func TestPluginAction(t *testing.T) {
+	pluginDir := t.TempDir()
+	logDir := t.TempDir()
+	writeTestPlugin(t, pluginDir, "ad", logDir)
+
+	testCases := []struct {
+		name                  string
+		content               string
+		expectValidateError   string
+		expectedStdout        string
+		expectedExecuteFields []string
+		expectedCleanupFields []string
+	}{
+		{
+			name: "Execute and Default Cleanup",
+			content: `name: plugin_test
+steps:
+  - name: domain
+    inline: echo corp.example.com
+  - name: join
+    plugin: ad
+    args:
+      domain: "{[{ trim .Steps.domain.Stdout }]}"
+      groups: [admins, "{[{ \"users\" | upper }]}"]
+    cleanup: default
+  - name: show
+    print_str: "machine is {[{ output \"join\" \"machine\" }]}"`,
+			expectedStdout: "corp.example.com\njoined domain\nmachine is ws01\nleft domain\n",
+			expectedExecuteFields: []string{
+				`"method":"execute"`,
+				`"args":{"domain":"corp.example.com","groups":["admins","USERS"]}`,
+				`"steps":{"domain":{"stdout":"corp.example.com\n","exit_code":0}}`,
+			},
+			expectedCleanupFields: []string{
+				`"method":"cleanup"`,
+				`"args":{"domain":"corp.example.com","groups":["admins","USERS"]}`,
+				`"state":{"account":"ws01$"}`,
+			},
+		},
+		{
+			name: "Plugin Rejects Arguments",
+			content: `name: plugin_test
+steps:
+  - name: join
+    plugin: ad
+    args:
+      server: dc01`,
+			expectValidateError: "plugin ad failed to validate: domain is required",
+		},
+		{
+			name: "Plugin Not Found",
+			content: `name: plugin_test
+steps:
+  - name: join
+    plugin: dns`,
+			expectValidateError: `plugin "dns" not found`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdout, stderr bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.PluginPaths = []string{pluginDir}
+			execCtx.Cfg.Stdout = &stdout
+			execCtx.Cfg.Stderr = &stderr
+			err = ttp.Validate(execCtx)
+			if tc.expectValidateError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectValidateError)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, ttp.Execute(execCtx))
+			require.NoError(t, ttp.RunCleanup(execCtx))
+			assert.Equal(t, tc.expectedStdout, stdout.String())
+			assert.Equal(t, "plugin diagnostics\n", stderr.String())
+
+			executeReq, err := os.ReadFile(filepath.Join(logDir, "execute.json"))
+			require.NoError(t, err)
+			for _, field := range tc.expectedExecuteFields {
+				assert.Contains(t, string(executeReq), field)
+			}
+			cleanupReq, err := os.ReadFile(filepath.Join(logDir, "cleanup.json"))
+			require.NoError(t, err)
+			for _, field := range tc.expectedCleanupFields {
+				assert.Contains(t, string(cleanupReq), field)
+			}
+		})
+	}
+}
+
+func TestPluginExitCode(t *testing.T) {
+	pluginDir := t.TempDir()
+	script := `#!/bin/sh
+echo '{"stdout":"partial","exit_code":3,"warnings":["unknown fields are ignored"]}'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, plugins.ExecutablePrefix+"partial"), []byte(script), 0755))
+
+	testCases := []struct {
+		name               string
+		expectExitCode     string
+		expectErrorMessage string
+	}{
+		{
+			name: "Non-Zero Exit Code Succeeds",
+		},
+		{
+			name:           "Expected Exit Code",
+			expectExitCode: "\n    expect_exit_code: 3",
+		},
+		{
+			name:               "Unexpected Exit Code",
+			expectExitCode:     "\n    expect_exit_code: 0",
+			expectErrorMessage: `step "partial" exited with code 3 but expected one of [0]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := `name: plugin_test
+steps:
+  - name: partial
+    plugin: partial` + tc.expectExitCode
+			ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+			require.NoError(t, err)
+
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.PluginPaths = []string{pluginDir}
+			execCtx.Cfg.Stdout = &bytes.Buffer{}
+			require.NoError(t, ttp.Validate(execCtx))
+			err = ttp.Execute(execCtx)
+			if tc.expectErrorMessage != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErrorMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 3, execCtx.StepResults.ByName["partial"].ExitCode)
+		})
+	}
+}
+
+func TestPluginValidationInDryRun(t *testing.T) {
+	pluginDir := t.TempDir()
+	writeTestPlugin(t, pluginDir, "ad", t.TempDir())
+
+	testCases := []struct {
+		name                string
+		plugin              string
+		expectValidateError string
+	}{
+		{
+			// the plugin would reject the arguments, but it is not run
+			name:   "Plugin Is Not Run",
+			plugin: "ad",
+		},
+		{
+			name:                "Plugin Not Found",
+			plugin:              "dns",
+			expectValidateError: `plugin "dns" not found`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := `name: plugin_test
+steps:
+  - name: join
+    plugin: ` + tc.plugin + `
+    args:
+      server: dc01`
+			ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+			require.NoError(t, err)
+
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.PluginPaths = []string{pluginDir}
+			execCtx.Cfg.DryRun = true
+			err = ttp.Validate(execCtx)
+			if tc.expectValidateError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectValidateError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPluginCleanupIsRecorded(t *testing.T) {
+	pluginDir := t.TempDir()
+	logDir := t.TempDir()
+	writeTestPlugin(t, pluginDir, "ad", logDir)
+
+	content := `name: plugin_test
+steps:
+  - name: join
+    plugin: ad
+    args:
+      domain: corp.example.com
+    cleanup: default`
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	execCtx := NewTTPExecutionContext()
+	execCtx.Cfg.PluginPaths = []string{pluginDir}
+	execCtx.Cfg.Stdout = &bytes.Buffer{}
+	execCtx.Cfg.Stderr = &bytes.Buffer{}
+	require.NoError(t, ttp.Validate(execCtx))
+	require.NoError(t, ttp.Execute(execCtx))
+
+	// the state file holds everything needed to clean up later
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := NewRunState(ttp, execCtx)
+	require.NoError(t, err)
+	require.NoError(t, state.WriteFile(statePath))
+	state, err = ReadRunState(statePath)
+	require.NoError(t, err)
+	require.Len(t, state.Steps, 1)
+	assert.Equal(t, []map[string]any{{
+		"plugin_cleanup": "ad",
+		"args":           map[string]any{"domain": "corp.example.com"},
+		"state":          map[string]any{"account": "ws01$"},
+	}}, state.Steps[0].Cleanup)
+
+	cleanupCtx := NewTTPExecutionContext()
+	cleanupCtx.Cfg = execCtx.Cfg
+	require.NoError(t, state.RunCleanup(cleanupCtx))
+	cleanupReq, err := os.ReadFile(filepath.Join(logDir, "cleanup.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(cleanupReq), `"state":{"account":"ws01$"}`)
+}
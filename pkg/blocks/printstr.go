@@ -21,6 +21,7 @@ package blocks
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -48,7 +49,7 @@ func (s *PrintStrAction) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies
-func (s *PrintStrAction) Validate(_ TTPExecutionContext) error {
+func (s *PrintStrAction) Validate(_ context.Context, _ TTPExecutionContext) error {
 	if s.Message == "" {
 		return fmt.Errorf("message field cannot be empty")
 	}
@@ -60,9 +61,9 @@ func (s *PrintStrAction) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *PrintStrAction) Template(execCtx TTPExecutionContext) error {
+func (s *PrintStrAction) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
-	s.Message, err = execCtx.templateStep(s.Message)
+	s.Message, err = execCtx.templateLegacyStep(s.Message)
 	if err != nil {
 		return err
 	}
@@ -70,19 +71,15 @@ func (s *PrintStrAction) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (s *PrintStrAction) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
+func (s *PrintStrAction) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	// needs to be overwritable to capture output during testing
 	stdout := execCtx.Cfg.Stdout
 	if stdout == nil {
 		stdout = os.Stdout
 	}
-	expandedStrs, err := execCtx.ExpandVariables([]string{s.Message})
-	if err != nil {
-		return nil, err
-	}
 	var stdoutBuf bytes.Buffer
 	multi := io.MultiWriter(stdout, &stdoutBuf)
-	fmt.Fprintln(multi, expandedStrs[0])
+	fmt.Fprintln(multi, s.Message)
 	result := &ActResult{
 		Stdout: stdoutBuf.String(),
 	}
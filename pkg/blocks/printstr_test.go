@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -67,8 +68,9 @@ func TestPrintStrExecute(t *testing.T) {
 				},
 			}
 
-			// execute and check error
-			result, err := tc.action.Execute(execCtx)
+			// template, execute and check error
+			require.NoError(t, tc.action.Template(context.Background(), execCtx))
+			result, err := tc.action.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				require.Error(t, err)
 				return
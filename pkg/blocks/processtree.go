@@ -0,0 +1,128 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+)
+
+// shutdownGracePeriod is how long processes are given to exit
+// after a shutdown signal is forwarded to them before they are killed
+var shutdownGracePeriod = 5 * time.Second
+
+// processWaitDelay bounds how long we wait for the output of a
+// command to be closed after the command has been killed
+var processWaitDelay = shutdownGracePeriod + 5*time.Second
+
+// trackedProcesses records the commands that are currently running
+// so that shutdown signals can be forwarded to them. The value
+// records whether a signal has already been forwarded.
+var trackedProcesses = struct {
+	sync.Mutex
+	signaledByPid map[int]bool
+}{
+	signaledByPid: make(map[int]bool),
+}
+
+// runTrackedProcess runs the command to completion. The command runs
+// in its own process tree which is killed if the command's context is done
+// or signaled if TTPForge receives a shutdown signal.
+func runTrackedProcess(cmd *exec.Cmd) error {
+	if err := startTrackedProcess(cmd); err != nil {
+		return err
+	}
+	defer untrackProcess(cmd)
+	return cmd.Wait()
+}
+
+// startTrackedProcess starts the command as described in runTrackedProcess -
+// the caller must call untrackProcess once the command has exited.
+func startTrackedProcess(cmd *exec.Cmd) error {
+	configureProcessTree(cmd)
+	cmd.Cancel = func() error {
+		trackedProcesses.Lock()
+		signaled := trackedProcesses.signaledByPid[cmd.Process.Pid]
+		trackedProcesses.Unlock()
+		// processes that received a shutdown signal are
+		// given the grace period to exit before they are killed
+		if signaled {
+			return nil
+		}
+		return killProcessTree(cmd.Process.Pid)
+	}
+	cmd.WaitDelay = processWaitDelay
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	trackedProcesses.Lock()
+	trackedProcesses.signaledByPid[cmd.Process.Pid] = false
+	trackedProcesses.Unlock()
+	return nil
+}
+
+// untrackProcess stops tracking a command that has exited
+func untrackProcess(cmd *exec.Cmd) {
+	trackedProcesses.Lock()
+	delete(trackedProcesses.signaledByPid, cmd.Process.Pid)
+	trackedProcesses.Unlock()
+}
+
+// forwardSignal sends the signal to the process trees of all running
+// commands and kills any of them that are still running
+// once the grace period has elapsed
+func forwardSignal(sig os.Signal) {
+	trackedProcesses.Lock()
+	var pids []int
+	for pid := range trackedProcesses.signaledByPid {
+		trackedProcesses.signaledByPid[pid] = true
+		pids = append(pids, pid)
+	}
+	trackedProcesses.Unlock()
+
+	for _, pid := range pids {
+		logging.L().Infof("Forwarding signal %v to process %d", sig, pid)
+		if err := signalProcessTree(pid, sig); err != nil {
+			logging.L().Warnf("Failed to forward signal %v to process %d: %v", sig, pid, err)
+		}
+	}
+	if len(pids) == 0 {
+		return
+	}
+
+	time.AfterFunc(shutdownGracePeriod, func() {
+		for _, pid := range pids {
+			trackedProcesses.Lock()
+			_, stillRunning := trackedProcesses.signaledByPid[pid]
+			trackedProcesses.Unlock()
+			if !stillRunning {
+				continue
+			}
+			logging.L().Warnf("Process %d did not exit within %v - killing it", pid, shutdownGracePeriod)
+			if err := killProcessTree(pid); err != nil {
+				logging.L().Warnf("Failed to kill process %d: %v", pid, err)
+			}
+		}
+	})
+}
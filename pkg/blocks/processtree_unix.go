@@ -0,0 +1,53 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessTree starts the command in its own process group
+// so that the command and all of its child processes
+// can be signaled or killed together
+func configureProcessTree(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// signalProcessTree sends the signal to the process group led by pid
+func signalProcessTree(pid int, sig os.Signal) error {
+	unixSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return killProcessTree(pid)
+	}
+	return syscall.Kill(-pid, unixSig)
+}
+
+// killProcessTree kills the process group led by pid
+func killProcessTree(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
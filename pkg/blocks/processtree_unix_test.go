@@ -0,0 +1,206 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signalOnceProcessStarts sends SIGTERM to the test process as soon as
+// a step process is running - the signal handler forwards it to that process
+func signalOnceProcessStarts(t *testing.T) {
+	go func() {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			trackedProcesses.Lock()
+			running := len(trackedProcesses.signaledByPid) > 0
+			trackedProcesses.Unlock()
+			if running {
+				// give the shell time to install its traps
+				time.Sleep(200 * time.Millisecond)
+				assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Error("no step process was started")
+	}()
+}
+
+func TestSignalForwarding(t *testing.T) {
+	origGracePeriod := shutdownGracePeriod
+	shutdownGracePeriod = 500 * time.Millisecond
+	defer func() { shutdownGracePeriod = origGracePeriod }()
+
+	testCases := []struct {
+		name           string
+		content        string
+		signalCleanup  bool
+		expectedStdout string
+		expectedError  string
+		// an interrupted cleanup is reported so that it can be retried
+		expectedCleanupError string
+	}{
+		{
+			name: "Signal is forwarded to the running step",
+			content: `name: signal_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: waits
+    inline: |
+      trap 'echo got TERM; exit 1' TERM
+      sleep 30 &
+      wait
+  - name: never
+    inline: echo never`,
+			expectedStdout: "first\ngot TERM\ncleanup first\n",
+			expectedError:  "Shutting Down",
+		},
+		{
+			name: "Step ignoring the signal is killed after the grace period",
+			content: `name: signal_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: stubborn
+    inline: |
+      trap '' TERM
+      sleep 30`,
+			expectedStdout: "first\ncleanup first\n",
+			expectedError:  "Shutting Down",
+		},
+		{
+			name: "Signal during cleanup skips only the current cleanup step",
+			content: `name: signal_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: second
+    inline: echo second
+    cleanup:
+      inline: sleep 30 && echo cleanup second`,
+			signalCleanup:        true,
+			expectedStdout:       "first\nsecond\ncleanup first\n",
+			expectedCleanupError: `cleanup of step "second" was interrupted by a signal`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetShutdownSignals()
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			require.NoError(t, ttp.Validate(execCtx))
+
+			start := time.Now()
+			if !tc.signalCleanup {
+				signalOnceProcessStarts(t)
+			}
+			err = ttp.Execute(execCtx)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.signalCleanup {
+				signalOnceProcessStarts(t)
+			}
+			err = ttp.RunCleanup(execCtx)
+			if tc.expectedCleanupError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedCleanupError)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+func TestSignalDuringNonProcessCleanup(t *testing.T) {
+	resetShutdownSignals()
+	// the request blocks until it is canceled - the
+	// test process is signaled as soon as it arrives
+	requestCanceled := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		select {
+		case <-r.Context().Done():
+			requestCanceled <- true
+		case <-time.After(10 * time.Second):
+			requestCanceled <- false
+		}
+	}))
+	defer server.Close()
+
+	content := `name: signal_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: second
+    inline: echo second
+    cleanup:
+      http_request: ` + server.URL + `
+      type: GET`
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+
+	var stdoutBuf bytes.Buffer
+	execCtx := NewTTPExecutionContext()
+	execCtx.Cfg.Stdout = &stdoutBuf
+	require.NoError(t, ttp.Validate(execCtx))
+	require.NoError(t, ttp.Execute(execCtx))
+
+	start := time.Now()
+	err = ttp.RunCleanup(execCtx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `cleanup of step "second" was interrupted by a signal`)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.True(t, <-requestCanceled)
+	assert.Equal(t, "first\nsecond\ncleanup first\n", stdoutBuf.String())
+}
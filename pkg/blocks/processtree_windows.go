@@ -0,0 +1,53 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// configureProcessTree is a no-op on Windows,
+// where process trees are killed with taskkill
+func configureProcessTree(_ *exec.Cmd) {}
+
+// signalProcessTree kills the process tree, as Windows
+// processes cannot receive SIGINT or SIGTERM
+func signalProcessTree(pid int, _ os.Signal) error {
+	return killProcessTree(pid)
+}
+
+// killProcessTree kills the process with the given pid and all of its children
+func killProcessTree(pid int) error {
+	// #nosec G204
+	taskkill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid))
+	if err := taskkill.Run(); err != nil {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		return process.Kill()
+	}
+	return nil
+}
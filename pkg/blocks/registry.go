@@ -0,0 +1,163 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var actionRegistry = struct {
+	sync.RWMutex
+	factories map[string]func() Action
+}{
+	factories: make(map[string]func() Action),
+}
+
+func init() {
+	RegisterAction("inline", func() Action { return NewBasicStep() })
+	RegisterAction("cd", func() Action { return NewChangeDirectoryStep() })
+	RegisterAction("file", func() Action { return NewFileStep() })
+	RegisterAction("ttp", func() Action { return NewSubTTPStep() })
+	RegisterAction("edit_file", func() Action { return NewEditStep() })
+	RegisterAction("fetch_uri", func() Action { return NewFetchURIStep() })
+	RegisterAction("create_file", func() Action { return NewCreateFileStep() })
+	RegisterAction("copy_path", func() Action { return NewCopyPathStep() })
+	RegisterAction("remove_path", func() Action { return NewRemovePathAction() })
+	RegisterAction("print_str", func() Action { return NewPrintStrAction() })
+	RegisterAction("expect", func() Action { return NewExpectStep() })
+	RegisterAction("http_request", func() Action { return NewHTTPRequestStep() })
+	RegisterAction("kill_process_id", func() Action { return NewKillProcessStep() })
+	RegisterAction("kill_process_name", func() Action { return NewKillProcessStep() })
+	RegisterAction("parallel", func() Action { return NewParallelStep() })
+	RegisterAction("plugin", func() Action { return NewPluginAction() })
+}
+
+// recordedActions holds the action types that only appear in the cleanup
+// recorded in state files (such as the default cleanup of plugin steps).
+// They are not registered, so they cannot be used in TTP files.
+var recordedActions = map[string]func() Action{
+	"plugin_cleanup": func() Action { return &pluginCleanupAction{} },
+}
+
+// RegisterAction makes an action type available to TTP steps.
+// A step uses the action type whose key appears among its YAML
+// fields (for example, `inline:` or `create_file:`) - the step is
+// then decoded into a new action created by calling factory.
+// An action type that can be identified by several keys
+// (such as kill_process) registers a factory for each of them.
+//
+// Custom action types are usually registered from the init function
+// of the package that implements them, so that they are available in
+// any TTPForge build that imports that package. RegisterAction panics
+// if the key is empty or already registered.
+//
+// **Parameters:**
+//
+// key: the YAML field that identifies the action type
+// factory: a function returning a new, empty instance of the action type
+func RegisterAction(key string, factory func() Action) {
+	if key == "" {
+		panic("blocks: RegisterAction called with an empty key")
+	}
+	if factory == nil {
+		panic(fmt.Sprintf("blocks: RegisterAction called with a nil factory for %q", key))
+	}
+	actionRegistry.Lock()
+	defer actionRegistry.Unlock()
+	if _, exists := actionRegistry.factories[key]; exists {
+		panic(fmt.Sprintf("blocks: RegisterAction called twice for %q", key))
+	}
+	actionRegistry.factories[key] = factory
+}
+
+// RegisteredActionKeys returns the keys of all registered action types
+// in sorted order
+func RegisteredActionKeys() []string {
+	actionRegistry.RLock()
+	defer actionRegistry.RUnlock()
+	return slices.Sorted(maps.Keys(actionRegistry.factories))
+}
+
+func lookupAction(key string) (func() Action, bool) {
+	actionRegistry.RLock()
+	defer actionRegistry.RUnlock()
+	factory, ok := actionRegistry.factories[key]
+	return factory, ok
+}
+
+// registeredActionKey returns the key under which the type of the given
+// action is registered, for actions that were not parsed from YAML (such as
+// default cleanup actions). If several keys are registered for the type,
+// the first one in sorted order is returned.
+func registeredActionKey(action Action) string {
+	for _, key := range RegisteredActionKeys() {
+		factory, _ := lookupAction(key)
+		if reflect.TypeOf(factory()) == reflect.TypeOf(action) {
+			return key
+		}
+	}
+	for key, factory := range recordedActions {
+		if reflect.TypeOf(factory()) == reflect.TypeOf(action) {
+			return key
+		}
+	}
+	return fmt.Sprintf("%T", action)
+}
+
+// newActionForNode creates an empty action of the type identified by
+// the keys of the given YAML mapping. It returns the (first) key that
+// identified the action type along with the action.
+func newActionForNode(node *yaml.Node) (string, Action, error) {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return "", nil, fmt.Errorf("expected a YAML mapping but got %v", node.Tag)
+	}
+
+	var actionKey string
+	var action Action
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key := node.Content[idx].Value
+		factory, ok := lookupAction(key)
+		if !ok {
+			continue
+		}
+		candidate := factory()
+		if action != nil && reflect.TypeOf(candidate) != reflect.TypeOf(action) {
+			return "", nil, fmt.Errorf("ambiguous action type: both `%v:` and `%v:` were specified", actionKey, key)
+		}
+		if action == nil {
+			actionKey = key
+			action = candidate
+		}
+	}
+	if action == nil {
+		return "", nil, fmt.Errorf("no action type was specified - expected one of: %v", strings.Join(RegisteredActionKeys(), ", "))
+	}
+	return actionKey, action, nil
+}
@@ -0,0 +1,154 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// registryTestAction is a minimal custom action
+// used to exercise RegisterAction
+type registryTestAction struct {
+	actionDefaults `yaml:",inline"`
+	Greeting       string `yaml:"registry_test_greet,omitempty"`
+}
+
+func (a *registryTestAction) IsNil() bool {
+	return a.Greeting == ""
+}
+
+func (a *registryTestAction) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
+	return nil
+}
+
+func (a *registryTestAction) Template(ctx context.Context, execCtx TTPExecutionContext) error {
+	return nil
+}
+
+func (a *registryTestAction) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	return &ActResult{Stdout: "hello " + a.Greeting}, nil
+}
+
+func init() {
+	RegisterAction("registry_test_greet", func() Action { return &registryTestAction{} })
+}
+
+func TestParseActionWithRegistry(t *testing.T) {
+	testCases := []struct {
+		name               string
+		content            string
+		expectedType       Action
+		expectErrorMessage string
+	}{
+		{
+			name: "Built-in Action",
+			content: `name: builtin
+print_str: hello`,
+			expectedType: &PrintStrAction{},
+		},
+		{
+			name: "Custom Action",
+			content: `name: custom
+description: a registered action
+registry_test_greet: world`,
+			expectedType: &registryTestAction{},
+		},
+		{
+			name: "Several Keys for the Same Action Type",
+			content: `name: kill
+kill_process_id: 1234
+kill_process_name: foo`,
+			expectedType: &KillProcessStep{},
+		},
+		{
+			name: "Ambiguous Action Type",
+			content: `name: ambiguous
+inline: echo hello
+registry_test_greet: world`,
+			expectErrorMessage: "could not parse action for step \"ambiguous\": ambiguous action type: both `inline:` and `registry_test_greet:` were specified",
+		},
+		{
+			name: "Unknown Action Type",
+			content: `name: unknown
+not_an_action: foo`,
+			expectErrorMessage: "could not parse action for step \"unknown\": no action type was specified - expected one of:",
+		},
+		{
+			name: "Action Type Only Used in State Files",
+			content: `name: recorded
+plugin_cleanup: ad`,
+			expectErrorMessage: "could not parse action for step \"recorded\": no action type was specified - expected one of:",
+		},
+		{
+			name: "Cleanup Type Only Used in State Files",
+			content: `name: recorded
+inline: echo hello
+cleanup:
+  plugin_cleanup: ad`,
+			expectErrorMessage: "could not parse cleanup action for step \"recorded\": no action type was specified - expected one of:",
+		},
+		{
+			name: "Empty Action",
+			content: `name: empty
+registry_test_greet: ""`,
+			expectErrorMessage: "could not parse action for step \"empty\": the `registry_test_greet:` action is empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tc.content), &node))
+
+			var step Step
+			err := node.Decode(&step)
+			if tc.expectErrorMessage != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErrorMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tc.expectedType, step.action)
+		})
+	}
+}
+
+func TestRegisterAction(t *testing.T) {
+	keys := RegisteredActionKeys()
+	assert.Contains(t, keys, "inline")
+	assert.Contains(t, keys, "registry_test_greet")
+	assert.IsIncreasing(t, keys)
+	assert.NotContains(t, keys, "plugin_cleanup")
+
+	assert.Panics(t, func() {
+		RegisterAction("inline", func() Action { return NewBasicStep() })
+	}, "registering a key twice should panic")
+	assert.Panics(t, func() {
+		RegisterAction("", func() Action { return NewBasicStep() })
+	}, "registering an empty key should panic")
+	assert.Panics(t, func() {
+		RegisterAction("registry_test_nil", nil)
+	}, "registering a nil factory should panic")
+}
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/facebookincubator/ttpforge/pkg/fileutils"
@@ -54,7 +55,7 @@ func (s *RemovePathAction) IsNil() bool {
 }
 
 // Validate validates the step, checking for the necessary attributes and dependencies
-func (s *RemovePathAction) Validate(_ TTPExecutionContext) error {
+func (s *RemovePathAction) Validate(_ context.Context, _ TTPExecutionContext) error {
 	if s.Path == "" {
 		return fmt.Errorf("path field cannot be empty")
 	}
@@ -66,7 +67,7 @@ func (s *RemovePathAction) Validate(_ TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *RemovePathAction) Template(execCtx TTPExecutionContext) error {
+func (s *RemovePathAction) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 	s.Path, err = execCtx.templateStep(s.Path)
 	if err != nil {
@@ -76,18 +77,23 @@ func (s *RemovePathAction) Template(execCtx TTPExecutionContext) error {
 }
 
 // Execute runs the step and returns an error if one occurs.
-func (s *RemovePathAction) Execute(_ TTPExecutionContext) (*ActResult, error) {
+func (s *RemovePathAction) Execute(_ context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	logging.L().Infof("Removing path %v", s.Path)
+	pathToRemove, err := fileutils.ExpandTilde(s.Path)
+	if err != nil {
+		return nil, err
+	}
 	fsys := s.FileSystem
 	if fsys == nil {
 		fsys = afero.NewOsFs()
+		// relative paths are relative to the working directory of the TTP
+		pathToRemove, err = FetchAbs(pathToRemove, execCtx.Vars.WorkDir)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// cannot remove a non-existent path
-	pathToRemove, err := fileutils.ExpandTilde(s.Path)
-	if err != nil {
-		return nil, err
-	}
 	exists, err := afero.Exists(fsys, pathToRemove)
 	if err != nil {
 		return nil, err
@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -140,7 +141,7 @@ func TestRemovePathExecute(t *testing.T) {
 			execCtx.Vars.StepVars = tc.stepVars
 
 			// validate
-			err := tc.step.Validate(execCtx)
+			err := tc.step.Validate(context.Background(), execCtx)
 			if tc.expectValidateError {
 				require.Error(t, err)
 				return
@@ -148,7 +149,7 @@ func TestRemovePathExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// template
-			err = tc.step.Template(execCtx)
+			err = tc.step.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				require.Error(t, err)
 				return
@@ -156,7 +157,7 @@ func TestRemovePathExecute(t *testing.T) {
 			require.NoError(t, err)
 
 			// execute
-			_, err = tc.step.Execute(execCtx)
+			_, err = tc.step.Execute(context.Background(), execCtx)
 			if tc.expectExecuteError {
 				require.Error(t, err)
 				return
@@ -0,0 +1,200 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+)
+
+// ReportFormatVersion is the version of the JSON execution report format.
+// It must be incremented whenever a backward-incompatible change is
+// made to the structure of the report.
+const ReportFormatVersion = 1
+
+// Report is a machine-readable record of a single TTP execution.
+// It is produced by `ttpforge run --report` so that the results
+// of a run can be consumed by other tooling.
+type Report struct {
+	FormatVersion int               `json:"format_version"`
+	TTP           ReportTTPInfo     `json:"ttp"`
+	Args          map[string]any    `json:"args"`
+	StartTime     time.Time         `json:"start_time"`
+	EndTime       time.Time         `json:"end_time"`
+	DurationMs    int64             `json:"duration_ms"`
+	Success       bool              `json:"success"`
+	Error         string            `json:"error,omitempty"`
+	Outputs       map[string]string `json:"outputs,omitempty"`
+	Steps         []ReportStep      `json:"steps"`
+}
+
+// ReportTTPInfo identifies the TTP that was executed
+type ReportTTPInfo struct {
+	UUID        string       `json:"uuid,omitempty"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Path        string       `json:"path,omitempty"`
+	Mitre       *MitreAttack `json:"mitre,omitempty"`
+}
+
+// ReportStep contains the results of an individual step
+// and of its cleanup action (if cleanup was run)
+type ReportStep struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Failed  bool   `json:"failed,omitempty"`
+	Error   string `json:"error,omitempty"`
+	ReportActionResult
+	Attempts   int                  `json:"attempts,omitempty"`
+	Iterations []ReportActionResult `json:"iterations,omitempty"`
+	Children   []ReportStep         `json:"children,omitempty"`
+	Cleanup    *ReportActionResult  `json:"cleanup,omitempty"`
+}
+
+// ReportActionResult is the serialized form of an ActResult
+type ReportActionResult struct {
+	StartTime  time.Time         `json:"start_time"`
+	EndTime    time.Time         `json:"end_time"`
+	DurationMs int64             `json:"duration_ms"`
+	Stdout     string            `json:"stdout"`
+	Stderr     string            `json:"stderr"`
+	ExitCode   int               `json:"exit_code"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+}
+
+// NewReport assembles a Report from the results stored
+// in the provided execution context.
+//
+// **Parameters:**
+//
+// ttp: the TTP that was executed
+// execCtx: the execution context that was used to run the TTP
+// startTime: when the TTP started executing
+// endTime: when the TTP (including cleanup) finished executing
+// runErr: the error returned by the TTP execution, if any
+//
+// **Returns:**
+//
+// *Report: the assembled report
+func NewReport(ttp *TTP, execCtx TTPExecutionContext, startTime, endTime time.Time, runErr error) *Report {
+	report := &Report{
+		FormatVersion: ReportFormatVersion,
+		TTP: ReportTTPInfo{
+			UUID:        ttp.UUID,
+			Name:        ttp.Name,
+			Description: ttp.Description,
+			Path:        ttp.FilePath,
+			Mitre:       ttp.MitreAttackMapping,
+		},
+		Args:       map[string]any{},
+		StartTime:  startTime,
+		EndTime:    endTime,
+		DurationMs: endTime.Sub(startTime).Milliseconds(),
+		Success:    runErr == nil,
+		Steps:      []ReportStep{},
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	if execCtx.Vars != nil && execCtx.Vars.Args != nil {
+		report.Args = execCtx.Vars.Args
+	}
+	if execCtx.StepResults == nil {
+		return report
+	}
+	if runErr == nil {
+		outputs, err := ttp.ResolveOutputs(execCtx)
+		if err != nil {
+			logging.L().Warnf("Could not resolve TTP outputs for report: %v", err)
+		}
+		report.Outputs = outputs
+	}
+	for idx, result := range execCtx.StepResults.ByIndex {
+		name := result.Name
+		if idx < len(ttp.Steps) {
+			name = ttp.Steps[idx].Name
+		}
+		report.Steps = append(report.Steps, newReportStep(idx, name, result))
+	}
+	return report
+}
+
+func newReportStep(idx int, name string, result *ExecutionResult) ReportStep {
+	step := ReportStep{
+		Index:              idx,
+		Name:               name,
+		Skipped:            result.Skipped,
+		Failed:             result.Failed,
+		ReportActionResult: newReportActionResult(&result.ActResult),
+		Attempts:           result.Attempts,
+	}
+	if result.Error != nil {
+		step.Error = result.Error.Error()
+	}
+	for _, iteration := range result.Iterations {
+		step.Iterations = append(step.Iterations, newReportActionResult(iteration))
+	}
+	for childIdx, child := range result.Children {
+		step.Children = append(step.Children, newReportStep(childIdx, child.Name, child))
+	}
+	if result.Cleanup != nil {
+		cleanup := newReportActionResult(result.Cleanup)
+		step.Cleanup = &cleanup
+	}
+	return step
+}
+
+func newReportActionResult(result *ActResult) ReportActionResult {
+	return ReportActionResult{
+		StartTime:  result.StartTime,
+		EndTime:    result.EndTime,
+		DurationMs: result.Duration().Milliseconds(),
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		ExitCode:   result.ExitCode,
+		Outputs:    result.Outputs,
+	}
+}
+
+// WriteFile serializes the report as indented JSON
+// and writes it to the specified path.
+//
+// **Parameters:**
+//
+// path: the file to which the report should be written
+//
+// **Returns:**
+//
+// error: an error if the report could not be written
+func (r *Report) WriteFile(path string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize report: %w", err)
+	}
+	if err := os.WriteFile(path, append(reportBytes, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write report to %v: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReport(t *testing.T) {
+	content := `name: report_test
+uuid: 4b3b6f4e-3c1f-4bd5-9d2e-0c0c2a5f7d3a
+description: checks that reports contain step results
+mitre:
+  tactics:
+    - TA0002 Execution
+outputs:
+  foo: "{[{ .Steps.step1.Outputs.foo }]}"
+steps:
+  - name: step1
+    inline: echo {\"foo\":\"bar\"}
+    outputs:
+      foo:
+        filters:
+        - json_path: foo
+    cleanup:
+      inline: echo cleanup1
+  - name: step2
+    inline: echo step2`
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	execCtx := NewTTPExecutionContext()
+	execCtx.Vars.Args = map[string]any{"target": "example"}
+	require.NoError(t, ttp.Validate(execCtx))
+
+	startTime := time.Now()
+	require.NoError(t, ttp.Execute(execCtx))
+	require.NoError(t, ttp.RunCleanup(execCtx))
+	report := NewReport(ttp, execCtx, startTime, time.Now(), nil)
+
+	assert.Equal(t, ReportFormatVersion, report.FormatVersion)
+	assert.Equal(t, "report_test", report.TTP.Name)
+	assert.Equal(t, "4b3b6f4e-3c1f-4bd5-9d2e-0c0c2a5f7d3a", report.TTP.UUID)
+	require.NotNil(t, report.TTP.Mitre)
+	assert.Equal(t, []string{"TA0002 Execution"}, report.TTP.Mitre.Tactics)
+	assert.Equal(t, "example", report.Args["target"])
+	assert.True(t, report.Success)
+	assert.Empty(t, report.Error)
+	assert.Equal(t, map[string]string{"foo": "bar"}, report.Outputs)
+
+	require.Len(t, report.Steps, 2)
+	assert.Equal(t, "step1", report.Steps[0].Name)
+	assert.Equal(t, "{\"foo\":\"bar\"}\n", report.Steps[0].Stdout)
+	assert.Equal(t, "bar", report.Steps[0].Outputs["foo"])
+	assert.False(t, report.Steps[0].StartTime.IsZero())
+	assert.False(t, report.Steps[0].EndTime.Before(report.Steps[0].StartTime))
+	require.NotNil(t, report.Steps[0].Cleanup)
+	assert.Equal(t, "cleanup1\n", report.Steps[0].Cleanup.Stdout)
+	assert.Equal(t, "step2", report.Steps[1].Name)
+	require.NotNil(t, report.Steps[1].Cleanup)
+	assert.Empty(t, report.Steps[1].Cleanup.Stdout)
+}
+
+func TestReportFailedStep(t *testing.T) {
+	content := `name: report_failure_test
+steps:
+  - name: step1
+    inline: echo step1
+    cleanup:
+      inline: echo cleanup1
+  - name: fails
+    inline: |
+      echo partial output
+      echo something went wrong >&2
+      exit 3
+    cleanup:
+      inline: echo never
+  - name: never
+    inline: echo never`
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	execCtx := NewTTPExecutionContext()
+	require.NoError(t, ttp.Validate(execCtx))
+
+	startTime := time.Now()
+	runErr := ttp.Execute(execCtx)
+	require.Error(t, runErr)
+	require.NoError(t, ttp.RunCleanup(execCtx))
+	report := NewReport(ttp, execCtx, startTime, time.Now(), runErr)
+
+	assert.False(t, report.Success)
+	require.Len(t, report.Steps, 2)
+	assert.False(t, report.Steps[0].Failed)
+	require.NotNil(t, report.Steps[0].Cleanup)
+	assert.Equal(t, "cleanup1\n", report.Steps[0].Cleanup.Stdout)
+
+	// the output of the failed step is reported, but it is not cleaned up
+	failed := report.Steps[1]
+	assert.Equal(t, "fails", failed.Name)
+	assert.True(t, failed.Failed)
+	assert.NotEmpty(t, failed.Error)
+	assert.Equal(t, "partial output\n", failed.Stdout)
+	assert.Equal(t, "something went wrong\n", failed.Stderr)
+	assert.Equal(t, 3, failed.ExitCode)
+	assert.False(t, failed.StartTime.IsZero())
+	assert.Nil(t, failed.Cleanup)
+}
+
+func TestReportWriteFile(t *testing.T) {
+	report := NewReport(&TTP{
+		PreambleFields: PreambleFields{Name: "failed_ttp"},
+	}, NewTTPExecutionContext(), time.Now(), time.Now(), errors.New("step failed"))
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.WriteFile(reportPath))
+
+	contents, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(contents, &decoded))
+	assert.Equal(t, float64(ReportFormatVersion), decoded["format_version"])
+	assert.Equal(t, false, decoded["success"])
+	assert.Equal(t, "step failed", decoded["error"])
+	assert.Equal(t, []any{}, decoded["steps"])
+}
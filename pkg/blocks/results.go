@@ -19,20 +19,50 @@ THE SOFTWARE.
 
 package blocks
 
+import "time"
+
 // ActResult contains common fields produced
 // from both the execution of steps and their
-// associated cleanup actions
+// associated cleanup actions.
+// ExitCode is only meaningful for actions that run a command
+// and is -1 if the command did not exit on its own.
 type ActResult struct {
-	Stdout  string
-	Stderr  string
-	Outputs map[string]string
+	Stdout    string
+	Stderr    string
+	ExitCode  int
+	Outputs   map[string]string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Duration returns how long the action took to run
+func (r *ActResult) Duration() time.Duration {
+	if r.StartTime.IsZero() || r.EndTime.IsZero() {
+		return 0
+	}
+	return r.EndTime.Sub(r.StartTime)
 }
 
 // ExecutionResult stores the results/outputs
-// generated by executing a Step
+// generated by executing a Step.
+// Skipped is set if the step was not executed
+// because its `if:` condition evaluated to false.
+// Iterations holds the individual results of loop steps.
+// Children holds the results of the steps in a parallel group.
+// Attempts is the number of times the step was tried.
+// Error is set if the step failed but has `continue_on_error:` set.
+// Failed is set (along with Error) if the failure of the step
+// stopped the TTP - such steps are not cleaned up afterward.
 type ExecutionResult struct {
 	ActResult
-	Cleanup *ActResult
+	Name       string
+	Attempts   int
+	Error      error
+	Failed     bool
+	Cleanup    *ActResult
+	Skipped    bool
+	Iterations []*ActResult
+	Children   []*ExecutionResult
 }
 
 // StepResultsRecord provides convenient accessors
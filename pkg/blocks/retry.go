@@ -0,0 +1,204 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+)
+
+const (
+	// RetryOnError retries a step if its action returns an error
+	// (for example, if a command exits with a non-zero status)
+	RetryOnError = "error"
+	// RetryOnChecks retries a step if any of its success checks fail
+	RetryOnChecks = "checks"
+)
+
+// RetrySpec specifies how a failed step should be retried.
+//
+// Attempts: the total number of times to try the step (including the first try)
+// Delay: how long to wait before the first retry (such as `500ms` or `2s`)
+// Backoff: the factor by which the delay is multiplied after each retry
+// MaxDelay: an upper bound on the delay between retries
+// RetryOn: the conditions that trigger a retry (`error`, `checks`) -
+// if not specified, the step is retried under either condition
+type RetrySpec struct {
+	Attempts int      `yaml:"attempts,omitempty"`
+	Delay    string   `yaml:"delay,omitempty"`
+	Backoff  float64  `yaml:"backoff,omitempty"`
+	MaxDelay string   `yaml:"max_delay,omitempty"`
+	RetryOn  []string `yaml:"retry_on,omitempty"`
+}
+
+// Validate checks that the retry policy is well-formed
+func (r *RetrySpec) Validate() error {
+	if r.Attempts < 1 {
+		return errors.New("attempts must be at least 1")
+	}
+	if _, err := r.initialDelay(); err != nil {
+		return err
+	}
+	if _, err := r.maxDelay(); err != nil {
+		return err
+	}
+	if r.Backoff != 0 && r.Backoff < 1 {
+		return errors.New("backoff must be at least 1")
+	}
+	for _, condition := range r.RetryOn {
+		switch condition {
+		case RetryOnError, RetryOnChecks:
+		default:
+			return fmt.Errorf("invalid retry_on condition %q - must be %q or %q", condition, RetryOnError, RetryOnChecks)
+		}
+	}
+	return nil
+}
+
+func (r *RetrySpec) initialDelay() (time.Duration, error) {
+	if r.Delay == "" {
+		return 0, nil
+	}
+	delay, err := time.ParseDuration(r.Delay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid delay %q: %w", r.Delay, err)
+	}
+	if delay < 0 {
+		return 0, fmt.Errorf("delay %q cannot be negative", r.Delay)
+	}
+	return delay, nil
+}
+
+func (r *RetrySpec) maxDelay() (time.Duration, error) {
+	if r.MaxDelay == "" {
+		return 0, nil
+	}
+	maxDelay, err := time.ParseDuration(r.MaxDelay)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max_delay %q: %w", r.MaxDelay, err)
+	}
+	if maxDelay < 0 {
+		return 0, fmt.Errorf("max_delay %q cannot be negative", r.MaxDelay)
+	}
+	return maxDelay, nil
+}
+
+// nextDelay computes the delay that follows the provided one
+func (r *RetrySpec) nextDelay(delay time.Duration) time.Duration {
+	if r.Backoff > 1 {
+		delay = time.Duration(float64(delay) * r.Backoff)
+	}
+	if maxDelay, _ := r.maxDelay(); maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// retriesOn reports whether the given condition should trigger a retry
+func (r *RetrySpec) retriesOn(condition string) bool {
+	if len(r.RetryOn) == 0 {
+		return true
+	}
+	for _, c := range r.RetryOn {
+		if c == condition {
+			return true
+		}
+	}
+	return false
+}
+
+// retryPolicy returns the retry policy of the step (or nil if the step
+// should not be retried). For backward compatibility, the legacy
+// `retries` field of `fetch_uri` steps is honored if no `retry:` is given.
+func (s *Step) retryPolicy(execCtx TTPExecutionContext) (*RetrySpec, error) {
+	if s.Retry != nil {
+		return s.Retry, nil
+	}
+	fetchStep, ok := s.action.(*FetchURIStep)
+	if !ok || fetchStep.Retries == "" {
+		return nil, nil
+	}
+	retriesStr, err := execCtx.templateStep(fetchStep.Retries)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := strconv.Atoi(strings.TrimSpace(retriesStr))
+	if err != nil || retries < 0 {
+		return nil, fmt.Errorf("invalid retries value %q for step %q: must be a non-negative integer", retriesStr, s.Name)
+	}
+	return &RetrySpec{
+		Attempts: retries + 1,
+		RetryOn:  []string{RetryOnError},
+	}, nil
+}
+
+// runWithRetries runs the step, retrying it according to the
+// provided policy. Between attempts, any effects of the previous
+// attempt are cleaned up and a fresh copy of the step's actions is created.
+//
+// If checks still fail after the final attempt, the result is returned
+// without an error so that the caller records it before verifying the checks.
+func (s *Step) runWithRetries(ctx context.Context, execCtx TTPExecutionContext, policy *RetrySpec) (*ActResult, error) {
+	delay, err := policy.initialDelay()
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		s.attempts = attempt
+		result, err := s.runOnce(ctx, execCtx)
+
+		var retryReason error
+		switch {
+		case err != nil:
+			if !policy.retriesOn(RetryOnError) {
+				return result, err
+			}
+			retryReason = err
+		case policy.retriesOn(RetryOnChecks):
+			retryReason = s.VerifyChecks()
+		}
+		if retryReason == nil || attempt >= policy.Attempts {
+			return result, err
+		}
+
+		logging.L().Warnf("Attempt %d/%d of step %q failed: %v - retrying in %v", attempt, policy.Attempts, s.Name, retryReason, delay)
+		if err == nil || s.ShouldCleanupOnFailure() {
+			if _, cleanupErr := s.cleanupAndNotify(ctx, execCtx); cleanupErr != nil {
+				logging.L().Errorf("Error cleaning up attempt %d of step %q: %v", attempt, s.Name, cleanupErr)
+			}
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("step %q was stopped while waiting to retry: %w", s.Name, ctx.Err())
+		}
+		delay = policy.nextDelay(delay)
+
+		if err := s.resetActions(ctx, execCtx); err != nil {
+			return nil, err
+		}
+	}
+}
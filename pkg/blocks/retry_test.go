@@ -0,0 +1,218 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetrySpecValidate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		spec      RetrySpec
+		wantError bool
+	}{
+		{
+			name: "Valid policy",
+			spec: RetrySpec{
+				Attempts: 3,
+				Delay:    "100ms",
+				Backoff:  2,
+				MaxDelay: "1s",
+				RetryOn:  []string{RetryOnError, RetryOnChecks},
+			},
+		},
+		{
+			name:      "Zero attempts",
+			spec:      RetrySpec{},
+			wantError: true,
+		},
+		{
+			name:      "Invalid delay",
+			spec:      RetrySpec{Attempts: 2, Delay: "soon"},
+			wantError: true,
+		},
+		{
+			name:      "Backoff less than one",
+			spec:      RetrySpec{Attempts: 2, Backoff: 0.5},
+			wantError: true,
+		},
+		{
+			name:      "Invalid retry_on condition",
+			spec:      RetrySpec{Attempts: 2, RetryOn: []string{"always"}},
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if tc.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRetrySpecNextDelay(t *testing.T) {
+	spec := RetrySpec{Attempts: 5, Delay: "1s", Backoff: 2, MaxDelay: "5s"}
+	require.NoError(t, spec.Validate())
+
+	delay, err := spec.initialDelay()
+	require.NoError(t, err)
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		delays = append(delays, delay)
+		delay = spec.nextDelay(delay)
+	}
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}, delays)
+}
+
+func TestRetrySteps(t *testing.T) {
+	// each attempt increments a counter stored in a file
+	// and the step succeeds once the counter reaches the target
+	const flakyCmd = `n=$(cat "%[1]s" 2>/dev/null || echo 0); n=$((n+1)); echo $n > "%[1]s"; echo "attempt $n"; [ $n -ge %[2]d ]`
+
+	testCases := []struct {
+		name             string
+		stepTemplate     string
+		succeedOn        int
+		expectedStdout   string
+		expectedAttempts int
+		wantExecuteError bool
+	}{
+		{
+			name: "Succeeds after retries",
+			stepTemplate: `    inline: '%s'
+    retry:
+      attempts: 3
+      delay: 10ms
+      backoff: 2`,
+			succeedOn:        3,
+			expectedStdout:   "attempt 1\nattempt 2\nattempt 3\n",
+			expectedAttempts: 3,
+		},
+		{
+			name: "Attempts exhausted",
+			stepTemplate: `    inline: '%s'
+    retry:
+      attempts: 2`,
+			succeedOn:        3,
+			expectedStdout:   "attempt 1\nattempt 2\n",
+			wantExecuteError: true,
+		},
+		{
+			name: "Errors are not retried when only checks are",
+			stepTemplate: `    inline: '%s'
+    retry:
+      attempts: 3
+      retry_on: [checks]`,
+			succeedOn:        3,
+			expectedStdout:   "attempt 1\n",
+			wantExecuteError: true,
+		},
+		{
+			name: "Failed checks are retried after cleanup",
+			stepTemplate: `    inline: '%s; [ $n -lt 2 ] || touch "MARKER"'
+    cleanup:
+      inline: echo cleanup
+    checks:
+      - msg: marker file should exist
+        path_exists: MARKER
+    retry:
+      attempts: 3
+      retry_on: [checks]`,
+			succeedOn:        1,
+			expectedStdout:   "attempt 1\ncleanup\nattempt 2\ncleanup\n",
+			expectedAttempts: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			counterPath := filepath.Join(tempDir, "counter")
+			markerPath := filepath.Join(tempDir, "marker")
+			step := fmt.Sprintf(tc.stepTemplate, fmt.Sprintf(flakyCmd, counterPath, tc.succeedOn))
+			step = strings.ReplaceAll(step, "MARKER", markerPath)
+			content := "name: retry_test\nsteps:\n  - name: flaky\n" + step
+
+			ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			require.NoError(t, ttp.Validate(execCtx))
+
+			err = ttp.Execute(execCtx)
+			if tc.wantExecuteError {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, ttp.RunCleanup(execCtx))
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+			assert.Equal(t, tc.expectedAttempts, execCtx.StepResults.ByName["flaky"].Attempts)
+		})
+	}
+}
+
+func TestFetchURIRetries(t *testing.T) {
+	// abort the first two requests to simulate a flaky network
+	var requests atomic.Int32
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			panic(http.ErrAbortHandler)
+		}
+		w.Write([]byte("Here's some data!"))
+	}))
+	defer testServer.Close()
+
+	location := filepath.Join(t.TempDir(), "fetched.txt")
+	content := fmt.Sprintf(`name: retry_test
+steps:
+  - name: fetch
+    fetch_uri: %s
+    location: %s
+    retries: 2`, testServer.URL, location)
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	execCtx := NewTTPExecutionContext()
+	require.NoError(t, ttp.Validate(execCtx))
+	require.NoError(t, ttp.Execute(execCtx))
+	assert.Equal(t, 3, execCtx.StepResults.ByName["fetch"].Attempts)
+	assert.FileExists(t, location)
+}
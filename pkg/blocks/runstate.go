@@ -0,0 +1,450 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"gopkg.in/yaml.v3"
+)
+
+// RunStateFormatVersion is the version of the run state file format.
+// It must be incremented whenever a backward-incompatible change is
+// made to the structure of the state file.
+const RunStateFormatVersion = 1
+
+// RunState records which steps of a TTP completed and what cleanup
+// each of them still requires. It is written by `ttpforge run --state-file`
+// after every step so that the TTP can be cleaned up later with
+// `ttpforge cleanup` - even if TTPForge crashed or was run with `--no-cleanup`.
+type RunState struct {
+	FormatVersion   int               `json:"format_version"`
+	TTPName         string            `json:"ttp_name"`
+	TTPPath         string            `json:"ttp_path,omitempty"`
+	WorkDir         string            `json:"work_dir,omitempty"`
+	StepVars        map[string]string `json:"step_vars,omitempty"`
+	Env             map[string]string `json:"env,omitempty"`
+	Steps           []StepState       `json:"steps"`
+	CleanupComplete bool              `json:"cleanup_complete,omitempty"`
+}
+
+// StepState records a single step that was executed (or skipped). Exactly one of
+// Cleanup, Iterations, Children and SubTTP describes how
+// the step should be cleaned up:
+//
+// Cleanup: the fully-resolved cleanup actions of the step
+// Iterations: the state of each iteration of a loop step
+// Children: the state of each child of a parallel step
+// SubTTP: the state of the steps of a sub-TTP
+//
+// The outputs of the step are retained so that cleanup actions can still
+// reference them with `$forge.steps.<name>.stdout`.
+type StepState struct {
+	Name       string            `json:"name"`
+	Skipped    bool              `json:"skipped,omitempty"`
+	Failed     bool              `json:"failed,omitempty"`
+	InProgress bool              `json:"in_progress,omitempty"`
+	CleanedUp  bool              `json:"cleaned_up,omitempty"`
+	WorkDir    string            `json:"work_dir,omitempty"`
+	Stdout     string            `json:"stdout,omitempty"`
+	Stderr     string            `json:"stderr,omitempty"`
+	Outputs    map[string]string `json:"outputs,omitempty"`
+	Cleanup    []map[string]any  `json:"cleanup,omitempty"`
+	Iterations []StepState       `json:"iterations,omitempty"`
+	Children   []StepState       `json:"children,omitempty"`
+	SubTTP     *RunState         `json:"sub_ttp,omitempty"`
+}
+
+// NewRunState captures the current state of a TTP execution.
+//
+// **Parameters:**
+//
+// ttp: the TTP that is being executed
+// execCtx: the execution context that is being used to run the TTP
+//
+// **Returns:**
+//
+// *RunState: the captured state
+// error: an error if the cleanup of any step could not be recorded
+func NewRunState(ttp *TTP, execCtx TTPExecutionContext) (*RunState, error) {
+	state := &RunState{
+		FormatVersion: RunStateFormatVersion,
+		TTPName:       ttp.Name,
+		TTPPath:       ttp.FilePath,
+		WorkDir:       ttp.WorkDir,
+		Steps:         []StepState{},
+	}
+	if execCtx.Vars != nil {
+		state.StepVars = execCtx.Vars.StepVars
+		state.Env = execCtx.Vars.Env
+	}
+	if execCtx.StepResults == nil {
+		return state, nil
+	}
+	for idx, result := range execCtx.StepResults.ByIndex {
+		if idx >= len(ttp.Steps) {
+			break
+		}
+		stepState, err := ttp.Steps[idx].state(result)
+		if err != nil {
+			return nil, err
+		}
+		state.Steps = append(state.Steps, stepState)
+	}
+
+	// a step that is still running (such as a sub-TTP)
+	// may already have completed some work that needs cleanup
+	if next := len(execCtx.StepResults.ByIndex); next < len(ttp.Steps) && ttp.Steps[next].running {
+		stepState, err := ttp.Steps[next].state(nil)
+		if err != nil {
+			return nil, err
+		}
+		stepState.InProgress = true
+		state.Steps = append(state.Steps, stepState)
+	}
+	return state, nil
+}
+
+// ReadRunState loads a run state file written by RunState.WriteFile
+func ReadRunState(path string) (*RunState, error) {
+	stateBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %v: %w", path, err)
+	}
+	var state RunState
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %v: %w", path, err)
+	}
+	if state.FormatVersion != RunStateFormatVersion {
+		return nil, fmt.Errorf("unsupported state file format version %d (expected %d)", state.FormatVersion, RunStateFormatVersion)
+	}
+	return &state, nil
+}
+
+// WriteFile writes the state to the specified path. The file is replaced
+// atomically so that it is never left half-written if TTPForge is killed.
+func (rs *RunState) WriteFile(path string) error {
+	stateBytes, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize run state: %w", err)
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".ttpforge-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write run state to %v: %w", path, err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(append(stateBytes, '\n')); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write run state to %v: %w", path, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write run state to %v: %w", path, err)
+	}
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("failed to write run state to %v: %w", path, err)
+	}
+	return nil
+}
+
+// RunCleanup runs the cleanup of every recorded step that has not
+// already been cleaned up, in reverse order. Steps that are cleaned up
+// successfully are marked so that they are not cleaned up again.
+func (rs *RunState) RunCleanup(execCtx TTPExecutionContext) error {
+	return rs.runCleanup(context.Background(), execCtx)
+}
+
+func (rs *RunState) runCleanup(ctx context.Context, execCtx TTPExecutionContext) error {
+	if rs.CleanupComplete {
+		logging.L().Infof("Cleanup of TTP %q has already been completed", rs.TTPName)
+		return nil
+	}
+
+	// steps that did not record their own working directory
+	// are cleaned up in the working directory of the TTP
+	if rs.WorkDir != "" {
+		execCtx.Vars.WorkDir = rs.WorkDir
+	}
+
+	// restore the variables and results that
+	// cleanup actions are able to reference
+	execCtx.Vars.StepVars = make(map[string]string)
+	for k, v := range rs.StepVars {
+		execCtx.Vars.StepVars[k] = v
+	}
+	// as well as the environment variables set by
+	// earlier steps that cleanup commands are run with
+	for k, v := range rs.Env {
+		execCtx.Vars.setEnv(k, v)
+	}
+	for _, stepState := range rs.Steps {
+		execCtx.StepResults.ByName[stepState.Name] = stepState.result()
+	}
+
+	logging.DividerThick()
+	logging.L().Infof("CLEANING UP %v steps of TTP: %q", len(rs.Steps), rs.TTPName)
+	var errs []error
+	for idx := len(rs.Steps) - 1; idx >= 0; idx-- {
+		stepState := &rs.Steps[idx]
+		logging.DividerThin()
+		if stepState.Skipped || stepState.Failed || stepState.CleanedUp {
+			logging.L().Infof("Not Cleaning Up Step #%d: %q - nothing to clean up", idx+1, stepState.Name)
+			continue
+		}
+		logging.L().Infof("Cleaning Up Step #%d: %q", idx+1, stepState.Name)
+		if err := stepState.runCleanup(ctx, execCtx); err != nil {
+			logging.L().Errorf("error cleaning up step %q: %v", stepState.Name, err)
+			errs = append(errs, err)
+		}
+	}
+	rs.CleanupComplete = len(errs) == 0
+	return errors.Join(errs...)
+}
+
+func (ss *StepState) result() *ExecutionResult {
+	return &ExecutionResult{
+		Name:    ss.Name,
+		Skipped: ss.Skipped,
+		ActResult: ActResult{
+			Stdout:  ss.Stdout,
+			Stderr:  ss.Stderr,
+			Outputs: ss.Outputs,
+		},
+	}
+}
+
+// runCleanup cleans up a single recorded step
+func (ss *StepState) runCleanup(ctx context.Context, execCtx TTPExecutionContext) error {
+	var errs []error
+	switch {
+	case ss.SubTTP != nil:
+		subExecCtx := NewTTPExecutionContext()
+		subExecCtx.Cfg = execCtx.Cfg
+		errs = append(errs, ss.SubTTP.runCleanup(ctx, subExecCtx))
+	case len(ss.Children) > 0 || len(ss.Iterations) > 0:
+		nested := ss.Children
+		if len(ss.Iterations) > 0 {
+			nested = ss.Iterations
+		}
+		for idx := len(nested) - 1; idx >= 0; idx-- {
+			if nested[idx].Skipped || nested[idx].CleanedUp {
+				continue
+			}
+			if err := nested[idx].runCleanup(ctx, execCtx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	default:
+		if ss.WorkDir != "" {
+			execCtx.Vars.WorkDir = ss.WorkDir
+		}
+		for _, actionSpec := range ss.Cleanup {
+			if err := runRecordedAction(ctx, ss.Name, actionSpec, execCtx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	err := errors.Join(errs...)
+	ss.CleanedUp = err == nil
+	return err
+}
+
+// runRecordedAction decodes and executes a cleanup action
+// that was recorded in a state file
+func runRecordedAction(ctx context.Context, stepName string, actionSpec map[string]any, execCtx TTPExecutionContext) error {
+	actionBytes, err := yaml.Marshal(actionSpec)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(actionBytes, &node); err != nil {
+		return err
+	}
+	step := Step{CommonStepFields: CommonStepFields{Name: stepName}}
+	action, err := parseRecordedAction(&step, node.Content[0])
+	if err != nil {
+		return fmt.Errorf("could not parse recorded cleanup action of step %q: %w", stepName, err)
+	}
+	if err := action.Validate(ctx, execCtx); err != nil {
+		return err
+	}
+	_, err = step.executeAction(ctx, action, execCtx)
+	return err
+}
+
+// parseRecordedAction decodes a cleanup action recorded in a state file,
+// which may be of a type that can only appear in state files
+func parseRecordedAction(step *Step, node *yaml.Node) (Action, error) {
+	for key, factory := range recordedActions {
+		if parseutils.FieldNode(node, key) == nil {
+			continue
+		}
+		action := factory()
+		if err := node.Decode(action); err != nil {
+			return nil, err
+		}
+		return action, nil
+	}
+	return step.ParseAction(node)
+}
+
+// state records the given step and the cleanup it requires
+func (s *Step) state(result *ExecutionResult) (StepState, error) {
+	stepState := StepState{
+		Name:      s.Name,
+		WorkDir:   s.workDir,
+		CleanedUp: s.cleanedUp,
+	}
+	if result != nil {
+		stepState.Skipped = result.Skipped
+		stepState.Failed = result.Failed
+		stepState.Stdout = result.Stdout
+		stepState.Stderr = result.Stderr
+		stepState.Outputs = result.Outputs
+	}
+	// failed steps are either cleaned up as soon as
+	// they fail or do not require cleanup at all
+	if stepState.Skipped || stepState.Failed {
+		return stepState, nil
+	}
+
+	if s.Loop != nil {
+		for _, iteration := range s.iterations {
+			cleanup, err := recordCleanup(iteration.cleanup)
+			if err != nil {
+				return StepState{}, fmt.Errorf("could not record cleanup of step %q: %w", s.Name, err)
+			}
+			stepState.Iterations = append(stepState.Iterations, StepState{
+				Name:    s.Name,
+				WorkDir: s.workDir,
+				Stdout:  iteration.result.Stdout,
+				Stderr:  iteration.result.Stderr,
+				Outputs: iteration.result.Outputs,
+				Cleanup: cleanup,
+			})
+		}
+		return stepState, nil
+	}
+
+	var err error
+	switch cleanup := s.cleanup.(type) {
+	case *subTTPCleanupAction:
+		subTTP := cleanup.step
+		if subTTP.ttp != nil && subTTP.subExecCtx != nil {
+			stepState.SubTTP, err = NewRunState(subTTP.ttp, *subTTP.subExecCtx)
+		}
+	case *parallelCleanupAction:
+		for _, child := range cleanup.step.children {
+			if child.result == nil || child.result.Skipped {
+				continue
+			}
+			var childState StepState
+			childState, err = child.step.state(child.result)
+			if err != nil {
+				break
+			}
+			stepState.Children = append(stepState.Children, childState)
+		}
+	default:
+		stepState.Cleanup, err = recordCleanup(cleanup)
+	}
+	if err != nil {
+		return StepState{}, fmt.Errorf("could not record cleanup of step %q: %w", s.Name, err)
+	}
+	return stepState, nil
+}
+
+// recordCleanup serializes a fully-resolved cleanup action so that
+// it can be executed later by a separate TTPForge process
+func recordCleanup(action Action) ([]map[string]any, error) {
+	switch a := action.(type) {
+	case nil:
+		return nil, nil
+	case *CompositeAction:
+		var specs []map[string]any
+		for _, subAction := range a.actions {
+			subSpecs, err := recordCleanup(subAction)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, subSpecs...)
+		}
+		return specs, nil
+	case *ChangeDirectoryStep:
+		// this only affects the working directory of later
+		// steps, which is recorded separately for each step
+		return nil, nil
+	case *subTTPCleanupAction, *parallelCleanupAction:
+		return nil, fmt.Errorf("cleanup action of type %T cannot be recorded", action)
+	}
+
+	actionBytes, err := yaml.Marshal(action)
+	if err != nil {
+		return nil, err
+	}
+	var spec map[string]any
+	if err := yaml.Unmarshal(actionBytes, &spec); err != nil {
+		return nil, err
+	}
+	return []map[string]any{spec}, nil
+}
+
+// stateRecorder writes the state of the top-level
+// TTP to a file each time a step finishes
+type stateRecorder struct {
+	mu      sync.Mutex
+	path    string
+	ttp     *TTP
+	execCtx TTPExecutionContext
+}
+
+// RecordState instructs the execution context to write the state of the
+// provided TTP to the specified path each time a step finishes.
+func (c *TTPExecutionContext) RecordState(path string, ttp *TTP) {
+	c.state = &stateRecorder{
+		path:    path,
+		ttp:     ttp,
+		execCtx: *c,
+	}
+}
+
+// recordState writes the current state, if state recording is enabled.
+// Failing to record the state does not stop the TTP.
+func (c TTPExecutionContext) recordState() {
+	if c.state == nil {
+		return
+	}
+	c.state.mu.Lock()
+	defer c.state.mu.Unlock()
+	state, err := NewRunState(c.state.ttp, c.state.execCtx)
+	if err == nil {
+		err = state.WriteFile(c.state.path)
+	}
+	if err != nil {
+		logging.L().Warnf("Failed to record run state: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCleanup(t *testing.T) {
+	testCases := []struct {
+		name     string
+		action   Action
+		expected []map[string]any
+	}{
+		{
+			name:     "No cleanup",
+			action:   nil,
+			expected: nil,
+		},
+		{
+			name: "Inline cleanup",
+			action: &BasicStep{
+				Inline:       "echo cleanup",
+				ExecutorName: ExecutorBash,
+			},
+			expected: []map[string]any{
+				{"inline": "echo cleanup", "executor": ExecutorBash},
+			},
+		},
+		{
+			name: "Composite cleanup is flattened",
+			action: &CompositeAction{
+				actions: []Action{
+					&CopyPathStep{Source: "/tmp/backup", Destination: "/tmp/orig", Overwrite: true},
+					&RemovePathAction{Path: "/tmp/backup"},
+				},
+			},
+			expected: []map[string]any{
+				{"copy_path": "/tmp/backup", "to": "/tmp/orig", "overwrite": true},
+				{"remove_path": "/tmp/backup"},
+			},
+		},
+		{
+			name:     "Change directory cleanup is not recorded",
+			action:   &ChangeDirectoryStep{PreviousCDStep: &ChangeDirectoryStep{Cd: "/tmp"}},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			specs, err := recordCleanup(tc.action)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, specs)
+		})
+	}
+}
+
+func TestRunStateCleanup(t *testing.T) {
+	tempDir := t.TempDir()
+	createdPath := filepath.Join(tempDir, "created.txt")
+	statePath := filepath.Join(tempDir, "state.json")
+	content := fmt.Sprintf(`name: state_test
+steps:
+  - name: make_file
+    create_file: %s
+    contents: hello
+    cleanup: default
+  - name: skipped
+    if: false
+    inline: echo skipped
+    cleanup:
+      inline: echo cleanup skipped
+  - name: greet
+    inline: echo greeting
+    cleanup:
+      inline: echo cleanup $forge.steps.greet.stdout
+  - name: spray
+    loop: [a, b]
+    inline: echo {[{ .Loop.Item }]}
+    cleanup:
+      inline: echo cleanup {[{ .Loop.Item }]}
+  - name: group
+    max_concurrency: 1
+    parallel:
+      - name: left
+        inline: echo left
+        cleanup:
+          inline: echo cleanup left
+      - name: right
+        inline: echo right
+        cleanup:
+          inline: echo cleanup right
+  - name: fails
+    inline: exit 1
+    cleanup:
+      inline: echo never`, createdPath)
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	var runStdout bytes.Buffer
+	execCtx := NewTTPExecutionContext()
+	execCtx.Cfg.Stdout = &runStdout
+	execCtx.Cfg.NoCleanup = true
+	require.NoError(t, ttp.Validate(execCtx))
+	execCtx.RecordState(statePath, ttp)
+	require.Error(t, ttp.Execute(execCtx))
+	require.NoError(t, ttp.RunCleanup(execCtx))
+	assert.Equal(t, "greeting\na\nb\nleft\nright\n", runStdout.String())
+	assert.FileExists(t, createdPath)
+
+	// clean up from the state file, as a separate process would
+	state, err := ReadRunState(statePath)
+	require.NoError(t, err)
+	require.Len(t, state.Steps, 6)
+	assert.True(t, state.Steps[1].Skipped)
+	assert.Len(t, state.Steps[3].Iterations, 2)
+	assert.Len(t, state.Steps[4].Children, 2)
+	// the failed step is recorded but not cleaned up
+	assert.True(t, state.Steps[5].Failed)
+	assert.Empty(t, state.Steps[5].Cleanup)
+
+	var cleanupStdout bytes.Buffer
+	cleanupCtx := NewTTPExecutionContext()
+	cleanupCtx.Cfg.Stdout = &cleanupStdout
+	require.NoError(t, state.RunCleanup(cleanupCtx))
+	assert.Equal(t, "cleanup right\ncleanup left\ncleanup b\ncleanup a\ncleanup greeting\n", cleanupStdout.String())
+	assert.NoFileExists(t, createdPath)
+	assert.True(t, state.CleanupComplete)
+
+	// cleaning up again should not do anything
+	cleanupStdout.Reset()
+	require.NoError(t, state.RunCleanup(cleanupCtx))
+	assert.Empty(t, cleanupStdout.String())
+}
+
+func TestRunStateRestoresEnv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "s3cr3t")
+	}))
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	content := fmt.Sprintf(`name: state_env_test
+steps:
+  - name: get_token
+    http_request: %s
+    type: GET
+    response: TOKEN
+  - name: use_token
+    inline: echo using $TOKEN
+    cleanup:
+      inline: echo revoking $TOKEN`, server.URL)
+
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	var runStdout bytes.Buffer
+	execCtx := NewTTPExecutionContext()
+	execCtx.Cfg.Stdout = &runStdout
+	execCtx.Cfg.NoCleanup = true
+	require.NoError(t, ttp.Validate(execCtx))
+	execCtx.RecordState(statePath, ttp)
+	require.NoError(t, ttp.Execute(execCtx))
+	assert.Equal(t, "using s3cr3t\n", runStdout.String())
+
+	state, err := ReadRunState(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "s3cr3t"}, state.Env)
+
+	var cleanupStdout bytes.Buffer
+	cleanupCtx := NewTTPExecutionContext()
+	cleanupCtx.Cfg.Stdout = &cleanupStdout
+	require.NoError(t, state.RunCleanup(cleanupCtx))
+	assert.Equal(t, "revoking s3cr3t\n", cleanupStdout.String())
+}
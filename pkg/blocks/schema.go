@@ -0,0 +1,110 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"reflect"
+
+	"github.com/facebookincubator/ttpforge/pkg/jsonschema"
+)
+
+// JSONSchema generates a JSON Schema for TTP files, which
+// covers every registered action type. It is intended for
+// editors rather than for validation - TTPs are templates,
+// so a TTP that does not match the schema before it is
+// rendered may still be valid.
+//
+// **Returns:**
+//
+// *jsonschema.Schema: the schema document
+func JSONSchema() *jsonschema.Schema {
+	r := jsonschema.NewReflector()
+	schema := r.ReflectStruct(reflect.TypeOf(TTP{}))
+	schema.Title = "TTPForge TTP"
+	schema.Properties["tests"] = r.Reflect(reflect.TypeOf([]TestCase{}))
+	return r.Document(schema)
+}
+
+// JSONSchema describes a step as the common step fields
+// combined with the fields of any one action type
+func (s *Step) JSONSchema(r *jsonschema.Reflector) *jsonschema.Schema {
+	cleanup := r.Define("blocks.Cleanup", func() *jsonschema.Schema {
+		return cleanupJSONSchema(r)
+	})
+	schema := &jsonschema.Schema{}
+	for _, key := range RegisteredActionKeys() {
+		actionSchema := actionJSONSchema(r, key)
+		for name, prop := range r.ReflectStruct(reflect.TypeOf(s.CommonStepFields)).Properties {
+			// actions such as expect give some shared keys their own meaning
+			if _, ok := actionSchema.Properties[name]; !ok {
+				actionSchema.Properties[name] = prop
+			}
+		}
+		actionSchema.Properties["cleanup"] = cleanup
+		actionSchema.Required = []string{"name", key}
+		schema.AnyOf = append(schema.AnyOf, actionSchema)
+	}
+	return schema
+}
+
+// cleanupJSONSchema describes the `cleanup:` of a step,
+// which is either `default` or an action with an optional name
+func cleanupJSONSchema(r *jsonschema.Reflector) *jsonschema.Schema {
+	schema := &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{{Const: "default"}},
+	}
+	for _, key := range RegisteredActionKeys() {
+		actionSchema := actionJSONSchema(r, key)
+		actionSchema.Properties["name"] = &jsonschema.Schema{Type: "string"}
+		actionSchema.Required = []string{key}
+		schema.AnyOf = append(schema.AnyOf, actionSchema)
+	}
+	return schema
+}
+
+// actionJSONSchema describes the fields of the action type
+// identified by key, without any of the common step fields
+func actionJSONSchema(r *jsonschema.Reflector, key string) *jsonschema.Schema {
+	factory, _ := lookupAction(key)
+	return r.ReflectStruct(reflect.TypeOf(factory()))
+}
+
+// JSONSchema describes the single exit
+// code and list forms of ExitCodes
+func (codes *ExitCodes) JSONSchema(*jsonschema.Reflector) *jsonschema.Schema {
+	minItems := 1
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{
+			{Type: "integer"},
+			{Type: "array", Items: &jsonschema.Schema{Type: "integer"}, MinItems: &minItems},
+		},
+	}
+}
+
+// JSONSchema describes both the mapping and
+// the literal list forms of LoopSpec
+func (l *LoopSpec) JSONSchema(r *jsonschema.Reflector) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		AnyOf: []*jsonschema.Schema{
+			r.Reflect(reflect.TypeOf(l.Items)),
+			r.ReflectStruct(reflect.TypeOf(l)),
+		},
+	}
+}
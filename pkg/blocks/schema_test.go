@@ -0,0 +1,124 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/args"
+	"github.com/facebookincubator/ttpforge/pkg/jsonschema"
+	"github.com/facebookincubator/ttpforge/pkg/platforms"
+	"github.com/facebookincubator/ttpforge/pkg/preprocess"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	assert.Equal(t, jsonschema.Draft, schema.Schema)
+	for _, field := range []string{"uuid", "name", "args", "mitre", "requirements", "tests", "outputs", "steps"} {
+		assert.Contains(t, schema.Properties, field)
+	}
+	for _, def := range []string{"args.Spec", "checks.Check", "outputs.Spec", "blocks.TestCase", "blocks.Cleanup"} {
+		assert.Contains(t, schema.Defs, def)
+	}
+
+	// every action type that may be used in a TTP should be described
+	stepSchema := schema.Defs["blocks.Step"]
+	require.NotNil(t, stepSchema)
+	var actionKeys []string
+	for _, branch := range stepSchema.AnyOf {
+		require.Len(t, branch.Required, 2)
+		assert.Equal(t, "name", branch.Required[0])
+		actionKey := branch.Required[1]
+		actionKeys = append(actionKeys, actionKey)
+		for _, field := range []string{actionKey, "name", "if", "loop", "checks", "cleanup", "description"} {
+			assert.Contains(t, branch.Properties, field, "step schema for `%v:`", actionKey)
+		}
+	}
+	assert.Equal(t, RegisteredActionKeys(), actionKeys)
+	assert.NotContains(t, actionKeys, "plugin_cleanup")
+
+	// the schema must be valid JSON
+	_, err := json.Marshal(schema)
+	require.NoError(t, err)
+}
+
+func TestExampleTTPsMatchJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	exampleDir := filepath.Join("..", "..", "example-ttps")
+	var ttpPaths []string
+	err := filepath.WalkDir(exampleDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, ".yaml") {
+			ttpPaths = append(ttpPaths, path)
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, ttpPaths)
+
+	for _, ttpPath := range ttpPaths {
+		t.Run(ttpPath, func(t *testing.T) {
+			ttpBytes, err := os.ReadFile(ttpPath)
+			require.NoError(t, err)
+
+			// the schema describes rendered TTPs, so the TTP is rendered with
+			// the arguments of its first test case (if it has one) and
+			// placeholder values for any other arguments
+			argSpecs, err := parseArgSpecs(ttpBytes)
+			require.NoError(t, err)
+			var preamble struct {
+				Tests []TestCase `yaml:"tests"`
+			}
+			result, err := preprocess.Parse(ttpBytes)
+			require.NoError(t, err)
+			require.NoError(t, yaml.Unmarshal(result.PreambleBytes, &preamble))
+			var testArgs []string
+			if len(preamble.Tests) > 0 {
+				for argName, argVal := range preamble.Tests[0].Args {
+					testArgs = append(testArgs, argName+"="+argVal)
+				}
+			}
+			specs, argsKvStrs := args.WithPlaceholders(argSpecs, testArgs)
+			argValues, err := args.ParseAndValidate(specs, argsKvStrs)
+			require.NoError(t, err)
+			rendered, err := RenderTemplate(string(ttpBytes), RenderParameters{
+				Args:     argValues,
+				Platform: platforms.GetCurrentPlatformSpec(),
+			})
+			require.NoError(t, err)
+
+			// the schema is checked against the JSON form of the TTP
+			var ttp any
+			require.NoError(t, yaml.Unmarshal(rendered, &ttp))
+			ttpJSON, err := json.Marshal(ttp)
+			require.NoError(t, err)
+			var document any
+			require.NoError(t, json.Unmarshal(ttpJSON, &document))
+			assert.NoError(t, schema.Validate(document))
+		})
+	}
+}
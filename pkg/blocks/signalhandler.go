@@ -20,6 +20,8 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"sync"
@@ -30,32 +32,89 @@ import (
 
 var signalHandlerInstalled bool
 var signalHandlerLock = sync.Mutex{}
-var shutdownChan chan bool
 
-// SetupSignalHandler sets up SIGINT and SIGTERM handlers for graceful shutdown
-func SetupSignalHandler() chan bool {
+// shutdownCancels holds the cancel functions of the contexts
+// created by withShutdown that have not been stopped yet
+var shutdownCancels = make(map[*context.CancelCauseFunc]bool)
+
+// shutdownSignals counts the shutdown signals received so far
+var shutdownSignals int
+
+// exitProcess is called to exit without finishing cleanup
+// once forceExitSignals shutdown signals have been received
+var exitProcess = os.Exit
+
+// forceExitSignals is the number of shutdown signals
+// after which TTPForge exits without finishing cleanup
+const forceExitSignals = 3
+
+// ErrShutdown is returned when a TTP is stopped by a shutdown signal
+var ErrShutdown = errors.New("[*] Shutting Down now")
+
+// SetupSignalHandler sets up SIGINT and SIGTERM handlers for graceful shutdown.
+// Received signals are forwarded to the process trees of running steps
+// and every context created by withShutdown that is still in use
+// is canceled with ErrShutdown as its cause. The third signal
+// exits the process immediately, abandoning any remaining cleanup.
+func SetupSignalHandler() {
 	// setup signal handling only once
 	signalHandlerLock.Lock()
+	defer signalHandlerLock.Unlock()
 	if signalHandlerInstalled {
-		signalHandlerLock.Unlock()
-		return shutdownChan
+		return
 	}
 	sigs := make(chan os.Signal, 2)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	shutdownChan = make(chan bool, 1)
 	signalHandlerInstalled = true
-	signalHandlerLock.Unlock()
 
 	go func() {
-		var sig os.Signal
-		var counter int
-		for {
-			sig = <-sigs
-			logging.L().Infof("[%v] Received signal %v, shutting down now", counter, sig)
-			shutdownChan <- true
-			counter++
+		for sig := range sigs {
+			handleSignal(sig)
 		}
 	}()
+}
+
+// handleSignal shuts down the running TTP in response to sig
+func handleSignal(sig os.Signal) {
+	signalHandlerLock.Lock()
+	counter := shutdownSignals
+	shutdownSignals++
+	exit := exitProcess
+	signalHandlerLock.Unlock()
+
+	if counter+1 >= forceExitSignals {
+		logging.L().Errorf("[%v] Received signal %v again, exiting without finishing cleanup", counter, sig)
+		exit(1)
+		return
+	}
+	logging.L().Infof("[%v] Received signal %v, shutting down now", counter, sig)
+	forwardSignal(sig)
+	signalHandlerLock.Lock()
+	for cancel := range shutdownCancels {
+		(*cancel)(ErrShutdown)
+	}
+	signalHandlerLock.Unlock()
+}
+
+// withShutdown returns a copy of ctx that is canceled (with ErrShutdown
+// as its cause) if a shutdown signal is received before stop is called.
+// Signals received after stop is called do not affect the returned context,
+// which lets cleanup run after the steps were interrupted by a signal.
+func withShutdown(ctx context.Context) (context.Context, context.CancelFunc) {
+	SetupSignalHandler()
+	ctx, cancel := context.WithCancelCause(ctx)
+	signalHandlerLock.Lock()
+	shutdownCancels[&cancel] = true
+	signalHandlerLock.Unlock()
+	return ctx, func() {
+		signalHandlerLock.Lock()
+		delete(shutdownCancels, &cancel)
+		signalHandlerLock.Unlock()
+		cancel(nil)
+	}
+}
 
-	return shutdownChan
+// isShutdown reports whether ctx was canceled by a shutdown signal
+func isShutdown(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrShutdown)
 }
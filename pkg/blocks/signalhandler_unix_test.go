@@ -0,0 +1,88 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetShutdownSignals makes the signals sent by a test count
+// as if they were sent to a new TTPForge process
+func resetShutdownSignals() {
+	signalHandlerLock.Lock()
+	defer signalHandlerLock.Unlock()
+	shutdownSignals = 0
+}
+
+func TestWithShutdown(t *testing.T) {
+	resetShutdownSignals()
+	stoppedCtx, stopStopped := withShutdown(context.Background())
+	stopStopped()
+	ctx, stop := withShutdown(context.Background())
+	defer stop()
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not canceled by the signal")
+	}
+	assert.True(t, isShutdown(ctx))
+	assert.ErrorIs(t, context.Cause(ctx), ErrShutdown)
+	assert.False(t, isShutdown(stoppedCtx))
+
+	// contexts created after the signal are not affected by it
+	laterCtx, stopLater := withShutdown(context.Background())
+	defer stopLater()
+	assert.NoError(t, laterCtx.Err())
+}
+
+func TestThirdSignalExits(t *testing.T) {
+	resetShutdownSignals()
+	defer resetShutdownSignals()
+	var exitCodes []int
+	signalHandlerLock.Lock()
+	exitProcess = func(code int) { exitCodes = append(exitCodes, code) }
+	signalHandlerLock.Unlock()
+	defer func() {
+		signalHandlerLock.Lock()
+		exitProcess = os.Exit
+		signalHandlerLock.Unlock()
+	}()
+
+	ctx, stop := withShutdown(context.Background())
+	defer stop()
+	handleSignal(syscall.SIGINT)
+	assert.True(t, isShutdown(ctx))
+	handleSignal(syscall.SIGINT)
+	assert.Empty(t, exitCodes)
+	handleSignal(syscall.SIGINT)
+	assert.Equal(t, []int{1}, exitCodes)
+}
@@ -20,11 +20,14 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/facebookincubator/ttpforge/pkg/checks"
 	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 )
@@ -33,8 +36,16 @@ import (
 // common to every type of step (such as Name).
 // It centralizes validation to simplify the code
 type CommonStepFields struct {
-	Name   string         `yaml:"name,omitempty"`
-	Checks []checks.Check `yaml:"checks,omitempty"`
+	Name    string         `yaml:"name,omitempty"`
+	If      string         `yaml:"if,omitempty"`
+	Loop    *LoopSpec      `yaml:"loop,omitempty"`
+	ForEach *LoopSpec      `yaml:"for_each,omitempty"`
+	Retry   *RetrySpec     `yaml:"retry,omitempty"`
+	Timeout string         `yaml:"timeout,omitempty"`
+	Checks  []checks.Check `yaml:"checks,omitempty"`
+
+	ExpectExitCode  ExitCodes `yaml:"expect_exit_code,omitempty"`
+	ContinueOnError bool      `yaml:"continue_on_error,omitempty"`
 
 	// CleanupSpec is exported so that UnmarshalYAML
 	// can see it - however, it should be considered
@@ -54,6 +65,41 @@ type Step struct {
 	// but rather must be decoded by ParseAction
 	action  Action
 	cleanup Action
+
+	// actionKey and cleanupKey are the registered keys that
+	// identified the type of each action in the YAML (the
+	// cleanupKey of a default cleanup action is empty)
+	actionKey  string
+	cleanupKey string
+
+	// cleanupTemplated is set once the cleanup action has been
+	// templated, which happens after the step itself has run
+	cleanupTemplated bool
+
+	// node is retained so that a fresh copy of the
+	// action can be decoded for each iteration of a loop
+	node       *yaml.Node
+	iterations []*loopIteration
+
+	// index is the index of the step within its TTP (or parallel
+	// step), set when the step runs, for notifying observers
+	index int
+
+	// attempts is the number of times the step was tried
+	attempts int
+
+	// running is set while the step is executing and workDir
+	// records the working directory in which it was executed -
+	// both are used to record the state of the TTP execution
+	running bool
+	workDir string
+
+	// cleanedUp is set once the step has been cleaned up successfully
+	cleanedUp bool
+
+	// ignoredErr is the error of the last execution
+	// of a step that has `continue_on_error:` set
+	ignoredErr error
 }
 
 func isDefaultCleanup(cleanupNode *yaml.Node) (bool, error) {
@@ -77,8 +123,12 @@ func isDefaultCleanup(cleanupNode *yaml.Node) (bool, error) {
 // you shouldn't try to remove_path a create_file that failed)
 // However, certain step types (especially SubTTPs) need to run cleanup even if they fail
 func (s *Step) ShouldCleanupOnFailure() bool {
+	// earlier iterations of a loop may have succeeded
+	if s.Loop != nil {
+		return true
+	}
 	switch s.action.(type) {
-	case *SubTTPStep:
+	case *SubTTPStep, *ParallelStep:
 		return true
 	default:
 		return false
@@ -92,7 +142,7 @@ func (s *Step) ShouldCleanupOnFailure() bool {
 // compatibility
 func ShouldUseImplicitDefaultCleanup(action Action) bool {
 	switch action.(type) {
-	case *SubTTPStep:
+	case *SubTTPStep, *ParallelStep:
 		return true
 	default:
 		return false
@@ -103,7 +153,10 @@ func ShouldUseImplicitDefaultCleanup(action Action) bool {
 // process to ensure that the step action and its
 // cleanup action are decoded to the correct struct type
 func (s *Step) UnmarshalYAML(node *yaml.Node) error {
+	return parseutils.ErrorAt(node, s.unmarshalYAML(node))
+}
 
+func (s *Step) unmarshalYAML(node *yaml.Node) error {
 	// Decode all of the shared fields.
 	// Use of this auxiliary type prevents infinite recursion
 	var csf CommonStepFields
@@ -117,190 +170,427 @@ func (s *Step) UnmarshalYAML(node *yaml.Node) error {
 		return errors.New("no name specified for step")
 	}
 
-	// figure out what kind of action is
-	// associated with executing this step
-	s.action, err = s.ParseAction(node)
+	// `for_each:` is an alias for `loop:`
+	if s.ForEach != nil {
+		if s.Loop != nil {
+			return fmt.Errorf("step %q cannot specify both `loop:` and `for_each:`", s.Name)
+		}
+		s.Loop, s.ForEach = s.ForEach, nil
+	}
+
+	s.node = node
+	s.action, s.cleanup, err = s.parseActions(node)
+	if err != nil {
+		return err
+	}
+
+	// expect steps predate step timeouts and use `timeout:`
+	// for the number of seconds to wait for each prompt
+	if _, ok := s.action.(*ExpectStep); ok {
+		s.Timeout = ""
+	}
+	return nil
+}
+
+// parseActions figures out what kind of action is associated
+// with executing this step and with cleaning it up
+func (s *Step) parseActions(node *yaml.Node) (Action, Action, error) {
+	actionKey, action, err := s.parseAction(node, &CommonStepFields{})
 	if err != nil {
-		return fmt.Errorf("could not parse action for step %q: %w", s.Name, err)
+		return nil, nil, fmt.Errorf("could not parse action for step %q: %w", s.Name, err)
 	}
+	s.actionKey, s.cleanupKey = actionKey, ""
 
-	// figure out what kind of action is
-	// associated with cleaning up this step
-	if csf.CleanupSpec.IsZero() {
+	if s.CleanupSpec.IsZero() {
 		// hack for subTTPs - they should always use their default cleanup
-		if ShouldUseImplicitDefaultCleanup(s.action) {
-			s.cleanup = s.action.GetDefaultCleanupAction()
-		}
-	} else {
-		useDefaultCleanup, err := isDefaultCleanup(&csf.CleanupSpec)
-		if err != nil {
-			return err
-		}
-		if useDefaultCleanup {
-			if dca := s.action.GetDefaultCleanupAction(); dca != nil {
-				s.cleanup = dca
-				return nil
-			}
-			return fmt.Errorf("`cleanup: default` was specified but step %v is not an action type that has a default cleanup action", s.Name)
+		if ShouldUseImplicitDefaultCleanup(action) {
+			return action, action.GetDefaultCleanupAction(), nil
 		}
+		return action, nil, nil
+	}
 
-		s.cleanup, err = s.ParseAction(&csf.CleanupSpec)
-		if err != nil {
-			return fmt.Errorf("could not parse cleanup action for step %q: %w", s.Name, err)
+	useDefaultCleanup, err := isDefaultCleanup(&s.CleanupSpec)
+	if err != nil {
+		return nil, nil, parseutils.ErrorAt(&s.CleanupSpec, err)
+	}
+	if useDefaultCleanup {
+		if dca := action.GetDefaultCleanupAction(); dca != nil {
+			return action, dca, nil
 		}
+		return nil, nil, fmt.Errorf("`cleanup: default` was specified but step %v is not an action type that has a default cleanup action", s.Name)
 	}
-	return nil
+
+	// cleanup actions may be given a name, which is ignored
+	cleanupFields := struct {
+		Name string `yaml:"name"`
+	}{}
+	cleanupKey, cleanup, err := s.parseAction(&s.CleanupSpec, &cleanupFields)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse cleanup action for step %q: %w", s.Name, err)
+	}
+	s.cleanupKey = cleanupKey
+	return action, cleanup, nil
 }
 
 // Validate checks that both the step action and cleanup
 // action are valid
-func (s *Step) Validate(execCtx TTPExecutionContext) error {
-	if err := s.action.Validate(execCtx); err != nil {
+func (s *Step) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
+	if s.If != "" {
+		if _, err := parseCondition(s.If, ExpressionScope{}); err != nil {
+			return fmt.Errorf("step %q has an invalid `if:` condition: %w", s.Name, err)
+		}
+	}
+	if s.Loop != nil {
+		if err := s.Loop.Validate(); err != nil {
+			return fmt.Errorf("step %q has an invalid loop: %w", s.Name, err)
+		}
+	}
+	if s.Timeout != "" {
+		if _, err := parseTimeout(s.Timeout); err != nil {
+			return fmt.Errorf("step %q has an invalid timeout: %w", s.Name, err)
+		}
+	}
+	if s.Retry != nil {
+		if err := s.Retry.Validate(); err != nil {
+			return fmt.Errorf("step %q has an invalid retry policy: %w", s.Name, err)
+		}
+	}
+	if s.ExpectExitCode != nil {
+		switch s.action.(type) {
+		case *BasicStep, *FileStep, *PluginAction:
+		default:
+			return fmt.Errorf("step %q specifies `expect_exit_code:` but is not an inline, file or plugin step", s.Name)
+		}
+	}
+	if err := s.action.Validate(ctx, execCtx); err != nil {
 		return err
 	}
 	if s.cleanup != nil {
-		if err := s.cleanup.Validate(execCtx); err != nil {
+		if err := s.cleanup.Validate(ctx, execCtx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// Template replaces variables in the step action and cleanup
-func (s *Step) Template(execCtx TTPExecutionContext) error {
-	if err := s.action.Template(execCtx); err != nil {
-		return err
+// Template replaces variables in the step action.
+// The cleanup action is templated by templateCleanup
+// once the step has run, so that it can reference
+// the results of the step itself.
+// Loop steps are templated separately for each iteration
+// when they are executed.
+func (s *Step) Template(ctx context.Context, execCtx TTPExecutionContext) error {
+	if s.Loop != nil {
+		return nil
 	}
-	if s.cleanup != nil {
-		if err := s.cleanup.Template(execCtx); err != nil {
-			return err
-		}
+	return s.action.Template(ctx, execCtx)
+}
+
+// templateCleanup replaces variables in the cleanup action of the step
+// (if this has not happened yet) - it is called once the results
+// of the step have been recorded
+func (s *Step) templateCleanup(ctx context.Context, execCtx TTPExecutionContext) error {
+	if s.Loop != nil || s.cleanup == nil || s.cleanupTemplated {
+		return nil
 	}
+	if err := s.cleanup.Template(ctx, execCtx); err != nil {
+		return fmt.Errorf("could not template cleanup of step %q: %w", s.Name, err)
+	}
+	s.cleanupTemplated = true
 	return nil
 }
 
-// Execute runs the action associated with this step and sends result/error to channels of the context
-func (s *Step) Execute(execCtx TTPExecutionContext) (*ActResult, error) {
-	desc := s.action.GetDescription()
-	if desc != "" {
-		logging.L().Infof("Description: %v", desc)
+// ShouldRun evaluates the `if:` condition of this step (if any)
+// and reports whether the step should be executed
+func (s *Step) ShouldRun(execCtx TTPExecutionContext) (bool, error) {
+	if s.If == "" {
+		return true, nil
+	}
+	shouldRun, err := execCtx.evaluateCondition(s.If)
+	if err != nil {
+		return false, fmt.Errorf("could not evaluate `if:` condition of step %q: %w", s.Name, err)
+	}
+	return shouldRun, nil
+}
+
+// Execute runs the action associated with this step
+func (s *Step) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	var result *ActResult
+	var err error
+	if s.Loop != nil {
+		result, err = s.executeLoop(ctx, execCtx)
+	} else {
+		result, err = s.executeAction(ctx, s.action, execCtx)
 	}
-	result, err := s.action.Execute(execCtx)
 	if err != nil {
 		logging.L().Errorf("Failed to execute step %v: %v", s.Name, err)
-		execCtx.errorsChan <- err
 	} else {
 		logging.L().Debugf("Successfully executed step %v", s.Name)
-		execCtx.actionResultsChan <- result
 	}
 
 	return result, err
 }
 
-// Cleanup runs the cleanup action associated with this step
-func (s *Step) Cleanup(execCtx TTPExecutionContext) (*ActResult, error) {
-	if s.cleanup != nil {
-		desc := s.cleanup.GetDescription()
-		if desc != "" {
-			logging.L().Infof("Description: %v", desc)
-		}
-		return s.cleanup.Execute(execCtx)
+// Run templates the step and then executes it,
+// retrying it if the step has a retry policy.
+// If the step has `continue_on_error:` set, a failed
+// execution is logged and treated as a success.
+func (s *Step) Run(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	s.running = true
+	defer func() {
+		s.running = false
+	}()
+	s.workDir = execCtx.Vars.WorkDir
+	s.ignoredErr = nil
+
+	result, err := s.run(ctx, execCtx)
+	if err == nil || !s.ContinueOnError {
+		return result, err
 	}
-	logging.L().Infof("No Cleanup Action Defined for Step %v", s.Name)
-	return &ActResult{}, nil
+	logging.L().Warnf("Step %q failed but has continue_on_error set: %v", s.Name, err)
+	s.ignoredErr = err
+	if result == nil {
+		result = &ActResult{}
+	}
+	return result, nil
 }
 
-// ParseAction decodes an action (from step or cleanup) in YAML
-// format into the appropriate struct
-func (s *Step) ParseAction(node *yaml.Node) (Action, error) {
-	var typeField struct {
-		Inline    string     `yaml:"inline"`
-		File      string     `yaml:"file"`
-		TTP       string     `yaml:"ttp"`
-		EditFile  string     `yaml:"edit_file"`
-		Responses []Response `yaml:"responses"`
+func (s *Step) run(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	policy, err := s.retryPolicy(execCtx)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		return s.runWithRetries(ctx, execCtx, policy)
 	}
+	s.attempts = 1
+	return s.runOnce(ctx, execCtx)
+}
+
+// IgnoredError returns the error of the last execution of
+// this step if it failed but has `continue_on_error:` set
+func (s *Step) IgnoredError() error {
+	return s.ignoredErr
+}
 
-	if err := node.Decode(&typeField); err != nil {
+func (s *Step) runOnce(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	if err := s.Template(ctx, execCtx); err != nil {
+		logging.L().Errorf("Error templating step %s: %v", s.Name, err)
 		return nil, err
 	}
 
-	// Check for ambiguous types
-	typesCount := 0
-	if typeField.Inline != "" {
-		typesCount++
+	timeout := s.timeout()
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := s.Execute(stepCtx, execCtx)
+	if err != nil && isDeadlineExceeded(stepCtx, ctx) {
+		return result, fmt.Errorf("step %q timed out after %v: %w", s.Name, timeout, err)
 	}
-	if typeField.File != "" {
-		typesCount++
+	return result, err
+}
+
+// resetActions replaces the step action and cleanup with fresh
+// (untemplated) copies so that the step can be run again
+func (s *Step) resetActions(ctx context.Context, execCtx TTPExecutionContext) error {
+	if s.node == nil {
+		return nil
 	}
-	if typeField.TTP != "" {
-		typesCount++
+	var err error
+	s.action, s.cleanup, err = s.parseActions(s.node)
+	if err != nil {
+		return err
 	}
-	if typeField.EditFile != "" {
-		typesCount++
+	s.cleanupTemplated = false
+	return s.Validate(ctx, execCtx)
+}
+
+// timeout returns the maximum amount of time
+// for which a single attempt of the step may run
+func (s *Step) timeout() time.Duration {
+	if s.Timeout == "" {
+		return DefaultExecutionTimeout
 	}
-	if typesCount > 1 {
-		return nil, fmt.Errorf("step %v has ambiguous type", s.Name)
+	// already checked by Validate
+	timeout, err := parseTimeout(s.Timeout)
+	if err != nil {
+		return DefaultExecutionTimeout
 	}
+	return timeout
+}
 
-	// Check for ExpectStep
-	if len(typeField.Responses) > 0 {
-		expectStep := NewExpectStep()
-		if err := node.Decode(expectStep); err != nil {
-			return nil, err
+// cleanupContext returns the context in which the step's cleanup should run.
+// Cleanup must still run after the step (or the whole TTP) has timed out,
+// so it is not bound by the deadline of the step, only by its own timeout.
+// It is still canceled if ctx is canceled by a shutdown signal.
+func (s *Step) cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	cleanupCtx, cancelCleanup := context.WithCancelCause(context.WithoutCancel(ctx))
+	stopShutdown := context.AfterFunc(ctx, func() {
+		if isShutdown(ctx) {
+			cancelCleanup(ErrShutdown)
 		}
-		return expectStep, nil
+	})
+	cleanupCtx, cancelTimeout := context.WithTimeout(cleanupCtx, s.timeout())
+	return cleanupCtx, func() {
+		cancelTimeout()
+		stopShutdown()
+		cancelCleanup(nil)
+	}
+}
+
+func (s *Step) executeAction(ctx context.Context, action Action, execCtx TTPExecutionContext) (*ActResult, error) {
+	desc := action.GetDescription()
+	if desc != "" {
+		logging.L().Infof("Description: %v", desc)
+	}
+	return s.checkExitCode(action.Execute(ctx, execCtx))
+}
+
+// executeLoop runs a fresh copy of the step action
+// for every item in the step's loop
+func (s *Step) executeLoop(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	items, err := s.Loop.resolveItems(execCtx)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve loop items for step %q: %w", s.Name, err)
 	}
 
-	// Otherwise, treat it as a BasicStep
-	if typeField.Inline != "" {
-		basicStep := NewBasicStep()
-		if err := node.Decode(basicStep); err != nil {
+	s.iterations = nil
+	defer func() {
+		execCtx.Vars.Loop = nil
+	}()
+	var results []*ActResult
+	for idx, item := range items {
+		logging.L().Infof("Loop iteration #%d of step %q: %v", idx+1, s.Name, item)
+		action, cleanup, err := s.parseActions(s.node)
+		if err != nil {
 			return nil, err
 		}
-		return basicStep, nil
-	}
-
-	actionCandidates := []Action{
-		NewBasicStep(),
-		NewChangeDirectoryStep(),
-		NewFileStep(),
-		NewSubTTPStep(),
-		NewEditStep(),
-		NewFetchURIStep(),
-		NewCreateFileStep(),
-		NewCopyPathStep(),
-		NewRemovePathAction(),
-		NewPrintStrAction(),
-		NewExpectStep(),
-		NewHTTPRequestStep(),
-		NewKillProcessStep(),
-	}
-
-	var action Action
-	for _, actionType := range actionCandidates {
-		err := node.Decode(actionType)
-		if err == nil && !actionType.IsNil() {
-			if action != nil {
-				// Must catch bad steps with ambiguous types, such as:
-				// - name: hello
-				//   file: bar
-				//   ttp: foo
-				//
-				// we can't use KnownFields to solve this without a massive
-				// refactor due to https://github.com/go-yaml/yaml/issues/460
-				// note: we check for non-empty name earlier so s.Name will be non-empty
-				return nil, fmt.Errorf("step %v has ambiguous type", s.Name)
+		execCtx.Vars.Loop = &LoopVars{
+			Item:  item,
+			Index: idx,
+		}
+		for _, a := range []Action{action, cleanup} {
+			if a == nil {
+				continue
+			}
+			if err := a.Validate(ctx, execCtx); err != nil {
+				return nil, err
 			}
-			action = actionType
+			if err := a.Template(ctx, execCtx); err != nil {
+				return nil, err
+			}
+		}
+
+		startTime := time.Now()
+		result, err := s.executeAction(ctx, action, execCtx)
+		if err != nil {
+			return nil, fmt.Errorf("loop iteration #%d (%v) failed: %w", idx+1, item, err)
+		}
+		result.StartTime = startTime
+		result.EndTime = time.Now()
+		s.iterations = append(s.iterations, &loopIteration{
+			item:    item,
+			result:  result,
+			cleanup: cleanup,
+		})
+		results = append(results, result)
+	}
+
+	aggregated := aggregateResults(results)
+	if len(results) > 0 {
+		aggregated.Outputs = results[len(results)-1].Outputs
+		aggregated.ExitCode = results[len(results)-1].ExitCode
+	}
+	return aggregated, nil
+}
+
+// IterationResults returns the results of each
+// completed loop iteration of this step (if any)
+func (s *Step) IterationResults() []*ActResult {
+	var results []*ActResult
+	for _, iteration := range s.iterations {
+		results = append(results, iteration.result)
+	}
+	return results
+}
+
+// ChildResults returns the results of the child steps
+// of a parallel group (or nil for any other type of step)
+func (s *Step) ChildResults() []*ExecutionResult {
+	if parallel, ok := s.action.(*ParallelStep); ok {
+		return parallel.ChildResults()
+	}
+	return nil
+}
+
+// Cleanup runs the cleanup action associated with this step
+func (s *Step) Cleanup(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	ctx, cancel := s.cleanupContext(ctx)
+	defer cancel()
+	if s.Loop != nil {
+		return s.cleanupLoop(ctx, execCtx)
+	}
+	if s.cleanup != nil {
+		if err := s.templateCleanup(ctx, execCtx); err != nil {
+			return nil, err
+		}
+		desc := s.cleanup.GetDescription()
+		if desc != "" {
+			logging.L().Infof("Description: %v", desc)
 		}
+		return s.cleanup.Execute(ctx, execCtx)
 	}
+	logging.L().Infof("No Cleanup Action Defined for Step %v", s.Name)
+	return &ActResult{}, nil
+}
 
-	if action == nil {
-		return nil, errors.New("action fields did not match any valid action type")
+// cleanupLoop cleans up each completed loop iteration in reverse order
+func (s *Step) cleanupLoop(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
+	var results []*ActResult
+	var errs []error
+	for idx := len(s.iterations) - 1; idx >= 0; idx-- {
+		iteration := s.iterations[idx]
+		if iteration.cleanup == nil {
+			continue
+		}
+		logging.L().Infof("Cleaning up loop iteration #%d of step %q: %v", idx+1, s.Name, iteration.item)
+		result, err := s.executeAction(ctx, iteration.cleanup, execCtx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cleanup of loop iteration #%d failed: %w", idx+1, err))
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 && len(errs) == 0 && s.cleanup == nil {
+		logging.L().Infof("No Cleanup Action Defined for Step %v", s.Name)
 	}
+	return aggregateResults(results), errors.Join(errs...)
+}
+
+// ParseAction decodes an action (from step or cleanup) in YAML
+// format into the appropriate struct
+func (s *Step) ParseAction(node *yaml.Node) (Action, error) {
+	_, action, err := s.parseAction(node)
+	return action, err
+}
 
-	return action, nil
+// parseAction decodes an action and reports any keys of the YAML
+// mapping that belong neither to the action nor to sharedFields.
+// It also returns the key that identified the type of the action.
+func (s *Step) parseAction(node *yaml.Node, sharedFields ...any) (string, Action, error) {
+	actionKey, action, err := newActionForNode(node)
+	if err != nil {
+		return "", nil, parseutils.ErrorAt(node, err)
+	}
+	if err := node.Decode(action); err != nil {
+		return "", nil, parseutils.ErrorAt(node, err)
+	}
+	if err := parseutils.CheckKnownFields(node, append([]any{action}, sharedFields...)...); err != nil {
+		return "", nil, err
+	}
+	if action.IsNil() {
+		return "", nil, parseutils.ErrorAt(parseutils.FieldNode(node, actionKey), fmt.Errorf("the `%v:` action is empty", actionKey))
+	}
+	return actionKey, action, nil
 }
 
 // VerifyChecks runs all checks and returns an error if any of them fail
@@ -311,6 +601,7 @@ func (s *Step) VerifyChecks() error {
 	}
 	verificationCtx := checks.VerificationContext{
 		FileSystem: afero.NewOsFs(),
+		WorkDir:    s.workDir,
 	}
 	for checkIdx, check := range s.Checks {
 		if err := check.Verify(verificationCtx); err != nil {
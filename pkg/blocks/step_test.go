@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -143,7 +144,21 @@ inline: echo "this is a run"
 cleanup:
   inline: echo {[{.StepVars.cleanup_message}]}
 `,
-			wantTemplateError: true,
+			expectedExecuteStdout: "this is a run\n",
+			wantCleanupError:      true,
+		},
+		{
+			name: "Cleanup templating can reference the step results",
+			content: `
+name: template_step
+inline: echo "this is a run"
+outputvar: run_message
+cleanup:
+  inline: echo "{[{.StepVars.run_message}]} - cleaned up"
+`,
+			stepVars:              map[string]string{},
+			expectedExecuteStdout: "this is a run\n",
+			expectedCleanupStdout: "this is a run - cleaned up\n",
 		},
 	}
 
@@ -162,7 +177,7 @@ cleanup:
 			require.NoError(t, err)
 
 			// validate the step
-			err = s.Validate(execCtx)
+			err = s.Validate(context.Background(), execCtx)
 			if tc.wantValidateError {
 				require.Error(t, err)
 				return
@@ -170,7 +185,7 @@ cleanup:
 			require.NoError(t, err)
 
 			// template the step
-			err = s.Template(execCtx)
+			err = s.Template(context.Background(), execCtx)
 			if tc.wantTemplateError {
 				require.Error(t, err)
 				return
@@ -178,7 +193,7 @@ cleanup:
 			require.NoError(t, err)
 
 			// execute the step and check output
-			result, err := s.Execute(execCtx)
+			result, err := s.Execute(context.Background(), execCtx)
 			if tc.wantExecuteError {
 				require.Error(t, err)
 				return
@@ -187,7 +202,7 @@ cleanup:
 			assert.Equal(t, tc.expectedExecuteStdout, result.Stdout)
 
 			// run cleanup and check output
-			cleanupResult, err := s.Cleanup(execCtx)
+			cleanupResult, err := s.Cleanup(context.Background(), execCtx)
 			if tc.wantCleanupError {
 				require.Error(t, err)
 				return
@@ -260,11 +275,11 @@ cleanup:
 			require.NoError(t, err)
 
 			// validate the step
-			err = s.Validate(execCtx)
+			err = s.Validate(context.Background(), execCtx)
 			require.NoError(t, err)
 
 			// execute the step and check file contents
-			_, err = s.Execute(execCtx)
+			_, err = s.Execute(context.Background(), execCtx)
 			if tc.wantExecuteError {
 				require.Error(t, err)
 				return
@@ -275,7 +290,7 @@ cleanup:
 			assert.Equal(t, tc.expectedFileContents, string(contentBytes))
 
 			// run cleanup
-			_, err = s.Cleanup(execCtx)
+			_, err = s.Cleanup(context.Background(), execCtx)
 			if tc.wantCleanupError {
 				require.Error(t, err)
 				return
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// parseCondition converts the provided `if:` expression
+// into a template that renders to "true" or "false".
+// The expression can reference everything in the ExpressionScope
+// as well as the results of earlier steps through legacy
+// `$forge.steps.x.stdout` references.
+func parseCondition(expr string, scope ExpressionScope) (*template.Template, error) {
+	// tolerate users who wrap the expression in step templating delimiters
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, stepTemplateLeftDelim)
+	expr = strings.TrimSuffix(expr, stepTemplateRightDelim)
+	rewritten, err := rewriteLegacyConditionReferences(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	tmplStr := fmt.Sprintf("%s if %s %strue%s else %sfalse%s end %s",
+		stepTemplateLeftDelim, rewritten, stepTemplateRightDelim,
+		stepTemplateLeftDelim, stepTemplateRightDelim,
+		stepTemplateLeftDelim, stepTemplateRightDelim,
+	)
+	tmpl, err := template.New("condition").Funcs(expressionFuncs(scope)).Option("missingkey=error").Delims(stepTemplateLeftDelim, stepTemplateRightDelim).Parse(tmplStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	return tmpl, nil
+}
+
+// evaluateCondition evaluates the `if:` expression of a step
+// against the current state of the TTP execution.
+// Legacy `$forge.steps.x.outputs.y` references are
+// supported as well.
+//
+// **Parameters:**
+//
+// expr: the expression to evaluate
+//
+// **Returns:**
+//
+// bool: whether the expression evaluated to true
+// error: an error if the expression could not be evaluated
+func (c TTPExecutionContext) evaluateCondition(expr string) (bool, error) {
+	scope := c.scope()
+	tmpl, err := parseCondition(expr, scope)
+	if err != nil {
+		return false, err
+	}
+
+	var output bytes.Buffer
+	if err := tmpl.Execute(&output, scope); err != nil {
+		return false, fmt.Errorf("failed to evaluate condition %q: %w", expr, err)
+	}
+	return output.String() == "true", nil
+}
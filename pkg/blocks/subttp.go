@@ -20,6 +20,7 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"context"
 	"errors"
 	"strings"
 
@@ -58,7 +59,7 @@ func (s *SubTTPStep) IsNil() bool {
 // The TTP file path is not empty.
 // The steps within the TTP file do not contain any nested SubTTPSteps.
 // If any of these conditions are not met, an error is returned.
-func (s *SubTTPStep) Validate(execCtx TTPExecutionContext) error {
+func (s *SubTTPStep) Validate(ctx context.Context, execCtx TTPExecutionContext) error {
 	if s.TtpRef == "" {
 		return errors.New("a TTP reference is required and must not be empty")
 	}
@@ -76,11 +77,11 @@ func (s *SubTTPStep) Validate(execCtx TTPExecutionContext) error {
 // **Returns:**
 //
 // error: error if template resolution fails, nil otherwise
-func (s *SubTTPStep) Template(execCtx TTPExecutionContext) error {
+func (s *SubTTPStep) Template(ctx context.Context, execCtx TTPExecutionContext) error {
 	var err error
 
 	for key, value := range s.Args {
-		s.Args[key], err = execCtx.templateStep(value)
+		s.Args[key], err = execCtx.templateLegacyStep(value)
 		if err != nil {
 			return err
 		}
@@ -91,9 +92,20 @@ func (s *SubTTPStep) Template(execCtx TTPExecutionContext) error {
 
 // Execute runs each step of the TTP file associated with the SubTTPStep
 // and manages the outputs and cleanup steps.
-func (s *SubTTPStep) Execute(_ TTPExecutionContext) (*ActResult, error) {
+func (s *SubTTPStep) Execute(ctx context.Context, execCtx TTPExecutionContext) (*ActResult, error) {
 	logging.L().Infof("[*] Executing Sub TTP: %s", s.TtpRef)
-	runErr := s.ttp.RunSteps(*s.subExecCtx)
+	// the sub TTP is bound by the timeout of this step
+	// and its progress is recorded along with that of the parent TTP.
+	// Observers are set on the stored context so that
+	// they are also notified of the sub TTP's cleanup.
+	s.subExecCtx.observers = execCtx.observers
+	// the sub TTP inherits the environment variables set by earlier steps
+	for k, v := range execCtx.Vars.Env {
+		s.subExecCtx.Vars.setEnv(k, v)
+	}
+	subExecCtx := *s.subExecCtx
+	subExecCtx.state = execCtx.state
+	runErr := s.ttp.run(ctx, subExecCtx)
 	if runErr != nil {
 		return &ActResult{}, runErr
 	}
@@ -104,7 +116,16 @@ func (s *SubTTPStep) Execute(_ TTPExecutionContext) (*ActResult, error) {
 	for index, execResult := range s.subExecCtx.StepResults.ByIndex {
 		actResults[index] = &execResult.ActResult
 	}
-	return aggregateResults(actResults), nil
+	result := aggregateResults(actResults)
+
+	// the outputs declared by the sub TTP become
+	// the outputs of this step in the parent TTP
+	outputs, err := s.ttp.ResolveOutputs(subExecCtx)
+	if err != nil {
+		return result, err
+	}
+	result.Outputs = outputs
+	return result, nil
 }
 
 // GetDefaultCleanupAction will instruct the calling code
@@ -119,6 +140,10 @@ func aggregateResults(results []*ActResult) *ActResult {
 	var subStdouts []string
 	var subStderrs []string
 	for _, result := range results {
+		// skipped steps have no results
+		if result == nil {
+			continue
+		}
 		subStdouts = append(subStdouts, result.Stdout)
 		subStderrs = append(subStderrs, result.Stderr)
 	}
@@ -142,6 +167,20 @@ func (s *SubTTPStep) processSubTTPArgs(execCtx TTPExecutionContext) ([]string, e
 	return expandedArgKvStrs, nil
 }
 
+// staticArgs returns the arguments passed to the sub TTP whose
+// values are known before any step runs, in "ARG_NAME=ARG_VALUE" format
+func (s *SubTTPStep) staticArgs() []string {
+	var execCtx TTPExecutionContext
+	var argKvStrs []string
+	for k, v := range s.Args {
+		if execCtx.containsStepTemplating(v) || strings.Contains(v, contextVariablePrefix) {
+			continue
+		}
+		argKvStrs = append(argKvStrs, k+"="+v)
+	}
+	return argKvStrs
+}
+
 // loadSubTTP loads a TTP file into a SubTTPStep instance
 // and validates the contained steps.
 func (s *SubTTPStep) loadSubTTP(execCtx TTPExecutionContext) error {
@@ -161,6 +200,8 @@ func (s *SubTTPStep) loadSubTTP(execCtx TTPExecutionContext) error {
 		return err
 	}
 	s.ttp = ttps
+	// the debugger treats the sub TTP as a single step
+	ctx.Cfg.Debugger = nil
 	s.subExecCtx = ctx
 
 	return nil
@@ -20,6 +20,8 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"bytes"
+	"context"
 	"testing"
 
 	"github.com/facebookincubator/ttpforge/pkg/repos"
@@ -50,6 +52,28 @@ steps:
 - name: testing_sub_ttp
   inline: |
     echo -n {{ .Args.arg_number_one}} {{ .Args.arg_number_two}} {{ .Args.arg_number_three }}`),
+		"repos/a/myttps/with-outputs.yaml": []byte(`name: with-outputs
+description: test sub ttp that exports outputs
+args:
+- name: prefix
+  default: ttpforge
+outputs:
+  username: "{{ .Args.prefix }}-{[{ .Steps.make_user.Outputs.suffix }]}"
+  exit_code: "{[{ exitCode \"make_user\" }]}"
+steps:
+- name: make_user
+  inline: echo '{"suffix":"1234"}'
+  outputs:
+    suffix:
+      filters:
+      - json_path: suffix`),
+		"repos/a/myttps/bad-outputs.yaml": []byte(`name: bad-outputs
+description: test sub ttp with an output that cannot be resolved
+outputs:
+  missing: "{[{ .Steps.does_not_exist.Stdout }]}"
+steps:
+- name: only_step
+  inline: echo hello`),
 		"repos/b/" + repos.RepoConfigFileName: []byte(`ttp_search_paths: ["ttps"]`),
 		"repos/b/ttps/with/cleanup.yaml": []byte(`name: with-cleanup
 description: test sub ttp with cleanup steps
@@ -62,6 +86,15 @@ steps:
     inline: echo sub_step_2_output
     cleanup:
       inline: echo cleanup_sub_step_2`),
+		"repos/b/ttps/with/failure.yaml": []byte(`name: with-failure
+description: test sub ttp whose second step fails
+steps:
+  - name: sub_step_1
+    inline: echo sub_step_1_output
+    cleanup:
+      inline: echo cleanup_sub_step_1
+  - name: sub_step_2
+    inline: exit 1`),
 	},
 	)
 	require.NoError(t, err)
@@ -167,7 +200,7 @@ args:
 			execCtx.Vars.StepVars = tc.stepVars
 
 			// validate the step
-			err = step.Validate(execCtx)
+			err = step.Validate(context.Background(), execCtx)
 			if tc.expectValidationErr {
 				require.Error(t, err)
 				return
@@ -175,7 +208,7 @@ args:
 			require.NoError(t, err)
 
 			// template the step
-			err = step.Template(execCtx)
+			err = step.Template(context.Background(), execCtx)
 			if tc.expectTemplateError {
 				require.Error(t, err)
 				return
@@ -183,7 +216,7 @@ args:
 			require.NoError(t, err)
 
 			// execute the step
-			result, err := step.Execute(execCtx)
+			result, err := step.Execute(context.Background(), execCtx)
 			if tc.expectExecutionError {
 				require.Error(t, err)
 				return
@@ -194,3 +227,61 @@ args:
 		})
 	}
 }
+
+func TestSubTTPOutputs(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		expectedStdout string
+		wantError      bool
+	}{
+		{
+			name: "Outputs are published to the parent",
+			content: `name: parent
+steps:
+  - name: create
+    ttp: with-outputs.yaml
+    args:
+      prefix: test
+  - name: use_outputs
+    inline: echo "$forge.steps.create.outputs.username {[{ .Steps.create.Outputs.exit_code }]}"`,
+			expectedStdout: "{\"suffix\":\"1234\"}\ntest-1234 0\n",
+		},
+		{
+			name: "Unresolvable output fails the sub TTP step",
+			content: `name: parent
+steps:
+  - name: bad
+    ttp: bad-outputs.yaml`,
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repoSpec := repos.Spec{
+				Name: "default",
+				Path: "repos/a",
+			}
+			repo, err := repoSpec.Load(makeTestFsForSubTTPs(t), "")
+			require.NoError(t, err)
+
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Repo = repo
+			execCtx.Cfg.Stdout = &stdoutBuf
+			require.NoError(t, ttp.Validate(execCtx))
+
+			err = ttp.Execute(execCtx)
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+		})
+	}
+}
@@ -19,6 +19,8 @@ THE SOFTWARE.
 
 package blocks
 
+import "context"
+
 // subTTPCleanupAction ensures that individual
 // steps of the subTTP are appropriately cleaned up
 type subTTPCleanupAction struct {
@@ -32,20 +34,17 @@ func (a *subTTPCleanupAction) IsNil() bool {
 }
 
 // Validate is not needed here, as this is not a user-accessible step type
-func (a *subTTPCleanupAction) Validate(_ TTPExecutionContext) error {
+func (a *subTTPCleanupAction) Validate(_ context.Context, _ TTPExecutionContext) error {
 	return nil
 }
 
 // Template is not needed here, as this is not a user-accessible step type
-func (a *subTTPCleanupAction) Template(_ TTPExecutionContext) error {
+func (a *subTTPCleanupAction) Template(_ context.Context, _ TTPExecutionContext) error {
 	return nil
 }
 
 // Execute will cleanup the subTTP starting from the last successful step
-func (a *subTTPCleanupAction) Execute(_ TTPExecutionContext) (*ActResult, error) {
-	cleanupResults, err := a.step.ttp.startCleanupForCompletedSteps(*a.step.subExecCtx)
-	if err != nil {
-		return nil, err
-	}
-	return aggregateResults(cleanupResults), nil
+func (a *subTTPCleanupAction) Execute(ctx context.Context, _ TTPExecutionContext) (*ActResult, error) {
+	cleanupResults, err := a.step.ttp.startCleanupForCompletedSteps(ctx, *a.step.subExecCtx)
+	return aggregateResults(cleanupResults), err
 }
@@ -0,0 +1,60 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultExecutionTimeout is the timeout for steps
+// that do not specify their own `timeout:`
+const DefaultExecutionTimeout = 100 * time.Minute
+
+// parseTimeout parses a timeout, which may be given either
+// as a duration string (such as `30s` or `5m`) or as an
+// integer number of seconds
+func parseTimeout(timeout string) (time.Duration, error) {
+	timeout = strings.TrimSpace(timeout)
+	if seconds, err := strconv.Atoi(timeout); err == nil {
+		if seconds <= 0 {
+			return 0, fmt.Errorf("timeout %q must be positive", timeout)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	duration, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", timeout, err)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("timeout %q must be positive", timeout)
+	}
+	return duration, nil
+}
+
+// isDeadlineExceeded reports whether ctx was stopped by its own deadline
+// (rather than by the deadline of one of its parents)
+func isDeadlineExceeded(ctx, parent context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded) && parent.Err() == nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package blocks
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeout(t *testing.T) {
+	testCases := []struct {
+		name      string
+		timeout   string
+		expected  time.Duration
+		wantError bool
+	}{
+		{
+			name:     "Duration string",
+			timeout:  "1m30s",
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "Integer seconds",
+			timeout:  "45",
+			expected: 45 * time.Second,
+		},
+		{
+			name:      "Zero",
+			timeout:   "0s",
+			wantError: true,
+		},
+		{
+			name:      "Negative seconds",
+			timeout:   "-5",
+			wantError: true,
+		},
+		{
+			name:      "Garbage",
+			timeout:   "forever",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			timeout, err := parseTimeout(tc.timeout)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, timeout)
+		})
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	testCases := []struct {
+		name            string
+		content         string
+		expectedStdout  string
+		expectedError   string
+		wantValidateErr bool
+		maxElapsed      time.Duration
+	}{
+		{
+			name: "Step timeout kills the whole process tree",
+			content: `name: timeout_test
+steps:
+  - name: first
+    inline: echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: hangs
+    timeout: 500ms
+    inline: |
+      (sleep 30; echo orphan) &
+      sleep 30
+  - name: never
+    inline: echo never`,
+			expectedStdout: "first\ncleanup first\n",
+			expectedError:  `step "hangs" timed out after 500ms`,
+			maxElapsed:     4 * time.Second,
+		},
+		{
+			name: "TTP max_duration stops remaining steps",
+			content: `name: timeout_test
+max_duration: 1s
+steps:
+  - name: first
+    inline: sleep 0.6 && echo first
+    cleanup:
+      inline: echo cleanup first
+  - name: second
+    inline: sleep 0.6 && echo second
+    cleanup:
+      inline: echo cleanup second
+  - name: third
+    inline: echo third`,
+			expectedStdout: "first\ncleanup first\n",
+			expectedError:  "exceeded its max_duration of 1s",
+			maxElapsed:     4 * time.Second,
+		},
+		{
+			name: "Loop iterations share the step timeout",
+			content: `name: timeout_test
+steps:
+  - name: spray
+    timeout: 1
+    loop: ["0.1", "0.1", "30"]
+    inline: sleep {[{ .Loop.Item }]} && echo slept {[{ .Loop.Item }]}
+    cleanup:
+      inline: echo cleanup {[{ .Loop.Index }]}`,
+			expectedStdout: "slept 0.1\nslept 0.1\ncleanup 1\ncleanup 0\n",
+			expectedError:  `step "spray" timed out after 1s`,
+			maxElapsed:     4 * time.Second,
+		},
+		{
+			name: "Invalid step timeout",
+			content: `name: timeout_test
+steps:
+  - name: bad
+    timeout: soon
+    inline: echo bad`,
+			wantValidateErr: true,
+		},
+		{
+			name: "Invalid max_duration",
+			content: `name: timeout_test
+max_duration: -1s
+steps:
+  - name: step
+    inline: echo step`,
+			wantValidateErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			err = ttp.Validate(execCtx)
+			if tc.wantValidateErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			start := time.Now()
+			err = ttp.Execute(execCtx)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+			assert.Less(t, time.Since(start), tc.maxElapsed)
+
+			// cleanup must still run after a timeout
+			require.NoError(t, ttp.RunCleanup(execCtx))
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+		})
+	}
+}
+
+func TestExpectStepTimeout(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "example-ttps", "actions", "expect", "expect.yaml"))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name           string
+		timeout        string
+		expectedPrompt int
+		wantError      bool
+	}{
+		{
+			name: "No timeout",
+		},
+		{
+			name:           "Timeout is the number of seconds for each prompt",
+			timeout:        "5",
+			expectedPrompt: 5,
+		},
+		{
+			name:      "Duration strings are not supported",
+			timeout:   "2m",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttpContent := string(content)
+			if tc.timeout != "" {
+				ttpContent = strings.Replace(ttpContent, "    expect:\n", "    timeout: "+tc.timeout+"\n    expect:\n", 1)
+			}
+			ttp, err := RenderTemplatedTTP(ttpContent, RenderParameters{})
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, ttp.Validate(NewTTPExecutionContext()))
+
+			step := ttp.Steps[1]
+			expectStep, ok := step.action.(*ExpectStep)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedPrompt, expectStep.Timeout)
+			assert.Empty(t, step.Timeout)
+			assert.Equal(t, DefaultExecutionTimeout, step.timeout())
+		})
+	}
+}
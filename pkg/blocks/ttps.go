@@ -21,13 +21,15 @@ package blocks
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"runtime"
 	"time"
 
 	"github.com/facebookincubator/ttpforge/pkg/checks"
 	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
 	"github.com/facebookincubator/ttpforge/pkg/platforms"
 	"gopkg.in/yaml.v3"
 )
@@ -38,14 +40,61 @@ import (
 // **Attributes:**
 //
 // Environment: A map of environment variables to be set for the TTP.
+// MaxDuration: The maximum amount of time for which the steps of the TTP may run.
+// Outputs: Named values computed from the step results once all steps have run.
 // Steps: An slice of steps to be executed for the TTP.
 // WorkDir: The working directory for the TTP.
+// FilePath: The path of the file from which the TTP was loaded.
 type TTP struct {
 	PreambleFields `yaml:",inline"`
 	Environment    map[string]string `yaml:"env,flow,omitempty"`
+	MaxDuration    string            `yaml:"max_duration,omitempty"`
+	Outputs        map[string]string `yaml:"outputs,omitempty"`
 	Steps          []Step            `yaml:"steps,omitempty,flow"`
 	// Omit WorkDir, but expose for testing.
-	WorkDir string `yaml:"-"`
+	WorkDir  string `yaml:"-"`
+	FilePath string `yaml:"-"`
+
+	// node is retained so that validation errors
+	// can point to the offending part of the TTP
+	node *yaml.Node
+}
+
+// UnmarshalYAML decodes the TTP and reports any
+// unknown (for example, misspelled) fields
+func (t *TTP) UnmarshalYAML(node *yaml.Node) error {
+	// Use of this auxiliary type prevents infinite recursion
+	type ttpTmp TTP
+	var tmp ttpTmp
+	if err := node.Decode(&tmp); err != nil {
+		return err
+	}
+	// the `tests:` of a TTP are read by `ttpforge test`
+	testFields := struct {
+		Tests []TestCase `yaml:"tests"`
+	}{}
+	if err := parseutils.CheckKnownFields(node, &tmp, &testFields); err != nil {
+		return err
+	}
+	*t = TTP(tmp)
+	t.node = node
+	return nil
+}
+
+// TestCase is one of the `tests:` of a TTP, which
+// `ttpforge test` runs with the specified arguments.
+//
+// **Attributes:**
+//
+// Name: The name of the test case.
+// Description: A description of the test case.
+// Args: The arguments with which the TTP is run.
+// DryRun: Whether the TTP is run with `--dry-run`.
+type TestCase struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Args        map[string]string `yaml:"args"`
+	DryRun      bool              `yaml:"dry_run"`
 }
 
 // MitreAttack represents mappings to the MITRE ATT&CK framework.
@@ -56,9 +105,9 @@ type TTP struct {
 // Techniques: A string slice containing the MITRE ATT&CK technique(s) associated with the TTP.
 // SubTechniques: A string slice containing the MITRE ATT&CK sub-technique(s) associated with the TTP.
 type MitreAttack struct {
-	Tactics       []string `yaml:"tactics,omitempty"`
-	Techniques    []string `yaml:"techniques,omitempty"`
-	SubTechniques []string `yaml:"subtechniques,omitempty"`
+	Tactics       []string `yaml:"tactics,omitempty" json:"tactics,omitempty"`
+	Techniques    []string `yaml:"techniques,omitempty" json:"techniques,omitempty"`
+	SubTechniques []string `yaml:"subtechniques,omitempty" json:"subtechniques,omitempty"`
 }
 
 // MarshalYAML is a custom marshalling implementation for the TTP structure.
@@ -117,14 +166,30 @@ func (t *TTP) Validate(execCtx TTPExecutionContext) error {
 	// Validate preamble fields
 	err := t.PreambleFields.Validate(false)
 	if err != nil {
-		return err
+		return parseutils.ErrorAt(t.node, err)
+	}
+
+	if t.MaxDuration != "" {
+		if _, err := parseTimeout(t.MaxDuration); err != nil {
+			return parseutils.ErrorAt(parseutils.FieldNode(t.node, "max_duration"), fmt.Errorf("invalid max_duration: %w", err))
+		}
+	}
+
+	outputsNode := parseutils.FieldNode(t.node, "outputs")
+	for name, expr := range t.Outputs {
+		if name == "" {
+			return parseutils.ErrorAt(outputsNode, errors.New("TTP outputs must have a name"))
+		}
+		if err := validateExpression(expr); err != nil {
+			return parseutils.ErrorAt(parseutils.FieldNode(outputsNode, name), fmt.Errorf("invalid expression for output %q: %w", name, err))
+		}
 	}
 
 	// Validate steps
 	for _, step := range t.Steps {
 		stepCopy := step
-		if err := stepCopy.Validate(execCtx); err != nil {
-			return err
+		if err := stepCopy.Validate(context.Background(), execCtx); err != nil {
+			return parseutils.ErrorAt(step.node, err)
 		}
 	}
 	logging.L().Debug("...finished validating TTP.")
@@ -147,79 +212,231 @@ func (t *TTP) Execute(execCtx TTPExecutionContext) error {
 	return err
 }
 
+// ResolveOutputs evaluates the `outputs:` of the TTP
+// against the results of its steps. It should only be
+// called once all steps have completed successfully.
+//
+// **Parameters:**
+//
+// execCtx: the execution context that was used to run the TTP
+//
+// **Returns:**
+//
+// map[string]string: the value of each output (nil if the TTP has no outputs)
+// error: an error if an output could not be evaluated
+func (t *TTP) ResolveOutputs(execCtx TTPExecutionContext) (map[string]string, error) {
+	if len(t.Outputs) == 0 {
+		return nil, nil
+	}
+	outputs := make(map[string]string, len(t.Outputs))
+	for name, expr := range t.Outputs {
+		value, err := execCtx.templateStep(expr)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve output %q of TTP %q: %w", name, t.Name, err)
+		}
+		outputs[name] = value
+	}
+	return outputs, nil
+}
+
 // RunSteps executes all of the steps in the given TTP.
 func (t *TTP) RunSteps(execCtx TTPExecutionContext) error {
-	// go to the configuration directory for this TTP
-	changeBack, err := t.chdir()
-	if err != nil {
-		return err
+	return t.run(context.Background(), execCtx)
+}
+
+// run executes all of the steps in the given TTP within ctx
+// (such as the timeout of the step that runs it as a sub TTP)
+func (t *TTP) run(ctx context.Context, execCtx TTPExecutionContext) error {
+	execCtx.ttp = t
+	execCtx.notify(func(o Observer) { o.OnTTPStart(t) })
+	err := t.runSteps(ctx, execCtx)
+	execCtx.notify(func(o Observer) { o.OnTTPEnd(t, err) })
+	return err
+}
+
+func (t *TTP) runSteps(ctx context.Context, execCtx TTPExecutionContext) error {
+	// make metadata about this run available to step expressions
+	if execCtx.Vars != nil {
+		execCtx.Vars.Run = RunInfo{
+			TTP:       t.Name,
+			Path:      t.FilePath,
+			StartTime: time.Now(),
+		}
+	}
+
+	// stop after the current step if a shutdown signal is received
+	parentCtx := ctx
+	ctx, stopShutdown := withShutdown(parentCtx)
+	defer stopShutdown()
+
+	// bound the execution of all steps by the TTP's max_duration
+	var maxDuration time.Duration
+	if t.MaxDuration != "" {
+		var err error
+		maxDuration, err = parseTimeout(t.MaxDuration)
+		if err != nil {
+			return fmt.Errorf("invalid max_duration: %w", err)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, maxDuration)
+		defer cancel()
 	}
-	defer changeBack()
 
 	var stepError error
 	var verifyError error
 	var shutdownFlag bool
 
 	// actually run all the steps
-	for stepIdx, step := range t.Steps {
+	for stepIdx := range t.Steps {
+		// use a pointer so that state recorded during
+		// execution (such as loop iterations) is retained for cleanup
+		step := &t.Steps[stepIdx]
+		step.index = stepIdx
 		logging.DividerThin()
 		logging.L().Infof("Executing Step #%d: %q", stepIdx+1, step.Name)
-		// core execution - run the step action
-		go func(step Step) {
-			err := step.Template((execCtx))
-			if err != nil {
-				logging.L().Errorf("Error templating step %s: %v", step.Name, err)
+
+		shouldRun, err := step.ShouldRun(execCtx)
+		if err != nil {
+			stepError = err
+			break
+		}
+		if !shouldRun {
+			logging.L().Infof("Skipping Step #%d: %q - `if:` condition is false", stepIdx+1, step.Name)
+			recordSkippedStep(execCtx, t, stepIdx, step)
+			continue
+		}
+
+		if debugger := execCtx.Cfg.Debugger; debugger != nil {
+			decision := debugger.beforeStep(ctx, stepIdx, step, execCtx)
+			if decision == debugSkip {
+				logging.L().Infof("Skipping Step #%d: %q - skipped in debugger", stepIdx+1, step.Name)
+				recordSkippedStep(execCtx, t, stepIdx, step)
+				continue
 			}
-			_, err = step.Execute(execCtx)
-			if err != nil {
-				// This error was logged by the step itself
-				logging.L().Debugf("Error executing step %s: %v", step.Name, err)
+			if decision == debugCleanup {
+				logging.L().Info("[*] Skipping the remaining steps as requested in debugger")
+				break
+			}
+		}
+
+		execCtx.notify(func(o Observer) { o.OnStepStart(t, stepIdx, step) })
+		var skippedAfterFailure bool
+		var failedResult *ExecutionResult
+		for {
+			stepStartTime := time.Now()
+			var stepResult *ActResult
+			// the step is canceled if a shutdown signal is received, but we
+			// still wait for it to return so that it is never cleaned up
+			// while it is still executing
+			stepResult, stepError = step.Run(ctx, execCtx)
+			shutdownFlag = isShutdown(ctx)
+			failedResult = nil
+
+			if stepResult == nil {
+				stepResult = &ActResult{}
 			}
-		}(step)
-
-		// await one of three outcomes:
-		// 1. step execution successful
-		// 2. step execution failed
-		// 3. shutdown signal received
-		select {
-		case stepResult := <-execCtx.actionResultsChan:
-			// step execution successful - record results
+			stepResult.StartTime = stepStartTime
+			stepResult.EndTime = time.Now()
 			execResult := &ExecutionResult{
-				ActResult: *stepResult,
+				ActResult:  *stepResult,
+				Name:       step.Name,
+				Attempts:   step.attempts,
+				Error:      step.IgnoredError(),
+				Iterations: step.IterationResults(),
+				Children:   step.ChildResults(),
 			}
-			execCtx.StepResults.ByName[step.Name] = execResult
-			execCtx.StepResults.ByIndex = append(execCtx.StepResults.ByIndex, execResult)
-
-		case stepError = <-execCtx.errorsChan:
-			// this part is tricky - SubTTP steps
-			// must be cleaned up even on failure
-			// (because substeps may have succeeded)
-			// so in those cases, we need to save the result
-			// even if nil
-			if step.ShouldCleanupOnFailure() {
+			if stepError != nil {
+				// the output of the failed step is recorded once the
+				// debugger (if any) has decided how to proceed
+				execResult.Error = stepError
+				execResult.Failed = true
+				failedResult = execResult
+			}
+
+			if stepError == nil {
+				// step execution successful - record results,
+				// replacing those of an attempt that was retried
+				// in the debugger because its cleanup could not be templated
+				recordStepResult(execCtx, stepIdx, execResult)
+				// now that the results are recorded,
+				// the cleanup can reference them
+				stepError = step.templateCleanup(ctx, execCtx)
+			} else if step.ShouldCleanupOnFailure() {
+				// this part is tricky - SubTTP steps
+				// must be cleaned up even on failure
+				// (because substeps may have succeeded)
+				// so in those cases, we need to save the result
+				// even if nil
 				logging.L().Infof("[+] Cleaning up failed step %s", step.Name)
 				logging.L().Infof("[+] Full Cleanup will Run Afterward")
-				_, cleanupErr := step.Cleanup(execCtx)
+				_, cleanupErr := step.cleanupAndNotify(ctx, execCtx)
 				if cleanupErr != nil {
 					logging.L().Errorf("Error cleaning up failed step %v: %v", step.Name, cleanupErr)
 				}
 			}
 
-		case shutdownFlag = <-execCtx.shutdownChan:
-			// TODO[nesusvet]: We should propagate signal to child processes if any
-			logging.L().Warn("Shutting down due to signal received")
+			debugger := execCtx.Cfg.Debugger
+			if stepError == nil || shutdownFlag || debugger == nil {
+				break
+			}
+			decision := debugger.afterStepFailure(ctx, stepIdx, step, stepError, execCtx)
+			if decision == debugRetry {
+				if err := step.resetActions(ctx, execCtx); err != nil {
+					stepError = err
+					break
+				}
+				logging.L().Infof("Retrying Step #%d: %q as requested in debugger", stepIdx+1, step.Name)
+				continue
+			}
+			if decision == debugSkip {
+				logging.L().Warnf("Ignoring failure of Step #%d: %q as requested in debugger", stepIdx+1, step.Name)
+				stepError = nil
+				skippedAfterFailure = true
+				recordSkippedStep(execCtx, t, stepIdx, step)
+			}
+			break
 		}
+		if skippedAfterFailure {
+			continue
+		}
+		if failedResult != nil {
+			// failed steps are recorded so that their
+			// output is available to reports
+			recordStepResult(execCtx, stepIdx, failedResult)
+		}
+
+		execCtx.recordState()
 
 		// if the user specified custom success checks, run them now
 		verifyError = step.VerifyChecks()
 
+		var execResult *ExecutionResult
+		if stepError == nil {
+			execResult = execCtx.StepResults.ByIndex[stepIdx]
+		}
+		resultErr := errors.Join(stepError, verifyError)
+		execCtx.notify(func(o Observer) { o.OnStepResult(t, stepIdx, step, execResult, resultErr) })
+
+		if stepError == nil && isDeadlineExceeded(ctx, parentCtx) {
+			stepError = errors.New("no time left to run the remaining steps")
+		}
 		if stepError != nil || verifyError != nil || shutdownFlag {
 			logging.L().Debug("[*] Stopping TTP Early")
 			break
 		}
 	}
 
+	if stepError != nil && isDeadlineExceeded(ctx, parentCtx) {
+		stepError = fmt.Errorf("TTP %q exceeded its max_duration of %v: %w", t.Name, maxDuration, stepError)
+	}
+
 	logging.DividerThin()
+	if shutdownFlag {
+		if stepError != nil {
+			logging.L().Errorf("[*] Step interrupted by shutdown signal: %v", stepError)
+		}
+		return ErrShutdown
+	}
 	if stepError != nil {
 		logging.L().Errorf("[*] Error executing TTP: %v", stepError)
 		return stepError
@@ -228,13 +445,35 @@ func (t *TTP) RunSteps(execCtx TTPExecutionContext) error {
 		logging.L().Errorf("[*] Error verifying TTP: %v", verifyError)
 		return verifyError
 	}
-	if shutdownFlag {
-		return fmt.Errorf("[*] Shutting Down now")
-	}
 
 	return nil
 }
 
+// recordSkippedStep records a step that was not executed - skipped steps
+// are still recorded so that the indices of ByIndex continue to match the
+// steps of the TTP
+func recordSkippedStep(execCtx TTPExecutionContext, t *TTP, stepIdx int, step *Step) {
+	execResult := &ExecutionResult{
+		Name:    step.Name,
+		Skipped: true,
+	}
+	recordStepResult(execCtx, stepIdx, execResult)
+	execCtx.recordState()
+	execCtx.notify(func(o Observer) { o.OnStepResult(t, stepIdx, step, execResult, nil) })
+}
+
+// recordStepResult records the result of the step with the given index,
+// replacing any result that was recorded by an earlier attempt of the step
+// so that the indices of ByIndex continue to match the steps of the TTP
+func recordStepResult(execCtx TTPExecutionContext, stepIdx int, execResult *ExecutionResult) {
+	execCtx.StepResults.ByName[execResult.Name] = execResult
+	if stepIdx < len(execCtx.StepResults.ByIndex) {
+		execCtx.StepResults.ByIndex[stepIdx] = execResult
+		return
+	}
+	execCtx.StepResults.ByIndex = append(execCtx.StepResults.ByIndex, execResult)
+}
+
 // RunCleanup executes all required cleanup for steps in the given TTP.
 func (t *TTP) RunCleanup(execCtx TTPExecutionContext) error {
 	if execCtx.Cfg.NoCleanup {
@@ -247,39 +486,13 @@ func (t *TTP) RunCleanup(execCtx TTPExecutionContext) error {
 		time.Sleep(time.Duration(execCtx.Cfg.CleanupDelaySeconds) * time.Second)
 	}
 
-	// TODO[nesusvet]: We also should catch signals in clean ups
-	cleanupResults, err := t.startCleanupForCompletedSteps(execCtx)
-	if err != nil {
-		return err
-	}
+	cleanupResults, err := t.startCleanupForCompletedSteps(context.Background(), execCtx)
 	// since ByIndex and ByName both contain pointers to
 	// the same underlying struct, this will update both
 	for cleanupIdx, cleanupResult := range cleanupResults {
 		execCtx.StepResults.ByIndex[cleanupIdx].Cleanup = cleanupResult
 	}
-
-	return nil
-}
-
-func (t *TTP) chdir() (func(), error) {
-	// note: t.WorkDir may not be set in tests but should
-	// be set when actually using `ttpforge run`
-	if t.WorkDir == "" {
-		logging.L().Info("Not changing working directory in tests")
-		return func() {}, nil
-	}
-	origDir, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
-	if err := os.Chdir(t.WorkDir); err != nil {
-		return nil, err
-	}
-	return func() {
-		if err := os.Chdir(origDir); err != nil {
-			logging.L().Errorf("could not restore original directory %v: %v", origDir, err)
-		}
-	}, nil
+	return err
 }
 
 // verify that we actually meet the necessary requirements to execute this TTP
@@ -293,32 +506,71 @@ func (t *TTP) verifyPlatform() error {
 	return t.Requirements.Verify(verificationCtx)
 }
 
-func (t *TTP) startCleanupForCompletedSteps(execCtx TTPExecutionContext) ([]*ActResult, error) {
-	// go to the configuration directory for this TTP
-	changeBack, err := t.chdir()
-	if err != nil {
-		return nil, err
-	}
-	defer changeBack()
-
+func (t *TTP) startCleanupForCompletedSteps(ctx context.Context, execCtx TTPExecutionContext) ([]*ActResult, error) {
+	execCtx.ttp = t
 	logging.DividerThick()
 	n := len(execCtx.StepResults.ByIndex)
 	logging.L().Infof("CLEANING UP %v steps of TTP: %q", n, t.Name)
 	cleanupResults := make([]*ActResult, n)
+	var cleanupErrs []error
 	for cleanupIdx := n - 1; cleanupIdx >= 0; cleanupIdx-- {
-		stepToCleanup := t.Steps[cleanupIdx]
+		stepToCleanup := &t.Steps[cleanupIdx]
 		logging.DividerThin()
+		if execCtx.StepResults.ByIndex[cleanupIdx].Skipped {
+			logging.L().Infof("Not Cleaning Up Step #%d: %q - step was skipped", cleanupIdx+1, stepToCleanup.Name)
+			continue
+		}
+		if execCtx.StepResults.ByIndex[cleanupIdx].Failed {
+			// steps that must be cleaned up on failure
+			// were cleaned up as soon as they failed
+			logging.L().Infof("Not Cleaning Up Step #%d: %q - step failed", cleanupIdx+1, stepToCleanup.Name)
+			continue
+		}
 		logging.L().Infof("Cleaning Up Step #%d: %q", cleanupIdx+1, stepToCleanup.Name)
-		cleanupResult, err := stepToCleanup.Cleanup(execCtx)
+		execCtx.notify(func(o Observer) { o.OnCleanupStart(t, cleanupIdx, stepToCleanup) })
+		cleanupStartTime := time.Now()
+		cleanupResult, err := cleanupStepUnlessInterrupted(ctx, stepToCleanup, execCtx)
+		if cleanupResult != nil {
+			cleanupResult.StartTime = cleanupStartTime
+			cleanupResult.EndTime = time.Now()
+		}
+		execCtx.notify(func(o Observer) { o.OnCleanupResult(t, cleanupIdx, stepToCleanup, cleanupResult, err) })
 		// must be careful to put these in step order, not in execution (reverse) order
 		cleanupResults[cleanupIdx] = cleanupResult
 		if err != nil {
 			logging.L().Errorf("error cleaning up step: %v", err)
 			logging.L().Errorf("will continue to try to cleanup other steps")
+			cleanupErrs = append(cleanupErrs, fmt.Errorf("failed to clean up step %q: %w", stepToCleanup.Name, err))
 			continue
 		}
+		// recorded so that `ttpforge cleanup` does not clean up the step again
+		stepToCleanup.cleanedUp = true
 	}
 	logging.DividerThin()
+	if len(cleanupErrs) > 0 {
+		logging.L().Errorf("Cleanup of %d step(s) failed", len(cleanupErrs))
+		return cleanupResults, errors.Join(cleanupErrs...)
+	}
 	logging.L().Info("Finished Cleanup Successfully ✅")
 	return cleanupResults, nil
 }
+
+// cleanupStepUnlessInterrupted runs the cleanup of the step. If a shutdown
+// signal is received in the meantime, the signal is forwarded to the cleanup's
+// processes, other cleanup actions (such as HTTP requests) are canceled and
+// the step is reported as skipped once they have returned. The signal only
+// interrupts the cleanup of this step, so that a second signal does not
+// abandon the remaining cleanup steps.
+func cleanupStepUnlessInterrupted(ctx context.Context, step *Step, execCtx TTPExecutionContext) (*ActResult, error) {
+	ctx, stop := withShutdown(ctx)
+	defer stop()
+	result, err := step.Cleanup(ctx, execCtx)
+	if !isShutdown(ctx) {
+		return result, err
+	}
+	logging.L().Warnf("Signal received - skipping cleanup of step %q", step.Name)
+	if err == nil {
+		return result, nil
+	}
+	return result, fmt.Errorf("cleanup of step %q was interrupted by a signal: %w", step.Name, err)
+}
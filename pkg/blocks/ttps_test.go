@@ -20,8 +20,14 @@ THE SOFTWARE.
 package blocks
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/facebookincubator/ttpforge/pkg/testutils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -133,9 +139,10 @@ steps:
 
 func TestTTP_Validate(t *testing.T) {
 	testCases := []struct {
-		name      string
-		content   string
-		wantError bool
+		name              string
+		content           string
+		wantError         bool
+		wantValidateError bool
 	}{
 		{
 			name: "Valid steps",
@@ -152,6 +159,31 @@ steps:
 `,
 			wantError: false,
 		},
+		{
+			name: "Valid outputs",
+			content: `
+name: test
+description: this is a test
+outputs:
+  greeting: "{[{ .Steps.step1.Stdout }]} {[{ stdout \"step1\" }]}"
+steps:
+  - name: step1
+    inline: echo "step1"
+`,
+		},
+		{
+			name: "Invalid output expression",
+			content: `
+name: test
+description: this is a test
+outputs:
+  greeting: "{[{ .Steps.step1.Stdout"
+steps:
+  - name: step1
+    inline: echo "step1"
+`,
+			wantValidateError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -165,7 +197,7 @@ steps:
 			}
 
 			err = ttp.Validate(TTPExecutionContext{})
-			if tc.wantError {
+			if tc.wantError || tc.wantValidateError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
@@ -372,6 +404,124 @@ steps:
 	}
 }
 
+func TestConditionalSteps(t *testing.T) {
+	testCases := []struct {
+		name               string
+		content            string
+		args               map[string]any
+		expectedSkipped    []bool
+		expectedStdout     string
+		expectExecuteError bool
+	}{
+		{
+			name: "Conditions on args and step outputs",
+			content: `name: conditional_steps
+steps:
+  - name: step1
+    inline: echo {\"user\":\"root\"}
+    outputs:
+      user:
+        filters:
+        - json_path: user
+    cleanup:
+      inline: echo cleanup1
+  - name: step2
+    if: eq "$forge.steps.step1.outputs.user" "root"
+    inline: echo step2
+    cleanup:
+      inline: echo cleanup2
+  - name: step3
+    if: .Args.run_step3
+    inline: echo step3
+    cleanup:
+      inline: echo cleanup3
+  - name: step4
+    if: '{[{ and (not .Args.run_step3) (ne .Platform.OS "") }]}'
+    inline: echo step4
+    cleanup:
+      inline: echo cleanup4`,
+			args: map[string]any{
+				"run_step3": false,
+			},
+			expectedSkipped: []bool{false, false, true, false},
+			expectedStdout:  "{\"user\":\"root\"}\nstep2\nstep4\ncleanup4\ncleanup2\ncleanup1\n",
+		},
+		{
+			name: "Legacy references to output with quotes and newlines",
+			content: `name: conditional_steps
+steps:
+  - name: step1
+    inline: printf 'say "hi"\nbye'
+  - name: step2
+    if: 'eq "$forge.steps.step1.stdout" "say \"hi\"\nbye"'
+    inline: echo step2
+  - name: step3
+    if: 'ne $forge.steps.step1.stdout "say \"hi\"\nbye"'
+    inline: echo step3
+  - name: step4
+    if: 'eq (len $forge.steps.step1.stdout) 12'
+    inline: echo step4`,
+			args:            map[string]any{},
+			expectedSkipped: []bool{false, false, true, false},
+			expectedStdout:  "say \"hi\"\nbyestep2\nstep4\n",
+		},
+		{
+			name: "Condition referencing missing arg",
+			content: `name: conditional_steps
+steps:
+  - name: step1
+    inline: echo step1
+    cleanup:
+      inline: echo cleanup1
+  - name: step2
+    if: .Args.missing
+    inline: echo step2`,
+			args:               map[string]any{},
+			expectedSkipped:    []bool{false},
+			expectedStdout:     "step1\ncleanup1\n",
+			expectExecuteError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ttp, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			require.NoError(t, err)
+
+			var stdoutBuf bytes.Buffer
+			execCtx := NewTTPExecutionContext()
+			execCtx.Cfg.Stdout = &stdoutBuf
+			execCtx.Vars.Args = tc.args
+			require.NoError(t, ttp.Validate(execCtx))
+
+			err = ttp.Execute(execCtx)
+			if tc.expectExecuteError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.NoError(t, ttp.RunCleanup(execCtx))
+
+			require.Len(t, execCtx.StepResults.ByIndex, len(tc.expectedSkipped))
+			for idx, skipped := range tc.expectedSkipped {
+				assert.Equal(t, skipped, execCtx.StepResults.ByIndex[idx].Skipped, "step index %d", idx)
+			}
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+		})
+	}
+}
+
+func TestInvalidStepCondition(t *testing.T) {
+	content := `name: invalid_condition
+steps:
+  - name: step1
+    if: eq .Args.foo (
+    inline: echo step1`
+	ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+	require.NoError(t, err)
+	assert.Error(t, ttp.Validate(NewTTPExecutionContext()))
+}
+
 func TestMitreAttackMapping(t *testing.T) {
 	testCases := []struct {
 		name      string
@@ -423,3 +573,271 @@ mitre:
 		})
 	}
 }
+
+func TestConcurrentTTPsDoNotShareWorkDir(t *testing.T) {
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	content := `name: workdir_test
+steps:
+  - name: create
+    create_file: created.txt
+    contents: hello
+  - name: where
+    inline: pwd
+    checks:
+      - msg: file was not created in the working directory
+        path_exists: created.txt`
+
+	dirs := []string{t.TempDir(), t.TempDir()}
+	stdouts := make([]bytes.Buffer, len(dirs))
+	errs := make([]error, len(dirs))
+	var wg sync.WaitGroup
+	for idx, dir := range dirs {
+		ttp, err := RenderTemplatedTTP(content, RenderParameters{})
+		require.NoError(t, err)
+		execCtx := NewTTPExecutionContext()
+		execCtx.Cfg.Stdout = &stdouts[idx]
+		execCtx.Cfg.NoCleanup = true
+		execCtx.Vars.WorkDir = dir
+		require.NoError(t, ttp.Validate(execCtx))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[idx] = ttp.Execute(execCtx)
+		}()
+	}
+	wg.Wait()
+
+	for idx, dir := range dirs {
+		require.NoError(t, errs[idx])
+		resolvedDir, err := filepath.EvalSymlinks(dir)
+		require.NoError(t, err)
+		assert.Equal(t, resolvedDir+"\n", stdouts[idx].String())
+		assert.FileExists(t, filepath.Join(dir, "created.txt"))
+	}
+
+	// the working directory of the process is never changed
+	currentDir, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, origDir, currentDir)
+}
+
+func TestStrictDecoding(t *testing.T) {
+	testCases := []struct {
+		name               string
+		content            string
+		expectErrorMessage string
+	}{
+		{
+			name: "Misspelled Top-Level Field",
+			content: `name: strict
+stpes:
+  - name: hello
+    inline: echo hello`,
+			expectErrorMessage: `line 2, column 1: unknown field "stpes" (did you mean "steps"?)`,
+		},
+		{
+			name: "Misspelled Action Field",
+			content: `name: strict
+steps:
+  - name: create
+    create_file: foo.txt
+    contents: hello
+    overwite: true`,
+			expectErrorMessage: `line 6, column 5: could not parse action for step "create": unknown field "overwite" (did you mean "overwrite"?)`,
+		},
+		{
+			name: "Misspelled Common Step Field",
+			content: `name: strict
+steps:
+  - name: hello
+    inline: echo hello
+    timout: 5s`,
+			expectErrorMessage: `line 5, column 5: could not parse action for step "hello": unknown field "timout" (did you mean "timeout"?)`,
+		},
+		{
+			name: "Field of Another Action Type",
+			content: `name: strict
+steps:
+  - name: hello
+    inline: echo hello
+    contents: hello`,
+			expectErrorMessage: `line 5, column 5: could not parse action for step "hello": unknown field "contents"`,
+		},
+		{
+			name: "Misspelled Cleanup Field",
+			content: `name: strict
+steps:
+  - name: hello
+    inline: echo hello
+    cleanup:
+      inline: echo bye
+      executer: bash`,
+			expectErrorMessage: `line 7, column 7: could not parse cleanup action for step "hello": unknown field "executer" (did you mean "executor"?)`,
+		},
+		{
+			name: "Misspelled Check Field",
+			content: `name: strict
+steps:
+  - name: hello
+    inline: echo hello
+    checks:
+      - msg: file should exist
+        path_exist: foo.txt`,
+			expectErrorMessage: `line 7, column 9: unknown field "path_exist" (did you mean "path_exists"?)`,
+		},
+		{
+			name: "Misspelled Output Filter Field",
+			content: `name: strict
+steps:
+  - name: hello
+    inline: echo hello
+    outputs:
+      a:
+        filters:
+          - json_pth: a`,
+			expectErrorMessage: `line 8, column 13: could not parse action for step "hello": unknown field "json_pth" (did you mean "json_path"?)`,
+		},
+		{
+			name: "Missing Action Type",
+			content: `name: strict
+steps:
+  - name: nothing
+    description: does nothing`,
+			expectErrorMessage: `line 3, column 5: could not parse action for step "nothing": no action type was specified`,
+		},
+		{
+			name: "Tests Section Is Allowed",
+			content: `name: strict
+tests:
+  - name: default
+steps:
+  - name: hello
+    inline: echo hello
+    cleanup:
+      name: named cleanup
+      inline: echo bye`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := RenderTemplatedTTP(tc.content, RenderParameters{})
+			if tc.expectErrorMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectErrorMessage)
+		})
+	}
+}
+
+func TestLoadTTPErrorLocations(t *testing.T) {
+	fsys, err := testutils.MakeAferoTestFs(map[string][]byte{
+		"repos/s/" + repos.RepoConfigFileName: []byte(`ttp_search_paths: ["ttps"]`),
+		"repos/s/ttps/typo.yaml": []byte(`name: typo
+steps:
+  - name: create
+    create_file: foo.txt
+    overwite: true`),
+		"repos/s/ttps/bad-retry.yaml": []byte(`name: bad-retry
+steps:
+  - name: first
+    inline: echo first
+  - name: retried
+    inline: echo retried
+    retry:
+      attempts: 0`),
+		"repos/s/ttps/bad-output.yaml": []byte(`name: bad-output
+outputs:
+  first: "{[{ .Steps.first.Stdout"
+steps:
+  - name: first
+    inline: echo first`),
+		"repos/s/ttps/ranged-typo.yaml": []byte(`name: ranged-typo
+args:
+  - name: names
+    default: a,b,c
+steps:
+{{ range $name := splitList "," .Args.names }}
+  - name: print_{{ $name }}
+    print_str: {{ $name }}
+{{ end }}
+  - name: create
+    create_file: foo.txt
+    overwite: true`),
+		"repos/s/ttps/ranged-retry.yaml": []byte(`name: ranged-retry
+args:
+  - name: names
+    default: a,b,c
+steps:
+{{ range $name := splitList "," .Args.names }}
+  - name: print_{{ $name }}
+    print_str: {{ $name }}
+{{ end }}
+  - name: retried
+    inline: echo retried
+    retry:
+      attempts: 0`),
+		"repos/s/ttps/parent.yaml": []byte(`name: parent
+steps:
+  - name: child
+    ttp: typo.yaml`),
+	})
+	require.NoError(t, err)
+	repoSpec := repos.Spec{
+		Name: "strict",
+		Path: "repos/s",
+	}
+	repo, err := repoSpec.Load(fsys, "")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name               string
+		ttpPath            string
+		expectErrorMessage string
+	}{
+		{
+			name:               "Decoding Error",
+			ttpPath:            "repos/s/ttps/typo.yaml",
+			expectErrorMessage: `repos/s/ttps/typo.yaml:5:5: could not parse action for step "create": unknown field "overwite" (did you mean "overwrite"?)`,
+		},
+		{
+			name:               "Step Validation Error",
+			ttpPath:            "repos/s/ttps/bad-retry.yaml",
+			expectErrorMessage: `repos/s/ttps/bad-retry.yaml:5:5: step "retried" has an invalid retry policy`,
+		},
+		{
+			name:               "Output Validation Error",
+			ttpPath:            "repos/s/ttps/bad-output.yaml",
+			expectErrorMessage: `repos/s/ttps/bad-output.yaml:3:10: invalid expression for output "first"`,
+		},
+		{
+			name:               "Decoding Error After Range Block",
+			ttpPath:            "repos/s/ttps/ranged-typo.yaml",
+			expectErrorMessage: `repos/s/ttps/ranged-typo.yaml:12:5: could not parse action for step "create": unknown field "overwite"`,
+		},
+		{
+			name:               "Validation Error After Range Block",
+			ttpPath:            "repos/s/ttps/ranged-retry.yaml",
+			expectErrorMessage: `repos/s/ttps/ranged-retry.yaml:10:5: step "retried" has an invalid retry policy`,
+		},
+		{
+			name:               "Error in Sub-TTP",
+			ttpPath:            "repos/s/ttps/parent.yaml",
+			expectErrorMessage: `repos/s/ttps/typo.yaml:5:5: could not parse action for step "create"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			execCfg := TTPExecutionConfig{Repo: repo}
+			_, _, err := LoadTTP(tc.ttpPath, repo.GetFs(), &execCfg, map[string]string{}, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectErrorMessage)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package campaign
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"gopkg.in/yaml.v3"
+)
+
+// CleanupPolicy specifies when the TTPs of a campaign are cleaned up
+type CleanupPolicy string
+
+const (
+	// CleanupAfterCampaign cleans up all TTPs in reverse order
+	// once every TTP of the campaign has run (the default)
+	CleanupAfterCampaign CleanupPolicy = "after_campaign"
+	// CleanupAfterEach cleans up each TTP as soon as it has run
+	CleanupAfterEach CleanupPolicy = "after_each"
+)
+
+// Campaign is an ordered set of TTPs that are run together as one engagement.
+// The TTPs are either listed directly under `ttps:` (in which case they run
+// in sequence and each one is its own stage) or grouped into `stages:`.
+type Campaign struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description,omitempty"`
+	Cleanup     CleanupPolicy `yaml:"cleanup,omitempty"`
+	TTPs        []Entry       `yaml:"ttps,omitempty"`
+	Stages      []Stage       `yaml:"stages,omitempty"`
+
+	// FilePath is the path from which the campaign was loaded -
+	// relative TTP paths are resolved against its directory
+	FilePath string `yaml:"-"`
+}
+
+// Stage is a group of TTPs that run in sequence. If one of them fails,
+// the remaining TTPs of the stage still run, but later stages are skipped.
+type Stage struct {
+	Name string  `yaml:"name"`
+	TTPs []Entry `yaml:"ttps"`
+}
+
+// Entry is a single TTP run as part of a campaign.
+//
+// Name: identifies the entry so that later entries can reference its results -
+// defaults to the file name of the TTP without its extension
+// TTP: the TTP reference, as accepted by `ttpforge run`
+// Args: the arguments of the TTP - values may use `{[{ }]}` templates
+// to reference the results of earlier entries
+// ContinueOnError: if set, a failure of this TTP does not stop the campaign
+type Entry struct {
+	Name            string            `yaml:"name,omitempty"`
+	TTP             string            `yaml:"ttp"`
+	Args            map[string]string `yaml:"args,omitempty"`
+	ContinueOnError bool              `yaml:"continue_on_error,omitempty"`
+}
+
+// Load reads and validates the campaign file at the given path
+//
+// **Parameters:**
+//
+// path: the path to the campaign YAML file
+//
+// **Returns:**
+//
+// *Campaign: the loaded campaign
+// error: an error if the file could not be read or is invalid
+func Load(path string) (*Campaign, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid campaign file %v: %w", path, err)
+	}
+	c.FilePath = path
+	return c, nil
+}
+
+// Parse decodes and validates a campaign from YAML. Unknown
+// (for example, misspelled) fields are reported as errors.
+func Parse(contents []byte) (*Campaign, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(contents, &node); err != nil {
+		return nil, err
+	}
+	var c Campaign
+	if err := node.Decode(&c); err != nil {
+		return nil, err
+	}
+	if err := parseutils.CheckKnownFields(&node, &c); err != nil {
+		return nil, parseutils.Locate(err, "")
+	}
+	if c.Cleanup == "" {
+		c.Cleanup = CleanupAfterCampaign
+	}
+	for _, stage := range c.Stages {
+		for entryIdx := range stage.TTPs {
+			setDefaultName(&stage.TTPs[entryIdx])
+		}
+	}
+	for entryIdx := range c.TTPs {
+		setDefaultName(&c.TTPs[entryIdx])
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func setDefaultName(entry *Entry) {
+	if entry.Name != "" {
+		return
+	}
+	base := filepath.Base(entry.TTP)
+	entry.Name = strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Validate checks that the campaign is well-formed
+func (c *Campaign) Validate() error {
+	if c.Name == "" {
+		return errors.New("no name specified for campaign")
+	}
+	switch c.Cleanup {
+	case CleanupAfterCampaign, CleanupAfterEach:
+	default:
+		return fmt.Errorf("invalid cleanup policy %q - must be %v or %v", c.Cleanup, CleanupAfterCampaign, CleanupAfterEach)
+	}
+	if len(c.TTPs) > 0 && len(c.Stages) > 0 {
+		return errors.New("a campaign cannot specify both `ttps:` and `stages:`")
+	}
+
+	stages := c.stages()
+	if len(stages) == 0 {
+		return errors.New("a campaign must contain at least one TTP")
+	}
+	stageNames := make(map[string]bool)
+	entryNames := make(map[string]bool)
+	for _, stage := range stages {
+		if stage.Name == "" {
+			return errors.New("no name specified for stage")
+		}
+		if stageNames[stage.Name] {
+			return fmt.Errorf("duplicate stage name %q", stage.Name)
+		}
+		stageNames[stage.Name] = true
+		if len(stage.TTPs) == 0 {
+			return fmt.Errorf("stage %q does not contain any TTPs", stage.Name)
+		}
+		for _, entry := range stage.TTPs {
+			if entry.TTP == "" {
+				return fmt.Errorf("an entry in stage %q does not specify a TTP", stage.Name)
+			}
+			if entryNames[entry.Name] {
+				return fmt.Errorf("duplicate TTP name %q - use `name:` to distinguish entries", entry.Name)
+			}
+			entryNames[entry.Name] = true
+		}
+	}
+	return nil
+}
+
+// stages returns the stages of the campaign - TTPs listed
+// under `ttps:` are each placed in their own stage
+func (c *Campaign) stages() []Stage {
+	if len(c.Stages) > 0 {
+		return c.Stages
+	}
+	var stages []Stage
+	for _, entry := range c.TTPs {
+		stages = append(stages, Stage{
+			Name: entry.Name,
+			TTPs: []Entry{entry},
+		})
+	}
+	return stages
+}
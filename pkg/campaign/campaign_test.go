@@ -0,0 +1,158 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package campaign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		expectedStages []Stage
+		wantError      bool
+		errorContains  string
+	}{
+		{
+			name: "Sequential TTPs",
+			content: `name: sequential
+ttps:
+  - ttp: repo-a//recon/whoami.yaml
+  - name: escalate
+    ttp: repo-b//privesc.yaml
+    args:
+      user: "{[{ .TTPs.whoami.StepVars.user }]}"`,
+			expectedStages: []Stage{
+				{
+					Name: "whoami",
+					TTPs: []Entry{{Name: "whoami", TTP: "repo-a//recon/whoami.yaml"}},
+				},
+				{
+					Name: "escalate",
+					TTPs: []Entry{{
+						Name: "escalate",
+						TTP:  "repo-b//privesc.yaml",
+						Args: map[string]string{"user": "{[{ .TTPs.whoami.StepVars.user }]}"},
+					}},
+				},
+			},
+		},
+		{
+			name: "Stages",
+			content: `name: staged
+cleanup: after_each
+stages:
+  - name: recon
+    ttps:
+      - ttp: a.yaml
+      - ttp: b.yaml
+        continue_on_error: true`,
+			expectedStages: []Stage{
+				{
+					Name: "recon",
+					TTPs: []Entry{
+						{Name: "a", TTP: "a.yaml"},
+						{Name: "b", TTP: "b.yaml", ContinueOnError: true},
+					},
+				},
+			},
+		},
+		{
+			name:      "Missing Name",
+			content:   `ttps: [{ttp: a.yaml}]`,
+			wantError: true,
+		},
+		{
+			name:      "No TTPs",
+			content:   `name: empty`,
+			wantError: true,
+		},
+		{
+			name: "Both TTPs and Stages",
+			content: `name: both
+ttps: [{ttp: a.yaml}]
+stages: [{name: s, ttps: [{ttp: b.yaml}]}]`,
+			wantError: true,
+		},
+		{
+			name: "Duplicate Entry Names",
+			content: `name: dup
+ttps:
+  - ttp: repo-a//whoami.yaml
+  - ttp: repo-b//whoami.yaml`,
+			wantError: true,
+		},
+		{
+			name: "Invalid Cleanup Policy",
+			content: `name: bad-cleanup
+cleanup: never
+ttps: [{ttp: a.yaml}]`,
+			wantError: true,
+		},
+		{
+			name: "Misspelled Campaign Field",
+			content: `name: typo
+clenaup: after_each
+ttps: [{ttp: a.yaml}]`,
+			wantError:     true,
+			errorContains: `line 2, column 1: unknown field "clenaup" (did you mean "cleanup"?)`,
+		},
+		{
+			name: "Misspelled Entry Field",
+			content: `name: typo
+stages:
+  - name: s
+    ttps:
+      - ttp: a.yaml
+        continue_on_eror: true`,
+			wantError:     true,
+			errorContains: `line 6, column 9: unknown field "continue_on_eror" (did you mean "continue_on_error"?)`,
+		},
+		{
+			name: "Empty Stage",
+			content: `name: empty-stage
+stages: [{name: s}]`,
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := Parse([]byte(tc.content))
+			if tc.wantError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStages, c.stages())
+		})
+	}
+}
+
+func TestParseDefaultCleanup(t *testing.T) {
+	c, err := Parse([]byte("name: defaults\nttps: [{ttp: a.yaml}]"))
+	require.NoError(t, err)
+	assert.Equal(t, CleanupAfterCampaign, c.Cleanup)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package campaign
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+)
+
+// ReportFormatVersion is the version of the JSON campaign report format.
+// It must be incremented whenever a backward-incompatible change is
+// made to the structure of the report.
+const ReportFormatVersion = 1
+
+// Report is the combined record of all TTPs run as part of a campaign
+type Report struct {
+	FormatVersion int                `json:"format_version"`
+	Campaign      ReportCampaignInfo `json:"campaign"`
+	StartTime     time.Time          `json:"start_time"`
+	EndTime       time.Time          `json:"end_time"`
+	DurationMs    int64              `json:"duration_ms"`
+	Success       bool               `json:"success"`
+	Error         string             `json:"error,omitempty"`
+	TTPs          []ReportEntry      `json:"ttps"`
+}
+
+// ReportCampaignInfo identifies the campaign that was run
+type ReportCampaignInfo struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Path        string        `json:"path,omitempty"`
+	Cleanup     CleanupPolicy `json:"cleanup"`
+}
+
+// ReportEntry contains the results of a single TTP of the campaign.
+// Report is the execution report of the TTP, in the same format
+// as `ttpforge run --report`, and is omitted if the TTP did not run.
+type ReportEntry struct {
+	Name         string            `json:"name"`
+	Stage        string            `json:"stage"`
+	TTP          string            `json:"ttp"`
+	Args         map[string]string `json:"args,omitempty"`
+	Skipped      bool              `json:"skipped,omitempty"`
+	Success      bool              `json:"success"`
+	Error        string            `json:"error,omitempty"`
+	CleanupError string            `json:"cleanup_error,omitempty"`
+	Report       *blocks.Report    `json:"report,omitempty"`
+}
+
+func (c *Campaign) newReport(runs []*entryRun, startTime, endTime time.Time, runErr error) *Report {
+	report := &Report{
+		FormatVersion: ReportFormatVersion,
+		Campaign: ReportCampaignInfo{
+			Name:        c.Name,
+			Description: c.Description,
+			Path:        c.FilePath,
+			Cleanup:     c.Cleanup,
+		},
+		StartTime:  startTime,
+		EndTime:    endTime,
+		DurationMs: endTime.Sub(startTime).Milliseconds(),
+		Success:    runErr == nil,
+		TTPs:       []ReportEntry{},
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+	for _, run := range runs {
+		entry := ReportEntry{
+			Name:    run.entry.Name,
+			Stage:   run.stage,
+			TTP:     run.entry.TTP,
+			Args:    run.args,
+			Skipped: run.skipped,
+			Success: !run.skipped && run.err == nil,
+		}
+		if run.err != nil {
+			entry.Error = run.err.Error()
+		}
+		if run.cleanupErr != nil {
+			entry.CleanupError = run.cleanupErr.Error()
+		}
+		if run.ttp != nil {
+			entry.Report = blocks.NewReport(run.ttp, *run.execCtx, run.startTime, run.endTime, run.err)
+		}
+		report.TTPs = append(report.TTPs, entry)
+	}
+	return report
+}
+
+// WriteFile serializes the report as indented JSON
+// and writes it to the specified path.
+//
+// **Parameters:**
+//
+// path: the file to which the report should be written
+//
+// **Returns:**
+//
+// error: an error if the report could not be written
+func (r *Report) WriteFile(path string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize campaign report: %w", err)
+	}
+	if err := os.WriteFile(path, append(reportBytes, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write campaign report to %v: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package campaign
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/facebookincubator/ttpforge/pkg/logging"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+)
+
+// TTPResults exposes the results of a TTP that has run to the
+// argument templates of later entries as `{[{ .TTPs.<name> }]}`
+type TTPResults struct {
+	Outputs  map[string]string
+	StepVars map[string]string
+	Steps    map[string]StepResults
+}
+
+// StepResults exposes the results of an individual step of a TTP, such as
+// `{[{ .TTPs.recon.Steps.whoami.Outputs.user }]}`
+type StepResults struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Outputs  map[string]string
+}
+
+// entryRun tracks the execution of a single campaign entry
+type entryRun struct {
+	entry      Entry
+	stage      string
+	args       map[string]string
+	skipped    bool
+	ttp        *blocks.TTP
+	execCtx    *blocks.TTPExecutionContext
+	startTime  time.Time
+	endTime    time.Time
+	err        error
+	cleanedUp  bool
+	cleanupErr error
+}
+
+// Run executes the TTPs of the campaign and cleans them up
+// according to the campaign's cleanup policy.
+//
+// **Parameters:**
+//
+// repoCollection: used to resolve the TTP references of the campaign
+// execCfg: the configuration with which each TTP is executed
+//
+// **Returns:**
+//
+// *Report: the combined report of all TTPs of the campaign
+// error: an error if any TTP of the campaign failed
+func (c *Campaign) Run(repoCollection repos.RepoCollection, execCfg blocks.TTPExecutionConfig) (*Report, error) {
+	startTime := time.Now()
+	results := map[string]*TTPResults{}
+	var runs []*entryRun
+	var runErr error
+	var stopped, shutdown bool
+
+	for _, stage := range c.stages() {
+		stageFailed := false
+		for _, entry := range stage.TTPs {
+			run := &entryRun{
+				entry: entry,
+				stage: stage.Name,
+			}
+			runs = append(runs, run)
+			if stopped || shutdown {
+				run.skipped = true
+				continue
+			}
+
+			logging.DividerThick()
+			logging.L().Infof("CAMPAIGN %q - running TTP %q of stage %q", c.Name, entry.Name, stage.Name)
+			c.runEntry(run, repoCollection, execCfg, results)
+			if run.err != nil {
+				logging.L().Errorf("TTP %q of campaign %q failed: %v", entry.Name, c.Name, run.err)
+				if errors.Is(run.err, blocks.ErrShutdown) {
+					shutdown = true
+				}
+				if !entry.ContinueOnError {
+					stageFailed = true
+					runErr = errors.Join(runErr, fmt.Errorf("TTP %q failed: %w", entry.Name, run.err))
+				}
+			}
+			if c.Cleanup == CleanupAfterEach {
+				run.cleanup()
+			}
+		}
+		if stageFailed {
+			logging.L().Warnf("Stage %q of campaign %q failed - skipping the remaining stages", stage.Name, c.Name)
+			stopped = true
+		}
+	}
+
+	// clean up in reverse order, as within a TTP
+	for runIdx := len(runs) - 1; runIdx >= 0; runIdx-- {
+		runs[runIdx].cleanup()
+	}
+
+	return c.newReport(runs, startTime, time.Now(), runErr), runErr
+}
+
+// runEntry loads and executes the TTP of the entry and
+// records its results for use by later entries
+func (c *Campaign) runEntry(run *entryRun, repoCollection repos.RepoCollection, execCfg blocks.TTPExecutionConfig, results map[string]*TTPResults) {
+	run.startTime = time.Now()
+	defer func() {
+		run.endTime = time.Now()
+	}()
+
+	args, err := renderArgs(run.entry.Args, results)
+	if err != nil {
+		run.err = fmt.Errorf("could not render args: %w", err)
+		return
+	}
+	run.args = args
+
+	repo, ttpAbsPath, err := c.resolveTTPRef(run.entry.TTP, repoCollection)
+	if err != nil {
+		run.err = fmt.Errorf("failed to resolve TTP reference %v: %w", run.entry.TTP, err)
+		return
+	}
+
+	var argsList []string
+	for name, value := range args {
+		argsList = append(argsList, name+"="+value)
+	}
+	sort.Strings(argsList)
+
+	ttpCfg := execCfg
+	ttpCfg.Repo = repo
+	ttp, execCtx, err := blocks.LoadTTP(ttpAbsPath, repo.GetFs(), &ttpCfg, map[string]string{}, argsList)
+	if err != nil {
+		run.err = fmt.Errorf("could not load TTP at %v: %w", ttpAbsPath, err)
+		return
+	}
+	run.ttp = ttp
+	run.execCtx = execCtx
+
+	run.err = ttp.Execute(*execCtx)
+
+	ttpResults := &TTPResults{
+		StepVars: execCtx.Vars.StepVars,
+		Steps:    map[string]StepResults{},
+	}
+	if run.err == nil {
+		ttpResults.Outputs, run.err = ttp.ResolveOutputs(*execCtx)
+	}
+	for name, result := range execCtx.StepResults.ByName {
+		ttpResults.Steps[name] = StepResults{
+			Stdout:   result.Stdout,
+			Stderr:   result.Stderr,
+			ExitCode: result.ExitCode,
+			Outputs:  result.Outputs,
+		}
+	}
+	results[run.entry.Name] = ttpResults
+}
+
+// resolveTTPRef resolves the TTP reference of an entry. Relative
+// paths are looked up next to the campaign file first, and are
+// otherwise resolved exactly as by `ttpforge run`.
+func (c *Campaign) resolveTTPRef(ref string, repoCollection repos.RepoCollection) (repos.Repo, string, error) {
+	if !strings.Contains(ref, repos.RepoPrefixSep) && !filepath.IsAbs(ref) && c.FilePath != "" {
+		campaignRef := filepath.Join(filepath.Dir(c.FilePath), ref)
+		if repo, ttpAbsPath, err := repoCollection.ResolveTTPRef(campaignRef); err == nil {
+			return repo, ttpAbsPath, nil
+		}
+	}
+	return repoCollection.ResolveTTPRef(ref)
+}
+
+// cleanup runs the cleanup of the entry's TTP if it has not been run yet
+func (r *entryRun) cleanup() {
+	if r.ttp == nil || r.cleanedUp {
+		return
+	}
+	r.cleanedUp = true
+	logging.L().Infof("Cleaning up TTP %q of stage %q", r.entry.Name, r.stage)
+	r.cleanupErr = r.ttp.RunCleanup(*r.execCtx)
+	if r.cleanupErr != nil {
+		logging.L().Errorf("Failed to clean up TTP %q: %v", r.entry.Name, r.cleanupErr)
+	}
+	r.endTime = time.Now()
+}
+
+// renderArgs resolves the `{[{ }]}` templates in the argument
+// values of an entry using the results of earlier entries
+func renderArgs(args map[string]string, results map[string]*TTPResults) (map[string]string, error) {
+	data := struct {
+		TTPs map[string]*TTPResults
+	}{
+		TTPs: results,
+	}
+	rendered := make(map[string]string, len(args))
+	for name, value := range args {
+		tmpl, err := template.New(name).
+			Delims("{[{", "}]}").
+			Funcs(sprig.TxtFuncMap()).
+			Option("missingkey=error").
+			Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template in arg %q: %w", name, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("could not render arg %q: %w", name, err)
+		}
+		rendered[name] = buf.String()
+	}
+	return rendered, nil
+}
@@ -0,0 +1,278 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package campaign
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeTestRepos creates two repos containing the given TTPs
+// (keyed by repo name and TTP path) in a temporary directory
+func makeTestRepos(t *testing.T, ttps map[string]map[string]string) (repos.RepoCollection, string) {
+	baseDir := t.TempDir()
+	var specs []repos.Spec
+	for repoName, repoTTPs := range ttps {
+		repoDir := filepath.Join(baseDir, repoName)
+		require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "ttps"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, repos.RepoConfigFileName), []byte("ttp_search_paths:\n  - ttps\n"), 0644))
+		for ttpPath, content := range repoTTPs {
+			require.NoError(t, os.WriteFile(filepath.Join(repoDir, "ttps", ttpPath), []byte(content), 0644))
+		}
+		specs = append(specs, repos.Spec{Name: repoName, Path: repoDir})
+	}
+	rc, err := repos.NewRepoCollection(afero.NewOsFs(), specs, baseDir)
+	require.NoError(t, err)
+	return rc, baseDir
+}
+
+const whoamiTTP = `name: whoami
+outputs:
+  user: "{[{ .StepVars.user }]}"
+steps:
+  - name: whoami
+    inline: echo alice
+    outputvar: user
+    cleanup:
+      inline: echo cleanup whoami`
+
+const greetTTP = `name: greet
+args:
+  - name: user
+steps:
+  - name: greet
+    inline: echo hello {{ .Args.user }}
+    cleanup:
+      inline: echo cleanup greet`
+
+const failTTP = `name: fail
+steps:
+  - name: fail
+    inline: echo failing && exit 1`
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name           string
+		campaign       string
+		expectedStdout string
+		expectedStatus map[string]string
+		wantError      bool
+	}{
+		{
+			name: "Outputs Between Repos",
+			campaign: `name: chain
+ttps:
+  - ttp: repo-a//whoami.yaml
+  - ttp: repo-b//greet.yaml
+    args:
+      user: "{[{ .TTPs.whoami.Outputs.user }]}-{[{ .TTPs.whoami.Steps.whoami.ExitCode }]}"`,
+			expectedStdout: "alice\nhello alice-0\ncleanup greet\ncleanup whoami\n",
+			expectedStatus: map[string]string{"whoami": "success", "greet": "success"},
+		},
+		{
+			name: "Cleanup After Each",
+			campaign: `name: each
+cleanup: after_each
+ttps:
+  - ttp: repo-a//whoami.yaml
+  - ttp: repo-b//greet.yaml
+    args:
+      user: bob`,
+			expectedStdout: "alice\ncleanup whoami\nhello bob\ncleanup greet\n",
+			expectedStatus: map[string]string{"whoami": "success", "greet": "success"},
+		},
+		{
+			name: "Failed Stage Skips Later Stages",
+			campaign: `name: stages
+stages:
+  - name: first
+    ttps:
+      - ttp: repo-b//fail.yaml
+      - ttp: repo-a//whoami.yaml
+  - name: second
+    ttps:
+      - ttp: repo-b//greet.yaml
+        args:
+          user: bob`,
+			expectedStdout: "failing\nalice\ncleanup whoami\n",
+			expectedStatus: map[string]string{"fail": "failed", "whoami": "success", "greet": "skipped"},
+			wantError:      true,
+		},
+		{
+			name: "Continue On Error",
+			campaign: `name: continue
+ttps:
+  - ttp: repo-b//fail.yaml
+    continue_on_error: true
+  - ttp: repo-b//greet.yaml
+    args:
+      user: bob`,
+			expectedStdout: "failing\nhello bob\ncleanup greet\n",
+			expectedStatus: map[string]string{"fail": "failed", "greet": "success"},
+		},
+		{
+			name: "Missing Output Reference",
+			campaign: `name: missing
+ttps:
+  - ttp: repo-b//greet.yaml
+    args:
+      user: "{[{ .TTPs.whoami.StepVars.user }]}"`,
+			expectedStdout: "",
+			expectedStatus: map[string]string{"greet": "failed"},
+			wantError:      true,
+		},
+		{
+			name: "Relative Path",
+			campaign: `name: relative
+ttps:
+  - ttp: repo-a/ttps/whoami.yaml`,
+			expectedStdout: "alice\ncleanup whoami\n",
+			expectedStatus: map[string]string{"whoami": "success"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rc, baseDir := makeTestRepos(t, map[string]map[string]string{
+				"repo-a": {"whoami.yaml": whoamiTTP},
+				"repo-b": {"greet.yaml": greetTTP, "fail.yaml": failTTP},
+			})
+			c, err := Parse([]byte(tc.campaign))
+			require.NoError(t, err)
+			c.FilePath = filepath.Join(baseDir, "campaign.yaml")
+
+			var stdoutBuf bytes.Buffer
+			report, err := c.Run(rc, blocks.TTPExecutionConfig{Stdout: &stdoutBuf})
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedStdout, stdoutBuf.String())
+
+			require.NotNil(t, report)
+			assert.Equal(t, !tc.wantError, report.Success)
+			status := make(map[string]string)
+			for _, entry := range report.TTPs {
+				switch {
+				case entry.Skipped:
+					status[entry.Name] = "skipped"
+				case entry.Success:
+					status[entry.Name] = "success"
+				default:
+					status[entry.Name] = "failed"
+				}
+			}
+			assert.Equal(t, tc.expectedStatus, status)
+		})
+	}
+}
+
+func TestRunResolvesRelativeRefs(t *testing.T) {
+	testCases := []struct {
+		name      string
+		ref       string
+		wantError bool
+	}{
+		{
+			name: "Relative to the campaign file",
+			ref:  "../repo-a/ttps/whoami.yaml",
+		},
+		{
+			name: "Relative to the working directory",
+			ref:  "repo-a/ttps/whoami.yaml",
+		},
+		{
+			name:      "Missing TTP",
+			ref:       "repo-a/ttps/missing.yaml",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rc, baseDir := makeTestRepos(t, map[string]map[string]string{
+				"repo-a": {"whoami.yaml": whoamiTTP},
+			})
+			c, err := Parse([]byte("name: relative\nttps:\n  - ttp: " + tc.ref))
+			require.NoError(t, err)
+			c.FilePath = filepath.Join(baseDir, "campaigns", "campaign.yaml")
+
+			wd, err := os.Getwd()
+			require.NoError(t, err)
+			require.NoError(t, os.Chdir(baseDir))
+			defer func() {
+				if err := os.Chdir(wd); err != nil {
+					panic(err)
+				}
+			}()
+
+			var stdoutBuf bytes.Buffer
+			_, err = c.Run(rc, blocks.TTPExecutionConfig{Stdout: &stdoutBuf})
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alice\ncleanup whoami\n", stdoutBuf.String())
+		})
+	}
+}
+
+func TestReportWriteFile(t *testing.T) {
+	rc, _ := makeTestRepos(t, map[string]map[string]string{
+		"repo-a": {"whoami.yaml": whoamiTTP},
+		"repo-b": {"greet.yaml": greetTTP},
+	})
+	c, err := Parse([]byte(`name: report
+ttps:
+  - ttp: repo-a//whoami.yaml
+  - ttp: repo-b//greet.yaml
+    args:
+      user: "{[{ .TTPs.whoami.StepVars.user }]}"`))
+	require.NoError(t, err)
+
+	report, err := c.Run(rc, blocks.TTPExecutionConfig{Stdout: &bytes.Buffer{}})
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, report.WriteFile(reportPath))
+	reportBytes, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+
+	var parsed map[string]any
+	require.NoError(t, json.Unmarshal(reportBytes, &parsed))
+	assert.Equal(t, "report", parsed["campaign"].(map[string]any)["name"])
+	ttps := parsed["ttps"].([]any)
+	require.Len(t, ttps, 2)
+	greet := ttps[1].(map[string]any)
+	assert.Equal(t, map[string]any{"user": "alice"}, greet["args"])
+	greetReport := greet["report"].(map[string]any)
+	assert.Equal(t, "greet", greetReport["ttp"].(map[string]any)["name"])
+}
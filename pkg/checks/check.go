@@ -22,7 +22,12 @@ package checks
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
 
+	"github.com/facebookincubator/ttpforge/pkg/jsonschema"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 )
@@ -43,6 +48,14 @@ type Check struct {
 	condition Condition
 }
 
+// conditionTypes returns a new, empty instance of each
+// condition type, keyed by the field that identifies it
+func conditionTypes() map[string]Condition {
+	return map[string]Condition{
+		"path_exists": &PathExists{},
+	}
+}
+
 // Verify wraps the Verify method from the underlying condition
 func (c *Check) Verify(ctx VerificationContext) error {
 	if ctx.FileSystem == nil {
@@ -55,7 +68,10 @@ func (c *Check) Verify(ctx VerificationContext) error {
 // process to ensure that the check is decoded
 // into the correct struct type
 func (c *Check) UnmarshalYAML(node *yaml.Node) error {
+	return parseutils.ErrorAt(node, c.unmarshalYAML(node))
+}
 
+func (c *Check) unmarshalYAML(node *yaml.Node) error {
 	// Decode all of the shared fields.
 	// Use of this auxiliary type prevents infinite recursion
 	var ccf CommonCheckFields
@@ -69,10 +85,7 @@ func (c *Check) UnmarshalYAML(node *yaml.Node) error {
 		return errors.New("no msg specified for check")
 	}
 
-	candidateTypeInstances := []Condition{
-		&PathExists{},
-	}
-	for _, candidateTypeInstance := range candidateTypeInstances {
+	for _, candidateTypeInstance := range conditionTypes() {
 		err := node.Decode(candidateTypeInstance)
 		if err == nil {
 			if c.condition != nil {
@@ -90,5 +103,38 @@ func (c *Check) UnmarshalYAML(node *yaml.Node) error {
 	if c.condition == nil {
 		return fmt.Errorf("condition with msg %q did not match any valid condition type", c.Msg)
 	}
-	return nil
+	return parseutils.CheckKnownFields(node, &ccf, c.condition)
+}
+
+// JSONSchema describes the check as one
+// of the condition types, along with its msg
+func (c *Check) JSONSchema(r *jsonschema.Reflector) *jsonschema.Schema {
+	types := conditionTypes()
+	schema := &jsonschema.Schema{}
+	for _, key := range slices.Sorted(maps.Keys(types)) {
+		condSchema := r.ReflectStruct(reflect.TypeOf(types[key]))
+		maps.Copy(condSchema.Properties, r.ReflectStruct(reflect.TypeOf(c.CommonCheckFields)).Properties)
+		condSchema.Required = []string{"msg", key}
+		schema.AnyOf = append(schema.AnyOf, condSchema)
+	}
+	return schema
+}
+
+// MarshalYAML serializes the check in the same
+// format from which it is decoded by UnmarshalYAML
+func (c Check) MarshalYAML() (interface{}, error) {
+	var node yaml.Node
+	if c.condition != nil {
+		if err := node.Encode(c.condition); err != nil {
+			return nil, err
+		}
+	} else {
+		node.Kind = yaml.MappingNode
+	}
+	var msgNode yaml.Node
+	if err := msgNode.Encode(map[string]string{"msg": c.Msg}); err != nil {
+		return nil, err
+	}
+	node.Content = append(msgNode.Content, node.Content...)
+	return &node, nil
 }
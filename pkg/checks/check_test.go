@@ -33,6 +33,7 @@ func TestCheckVerify(t *testing.T) {
 		name                 string
 		contentStr           string
 		fsysContents         map[string][]byte
+		workDir              string
 		expectUnmarshalError bool
 		expectVerifyError    bool
 	}{
@@ -49,6 +50,13 @@ path_exists: does-not-exist.txt`,
 			fsysContents:      map[string][]byte{"should-exist.txt": []byte("foo")},
 			expectVerifyError: true,
 		},
+		{
+			name: "Relative Path is Resolved Against WorkDir",
+			contentStr: `msg: File does not exist,
+path_exists: should-exist.txt`,
+			fsysContents: map[string][]byte{"/ttps/should-exist.txt": []byte("foo")},
+			workDir:      "/ttps",
+		},
 		{
 			name: "path_exists + Checksum Verification (Success)",
 			contentStr: `msg: File does not exists or does not have expected content,
@@ -94,7 +102,7 @@ checksum:
 			require.NoError(t, err)
 
 			// run verification
-			err = check.Verify(VerificationContext{FileSystem: fsys})
+			err = check.Verify(VerificationContext{FileSystem: fsys, WorkDir: tc.workDir})
 			if tc.expectVerifyError {
 				require.Error(t, err)
 				return
@@ -104,3 +112,21 @@ checksum:
 	}
 
 }
+
+func TestCheckMarshalYAML(t *testing.T) {
+	content := `msg: File must exist
+path_exists: should-exist.txt
+checksum:
+  sha256: 2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae
+`
+	var check Check
+	require.NoError(t, yaml.Unmarshal([]byte(content), &check))
+
+	marshaled, err := yaml.Marshal(check)
+	require.NoError(t, err)
+	require.Contains(t, string(marshaled), "msg: File must exist\npath_exists: should-exist.txt\n")
+
+	var roundTripped Check
+	require.NoError(t, yaml.Unmarshal(marshaled, &roundTripped))
+	require.Equal(t, check, roundTripped)
+}
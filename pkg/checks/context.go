@@ -31,4 +31,7 @@ import (
 type VerificationContext struct {
 	Platform   platforms.Spec
 	FileSystem afero.Fs
+	// WorkDir (if set) is the directory against
+	// which relative paths are resolved
+	WorkDir string
 }
@@ -21,6 +21,7 @@ package checks
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/spf13/afero"
 )
@@ -29,15 +30,19 @@ import (
 // It can also verify the contents of the file against a checksum
 type PathExists struct {
 	Path     string    `yaml:"path_exists"`
-	Checksum *Checksum `yaml:"checksum"`
+	Checksum *Checksum `yaml:"checksum,omitempty"`
 }
 
 // Verify checks the condition and returns an error if it fails
 func (c *PathExists) Verify(ctx VerificationContext) error {
 	fsys := ctx.FileSystem
+	path := c.Path
+	if ctx.WorkDir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(ctx.WorkDir, path)
+	}
 
 	// basic existence check
-	exists, err := afero.Exists(fsys, c.Path)
+	exists, err := afero.Exists(fsys, path)
 	if err != nil {
 		return err
 	}
@@ -47,7 +52,7 @@ func (c *PathExists) Verify(ctx VerificationContext) error {
 
 	// verify the checksum if provided
 	if c.Checksum != nil {
-		contentBytes, err := afero.ReadFile(fsys, c.Path)
+		contentBytes, err := afero.ReadFile(fsys, path)
 		if err != nil {
 			return err
 		}
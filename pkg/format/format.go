@@ -0,0 +1,438 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package format rewrites TTP files into a canonical layout.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"github.com/facebookincubator/ttpforge/pkg/preprocess"
+	"gopkg.in/yaml.v3"
+)
+
+const indentation = 2
+
+// topLevelKeyOrder is the canonical order of the top-level keys of a TTP.
+// Unknown keys are kept (in their original order) after the known ones,
+// and `steps:` always comes last as required by preprocess.Parse.
+var topLevelKeyOrder = []string{
+	"api_version",
+	"uuid",
+	"name",
+	"description",
+	"mitre",
+	"requirements",
+	"args",
+	"tests",
+	"env",
+	"max_duration",
+	"outputs",
+}
+
+var (
+	mitreKeyOrder = []string{"tactics", "techniques", "subtechniques"}
+	argKeyOrder   = []string{"name", "description", "type", "default", "choices", "regexp"}
+)
+
+var (
+	templateRegexp        = regexp.MustCompile(`(?s)\{\{.*?\}\}`)
+	templateTokenRegexp   = regexp.MustCompile(`__TTPFORGE_TEMPLATE_(\d+)__`)
+	controlTemplateRegexp = regexp.MustCompile(`^(if|else|end|range|with|define|block|template|break|continue)\b|:?=`)
+	blockScalarRegexp     = regexp.MustCompile(`(^|[\s:-])[|>][0-9+-]*\s*(#.*)?$`)
+)
+
+// TTP formats the content of a TTP file. The result has
+//
+//   - a `---` document start marker
+//   - two-space indentation
+//   - the top-level keys in a canonical order, with `steps:` last
+//   - `name:` first and `cleanup:` last in each step
+//   - multi-line `inline:` commands and folded block
+//     scalars written as literal block scalars
+//
+// Comments and `{{ ... }}` template expressions are preserved.
+// Template expressions that take up a whole line (such as
+// `{{ range ... }}` and `{{ end }}`) keep their position
+// relative to the surrounding YAML, but may be re-indented.
+//
+// **Parameters:**
+//
+// content: the content of the TTP file
+//
+// **Returns:**
+//
+// []byte: the formatted content
+// error: an error if the TTP cannot be parsed or safely formatted
+func TTP(content []byte) ([]byte, error) {
+	header, body := splitHeader(content)
+	protected := protectTemplates(body)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(protected.content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("the TTP must be a YAML mapping")
+	}
+	root := doc.Content[0]
+	canonicalize(root)
+
+	// the document start marker is written by hand so that it
+	// comes after any leading comments (such as license headers)
+	headComment := doc.HeadComment
+	doc.HeadComment = ""
+	var encoded bytes.Buffer
+	encoder := yaml.NewEncoder(&encoded)
+	encoder.SetIndent(indentation)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	var formatted bytes.Buffer
+	formatted.Write(header)
+	if headComment != "" {
+		formatted.WriteString(headComment + "\n\n")
+	}
+	formatted.WriteString("---\n")
+	formatted.Write(encoded.Bytes())
+
+	if err := checkEquivalent(protected.content, formatted.Bytes()); err != nil {
+		return nil, err
+	}
+	result, err := protected.restore(formatted.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := preprocess.Parse(result); err != nil {
+		return nil, fmt.Errorf("formatted TTP is invalid: %w", err)
+	}
+	return result, nil
+}
+
+// protectedTTP is a TTP in which the template
+// expressions have been replaced by tokens
+//
+// **Attributes:**
+//
+// content: the TTP content with tokens in place of templates
+// templates: the replaced template expressions, indexed by token
+// verbatim: lines that provide the content of a block scalar but are
+// less indented than it (such as `{{ indent 6 .Args.contents }}`),
+// which are indented while formatting and then restored as-is
+type protectedTTP struct {
+	content   []byte
+	templates []string
+	verbatim  map[string]string
+}
+
+// protectTemplates replaces each template expression with a token,
+// so that the TTP can be parsed as YAML. Lines that only contain
+// template expressions (such as `{{ range ... }}` or `{{ end }}`)
+// are turned into comments, unless they are part of a block scalar.
+func protectTemplates(content []byte) protectedTTP {
+	p := protectedTTP{verbatim: make(map[string]string)}
+	replaced := templateRegexp.ReplaceAllFunc(content, func(match []byte) []byte {
+		p.templates = append(p.templates, string(match))
+		return []byte(templateToken(len(p.templates) - 1))
+	})
+	if len(p.templates) == 0 {
+		p.content = content
+		return p
+	}
+
+	lines := strings.Split(string(replaced), "\n")
+	// the indentation of the line that started the
+	// current block scalar, or -1 outside block scalars
+	blockParentIndent := -1
+	blockIsEmpty := false
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockParentIndent >= 0 && (indent > blockParentIndent || blockIsEmpty && isTemplateLine(trimmed)) {
+			if indent <= blockParentIndent {
+				lines[idx] = strings.Repeat(" ", blockParentIndent+indentation) + trimmed
+				p.verbatim[trimmed] = line
+			}
+			blockIsEmpty = false
+			continue
+		}
+		blockParentIndent = -1
+		if isTemplateLine(trimmed) {
+			lines[idx] = line[:indent] + "# " + trimmed
+			continue
+		}
+		if blockScalarRegexp.MatchString(line) {
+			blockParentIndent = indent
+			blockIsEmpty = true
+		}
+	}
+	p.content = []byte(strings.Join(lines, "\n"))
+	return p
+}
+
+// isTemplateLine reports whether a (trimmed) line
+// consists only of template tokens
+func isTemplateLine(trimmed string) bool {
+	return trimmed != "" && strings.TrimSpace(templateTokenRegexp.ReplaceAllString(trimmed, "")) == ""
+}
+
+func templateToken(idx int) string {
+	return fmt.Sprintf("__TTPFORGE_TEMPLATE_%d__", idx)
+}
+
+// precedingControl returns the index of the last control
+// expression before the template with the given index, or -1
+func (p protectedTTP) precedingControl(idx int) int {
+	for i := idx - 1; i >= 0; i-- {
+		if isControlTemplate(p.templates[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isControlTemplate reports whether a template expression
+// affects the rest of the template, such as a variable
+// assignment or an action like `{{ range ... }}`
+func isControlTemplate(template string) bool {
+	action := strings.TrimSpace(strings.Trim(strings.TrimSuffix(strings.TrimPrefix(template, "{{"), "}}"), "-"))
+	return controlTemplateRegexp.MatchString(action)
+}
+
+// splitHeader separates the comments (such as license headers)
+// that precede the `---` document start marker of a TTP, which
+// are kept as-is, from the rest of the TTP
+func splitHeader(content []byte) ([]byte, []byte) {
+	var offset int
+	hasComments := false
+	for offset < len(content) {
+		end := bytes.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		}
+		line := bytes.TrimSpace(content[offset : offset+end])
+		switch {
+		case bytes.Equal(line, []byte("---")):
+			if !hasComments {
+				return nil, content
+			}
+			return content[:offset], content[offset:]
+		case bytes.HasPrefix(line, []byte("#")):
+			hasComments = true
+		case len(line) > 0:
+			return nil, content
+		}
+		offset += end + 1
+	}
+	return nil, content
+}
+
+// restore reverses protectTemplates. Each template must
+// still appear exactly once and in its original order, as moving
+// a template expression could change the meaning of the TTP.
+func (p protectedTTP) restore(formatted []byte) ([]byte, error) {
+	if len(p.templates) == 0 {
+		return formatted, nil
+	}
+	matches := templateTokenRegexp.FindAllSubmatch(formatted, -1)
+	if len(matches) != len(p.templates) {
+		return nil, errors.New("cannot format the TTP without changing its template expressions")
+	}
+	// expressions such as `{{ $x := 1 }}` or `{{ if ... }}` must stay
+	// in order, and every other expression must stay in the scope of
+	// the same such expression, but may otherwise move with its key
+	lastControl := -1
+	for _, match := range matches {
+		idx, _ := strconv.Atoi(string(match[1]))
+		if isControlTemplate(p.templates[idx]) {
+			if idx < lastControl {
+				return nil, errors.New("cannot format the TTP without reordering its template expressions")
+			}
+			lastControl = idx
+		} else if p.precedingControl(idx) != lastControl {
+			return nil, errors.New("cannot format the TTP without moving expressions across template actions")
+		}
+	}
+
+	lines := strings.Split(string(formatted), "\n")
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if original, ok := p.verbatim[trimmed]; ok {
+			lines[idx] = original
+			continue
+		}
+		// lines that were turned into comments by protectTemplates
+		if !strings.HasPrefix(trimmed, "# ") {
+			continue
+		}
+		rest := strings.TrimPrefix(trimmed, "# ")
+		if isTemplateLine(rest) {
+			lines[idx] = line[:len(line)-len(strings.TrimLeft(line, " "))] + rest
+		}
+	}
+	restored := templateTokenRegexp.ReplaceAllStringFunc(strings.Join(lines, "\n"), func(token string) string {
+		idx, _ := strconv.Atoi(templateTokenRegexp.FindStringSubmatch(token)[1])
+		return p.templates[idx]
+	})
+	return []byte(restored), nil
+}
+
+// canonicalize reorders keys and sets the style of
+// multi-line strings throughout the TTP
+func canonicalize(root *yaml.Node) {
+	sortKeys(root, topLevelKeyOrder, []string{"steps"})
+	if mitre := parseutils.FieldNode(root, "mitre"); mitre != nil {
+		sortKeys(mitre, mitreKeyOrder, nil)
+	}
+	if argsNode := parseutils.FieldNode(root, "args"); argsNode != nil && argsNode.Kind == yaml.SequenceNode {
+		for _, arg := range argsNode.Content {
+			sortKeys(arg, argKeyOrder, nil)
+		}
+	}
+	canonicalizeSteps(parseutils.FieldNode(root, "steps"))
+	useLiteralStyle(root)
+	useLiteralForFolded(root)
+}
+
+// canonicalizeSteps puts `name:` first and `cleanup:` last in each step
+// (including the child steps of `parallel:` steps)
+func canonicalizeSteps(steps *yaml.Node) {
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, step := range steps.Content {
+		sortKeys(step, []string{"name"}, []string{"cleanup"})
+		canonicalizeSteps(parseutils.FieldNode(step, "parallel"))
+	}
+}
+
+// sortKeys reorders the entries of a mapping node: first the keys
+// in `first` (in that order), then any other keys in their original
+// order, and then the keys in `last`
+func sortKeys(node *yaml.Node, first []string, last []string) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	rank := func(key string) int {
+		if idx := slices.Index(first, key); idx >= 0 {
+			return idx
+		}
+		if idx := slices.Index(last, key); idx >= 0 {
+			return len(first) + 1 + idx
+		}
+		return len(first)
+	}
+	type entry struct {
+		key, value *yaml.Node
+	}
+	entries := make([]entry, 0, len(node.Content)/2)
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		entries = append(entries, entry{node.Content[idx], node.Content[idx+1]})
+	}
+	if len(entries) == 0 || hasTemplateComments(node) {
+		return
+	}
+	// comments after the last entry (such as `{{ end }}` lines)
+	// belong at the end of the mapping rather than to that entry
+	final := entries[len(entries)-1]
+	keyFoot, valueFoot := final.key.FootComment, final.value.FootComment
+	final.key.FootComment, final.value.FootComment = "", ""
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return rank(a.key.Value) - rank(b.key.Value)
+	})
+	final = entries[len(entries)-1]
+	final.key.FootComment, final.value.FootComment = keyFoot, valueFoot
+	for idx, e := range entries {
+		node.Content[2*idx] = e.key
+		node.Content[2*idx+1] = e.value
+	}
+}
+
+// hasTemplateComments reports whether any of the entries of a mapping
+// node have comments that contain template expressions - these were
+// lines such as `{{ if ... }}` and `{{ end }}`, so reordering the
+// entries could move them into or out of conditionals and loops
+func hasTemplateComments(node *yaml.Node) bool {
+	for _, child := range node.Content {
+		for _, comment := range []string{child.HeadComment, child.LineComment, child.FootComment} {
+			if templateTokenRegexp.MatchString(comment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// useLiteralStyle writes multi-line `inline:` commands as literal
+// block scalars (`|`) rather than as quoted strings with `\n` escapes
+func useLiteralStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			value := node.Content[idx+1]
+			if node.Content[idx].Value == "inline" && value.Kind == yaml.ScalarNode && strings.Contains(value.Value, "\n") {
+				value.Style = yaml.LiteralStyle
+			}
+		}
+	}
+	for _, child := range node.Content {
+		useLiteralStyle(child)
+	}
+}
+
+// useLiteralForFolded writes folded block scalars (`>`) as literal
+// ones, as the YAML encoder does not write folded scalars reliably
+// (for instance, if the value has more-indented lines)
+func useLiteralForFolded(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode && node.Style == yaml.FoldedStyle {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		useLiteralForFolded(child)
+	}
+}
+
+// checkEquivalent verifies that formatting did not
+// change the data represented by the TTP
+func checkEquivalent(original []byte, formatted []byte) error {
+	var before, after any
+	if err := yaml.Unmarshal(original, &before); err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(formatted, &after); err != nil {
+		return fmt.Errorf("formatted TTP is not valid YAML: %w", err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		return errors.New("cannot format the TTP without changing its content")
+	}
+	return nil
+}
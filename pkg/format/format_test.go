@@ -0,0 +1,281 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTTP(t *testing.T) {
+	testCases := []struct {
+		name           string
+		content        string
+		expectedOutput string
+		wantError      bool
+	}{
+		{
+			name: "Already Formatted",
+			content: `---
+api_version: 2.0
+uuid: 3a1c9a8e-4b5d-4c6e-8f70-1a2b3c4d5e6f
+name: formatted
+description: already in canonical form
+steps:
+  - name: hello
+    print_str: hello
+`,
+			expectedOutput: `---
+api_version: 2.0
+uuid: 3a1c9a8e-4b5d-4c6e-8f70-1a2b3c4d5e6f
+name: formatted
+description: already in canonical form
+steps:
+  - name: hello
+    print_str: hello
+`,
+		},
+		{
+			name: "Key Order and Indentation",
+			content: `steps:
+    - inline: echo hello
+      cleanup:
+          inline: echo bye
+      name: hello
+args:
+    - default: foo
+      name: greeting
+name: unordered
+mitre:
+    techniques:
+        - T1059 Command and Scripting Interpreter
+    tactics:
+        - TA0002 Execution
+description: keys in the wrong order
+`,
+			expectedOutput: `---
+name: unordered
+description: keys in the wrong order
+mitre:
+  tactics:
+    - TA0002 Execution
+  techniques:
+    - T1059 Command and Scripting Interpreter
+args:
+  - name: greeting
+    default: foo
+steps:
+  - name: hello
+    inline: echo hello
+    cleanup:
+      inline: echo bye
+`,
+		},
+		{
+			name: "Comments",
+			content: `# Copyright header
+# second line
+
+---
+name: comments
+# describes the TTP
+description: comments are preserved # trailing comment
+steps:
+  # the only step
+  - name: hello
+    print_str: hello
+`,
+			expectedOutput: `# Copyright header
+# second line
+
+---
+name: comments
+# describes the TTP
+description: comments are preserved # trailing comment
+steps:
+  # the only step
+  - name: hello
+    print_str: hello
+`,
+		},
+		{
+			name: "Multi-line Inline Commands",
+			content: `---
+name: inline
+description: "multi-line inline\ncommands"
+steps:
+  - name: multi
+    inline: "echo one\necho two\n"
+  - name: parallel
+    parallel:
+      - name: child
+        inline: "echo three\necho four"
+        cleanup: default
+`,
+			expectedOutput: `---
+name: inline
+description: "multi-line inline\ncommands"
+steps:
+  - name: multi
+    inline: |
+      echo one
+      echo two
+  - name: parallel
+    parallel:
+      - name: child
+        inline: |-
+          echo three
+          echo four
+        cleanup: default
+`,
+		},
+		{
+			name: "Templates",
+			content: `---
+name: templates
+description: templates are preserved
+args:
+  - name: count
+    type: int
+    default: 2
+  - name: contents
+    default: foo
+steps:
+    {{ range $i := until .Args.count }}
+  - print_str: "step {{ $i }}"
+    name: step_{{ $i }}
+    {{ end }}
+  - name: write
+    create_file: /tmp/{{ .Args.count }}
+    contents: |
+{{ indent 6 .Args.contents }}
+    cleanup: default
+  - name: block
+    inline: |
+      {{ if eq .Args.count 2 }}
+      echo two
+      {{ end }}
+`,
+			expectedOutput: `---
+name: templates
+description: templates are preserved
+args:
+  - name: count
+    type: int
+    default: 2
+  - name: contents
+    default: foo
+steps:
+  {{ range $i := until .Args.count }}
+  - print_str: "step {{ $i }}"
+    name: step_{{ $i }}
+    {{ end }}
+  - name: write
+    create_file: /tmp/{{ .Args.count }}
+    contents: |
+{{ indent 6 .Args.contents }}
+    cleanup: default
+  - name: block
+    inline: |
+      {{ if eq .Args.count 2 }}
+      echo two
+      {{ end }}
+`,
+		},
+		{
+			name: "Folded Block With More-Indented Lines",
+			content: `---
+name: folded
+description: >
+  the encoder cannot write
+  this in folded style
+steps:
+  - name: request
+    http_request: https://example.com
+    body: >
+      {
+        "key": "value"
+      }
+`,
+			expectedOutput: `---
+name: folded
+description: |
+  the encoder cannot write this in folded style
+steps:
+  - name: request
+    http_request: https://example.com
+    body: |
+      {
+        "key": "value"
+      }
+`,
+		},
+		{
+			name: "Keys Are Not Moved Across Template Actions",
+			content: `---
+name: conditional
+description: the keys of the step are not reordered
+steps:
+  - inline: echo always
+    {{ if .Args.verbose }}
+    name: conditional
+    {{ end }}
+`,
+			expectedOutput: `---
+name: conditional
+description: the keys of the step are not reordered
+steps:
+  - inline: echo always
+    {{ if .Args.verbose }}
+    name: conditional
+    {{ end }}
+`,
+		},
+		{
+			name:      "Invalid YAML",
+			content:   "name: [unterminated\nsteps:\n",
+			wantError: true,
+		},
+		{
+			name:      "Not a Mapping",
+			content:   "- just\n- a list\n",
+			wantError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			formatted, err := TTP([]byte(tc.content))
+			if tc.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, string(formatted))
+
+			// formatting must be idempotent
+			reformatted, err := TTP(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(reformatted))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package jsonschema generates JSON Schema (draft 2020-12) documents
+// from Go types, following the `yaml:` struct tags used to decode them.
+package jsonschema
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Only the keywords
+// needed to describe TTPs are supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+
+	// boolean is set for the `true` and `false` schemas
+	boolean *bool
+}
+
+// Bool returns the schema that accepts any value (true) or no value (false)
+func Bool(b bool) *Schema {
+	return &Schema{boolean: &b}
+}
+
+// MarshalJSON writes boolean schemas as `true` or `false`
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.boolean != nil {
+		return json.Marshal(*s.boolean)
+	}
+	// Use of this auxiliary type prevents infinite recursion
+	type schemaTmp Schema
+	return json.Marshal((*schemaTmp)(s))
+}
+
+// Provider is implemented by types that describe their own schema,
+// which is usually necessary if they implement yaml.Unmarshaler.
+// JSONSchema is called on a new, empty instance of the type.
+type Provider interface {
+	JSONSchema(r *Reflector) *Schema
+}
+
+var (
+	providerType = reflect.TypeOf((*Provider)(nil)).Elem()
+	yamlNodeType = reflect.TypeOf(yaml.Node{})
+)
+
+// Reflector generates schemas for Go types. Named struct types (and
+// types implementing Provider) are added to the `$defs` of the schema
+// and referenced, which also allows types to refer to themselves.
+type Reflector struct {
+	defs map[string]*Schema
+}
+
+// NewReflector creates a new Reflector with no definitions.
+func NewReflector() *Reflector {
+	return &Reflector{
+		defs: make(map[string]*Schema),
+	}
+}
+
+// Reflect returns the schema for values of type t.
+//
+// **Parameters:**
+//
+// t: the Go type into which the YAML value is decoded
+//
+// **Returns:**
+//
+// *Schema: the schema, or a reference to its definition
+func (r *Reflector) Reflect(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Name() != "" && (t.Implements(providerType) || reflect.PointerTo(t).Implements(providerType)) {
+		return r.Define(definitionName(t), func() *Schema {
+			return reflect.New(t).Interface().(Provider).JSONSchema(r)
+		})
+	}
+	if t == yamlNodeType {
+		return Bool(true)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		// YAML numbers and booleans can also be decoded into strings
+		return &Schema{Type: []string{"string", "number", "boolean"}}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: r.Reflect(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.Reflect(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.ReflectStruct(t)
+		}
+		return r.Define(definitionName(t), func() *Schema {
+			return r.ReflectStruct(t)
+		})
+	default:
+		return Bool(true)
+	}
+}
+
+// ReflectStruct returns the object schema for the fields of the
+// struct type t, even if t implements Provider - this allows
+// providers to build on the schema of their own fields. Unknown
+// properties are rejected, as they are when decoding TTPs.
+//
+// **Parameters:**
+//
+// t: a struct type (or a pointer to one)
+//
+// **Returns:**
+//
+// *Schema: the object schema
+func (r *Reflector) ReflectStruct(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: Bool(false),
+	}
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Map {
+				schema.AdditionalProperties = r.Reflect(fieldType.Elem())
+				continue
+			}
+			for propName, prop := range r.ReflectStruct(fieldType).Properties {
+				schema.Properties[propName] = prop
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		schema.Properties[name] = r.Reflect(field.Type)
+	}
+	return schema
+}
+
+// Define adds a named definition to the schema, built by calling
+// build the first time that the name is defined, and returns
+// a reference to it.
+//
+// **Parameters:**
+//
+// name: the name of the definition
+// build: creates the schema of the definition
+//
+// **Returns:**
+//
+// *Schema: a reference to the definition
+func (r *Reflector) Define(name string, build func() *Schema) *Schema {
+	if _, ok := r.defs[name]; !ok {
+		// a placeholder is stored first so that
+		// recursive references terminate
+		r.defs[name] = Bool(true)
+		r.defs[name] = build()
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// Document turns root into a complete schema document, which
+// declares its dialect and contains all of the definitions
+// created by the Reflector.
+//
+// **Parameters:**
+//
+// root: the schema of the document's root value
+//
+// **Returns:**
+//
+// *Schema: root, with `$schema` and `$defs` set
+func (r *Reflector) Document(root *Schema) *Schema {
+	root.Schema = Draft
+	if len(r.defs) > 0 {
+		root.Defs = r.defs
+	}
+	return root
+}
+
+// definitionName names a type by its package and type
+// names, such as `blocks.Step` or `args.Spec`
+func definitionName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type testLeaf struct {
+	Value  string `yaml:"value"`
+	hidden string
+}
+
+type testNode struct {
+	testLeaf `yaml:",inline"`
+	Count    int               `yaml:"count,omitempty"`
+	Ratio    float64           `yaml:"ratio"`
+	Enabled  bool              `yaml:"enabled"`
+	Tags     []string          `yaml:"tags"`
+	Labels   map[string]string `yaml:"labels"`
+	Children []*testNode       `yaml:"children"`
+	Raw      yaml.Node         `yaml:"raw"`
+	Skipped  string            `yaml:"-"`
+	Untagged string
+}
+
+type testProvider struct {
+	Field string `yaml:"field"`
+}
+
+func (p *testProvider) JSONSchema(r *Reflector) *Schema {
+	schema := r.ReflectStruct(reflect.TypeOf(p))
+	schema.Required = []string{"field"}
+	return schema
+}
+
+type testHolder struct {
+	Provided testProvider `yaml:"provided"`
+}
+
+func TestReflect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{
+			name:  "Struct fields follow YAML tags",
+			value: testNode{},
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$ref": "#/$defs/jsonschema.testNode",
+				"$defs": {
+					"jsonschema.testNode": {
+						"type": "object",
+						"properties": {
+							"value": {"type": ["string", "number", "boolean"]},
+							"count": {"type": "integer"},
+							"ratio": {"type": "number"},
+							"enabled": {"type": "boolean"},
+							"tags": {"type": "array", "items": {"type": ["string", "number", "boolean"]}},
+							"labels": {"type": "object", "additionalProperties": {"type": ["string", "number", "boolean"]}},
+							"children": {"type": "array", "items": {"$ref": "#/$defs/jsonschema.testNode"}},
+							"raw": true,
+							"untagged": {"type": ["string", "number", "boolean"]}
+						},
+						"additionalProperties": false
+					}
+				}
+			}`,
+		},
+		{
+			name:  "Providers describe themselves",
+			value: testHolder{},
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"$ref": "#/$defs/jsonschema.testHolder",
+				"$defs": {
+					"jsonschema.testHolder": {
+						"type": "object",
+						"properties": {
+							"provided": {"$ref": "#/$defs/jsonschema.testProvider"}
+						},
+						"additionalProperties": false
+					},
+					"jsonschema.testProvider": {
+						"type": "object",
+						"properties": {
+							"field": {"type": ["string", "number", "boolean"]}
+						},
+						"required": ["field"],
+						"additionalProperties": false
+					}
+				}
+			}`,
+		},
+		{
+			name:  "Values without a definition",
+			value: map[string][]int{},
+			expected: `{
+				"$schema": "https://json-schema.org/draft/2020-12/schema",
+				"type": "object",
+				"additionalProperties": {"type": "array", "items": {"type": "integer"}}
+			}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewReflector()
+			schema := r.Document(r.Reflect(reflect.TypeOf(tc.value)))
+			actual, err := json.Marshal(schema)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(actual))
+		})
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Validate checks that value conforms to the schema document s.
+// The value must be in the form produced by decoding JSON into an
+// `any` (maps, slices, strings, float64 numbers, booleans and nil).
+// Only the keywords supported by Schema are checked, and references
+// must refer to the `$defs` of s.
+//
+// **Parameters:**
+//
+// value: the decoded JSON value to validate
+//
+// **Returns:**
+//
+// error: an error describing the first violation found, including
+// the JSON pointer of the offending value, or nil if value is valid
+func (s *Schema) Validate(value any) error {
+	return validator{root: s}.validate(s, value, "")
+}
+
+// validator resolves the references of the schema document root
+type validator struct {
+	root *Schema
+}
+
+func (v validator) validate(s *Schema, value any, location string) error {
+	if s.boolean != nil {
+		if !*s.boolean {
+			return fmt.Errorf("%v: no value is allowed", pointer(location))
+		}
+		return nil
+	}
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/$defs/")
+		def := v.root.Defs[name]
+		if !ok || def == nil {
+			return fmt.Errorf("%v: unknown reference %q", pointer(location), s.Ref)
+		}
+		if err := v.validate(def, value, location); err != nil {
+			return err
+		}
+	}
+	if s.Type != nil && !matchesType(s.Type, value) {
+		return fmt.Errorf("%v: expected %v but got %v", pointer(location), s.Type, typeName(value))
+	}
+	if s.Const != nil && !reflect.DeepEqual(asJSON(s.Const), value) {
+		return fmt.Errorf("%v: expected %v", pointer(location), s.Const)
+	}
+	if s.Enum != nil && !slices.ContainsFunc(s.Enum, func(allowed any) bool {
+		return reflect.DeepEqual(asJSON(allowed), value)
+	}) {
+		return fmt.Errorf("%v: %v is not one of %v", pointer(location), value, s.Enum)
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		if err := v.validateObject(s, value, location); err != nil {
+			return err
+		}
+	case []any:
+		if s.MinItems != nil && len(value) < *s.MinItems {
+			return fmt.Errorf("%v: expected at least %d items but got %d", pointer(location), *s.MinItems, len(value))
+		}
+		if s.Items != nil {
+			for idx, item := range value {
+				if err := v.validate(s.Items, item, fmt.Sprintf("%v/%d", location, idx)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if len(s.AnyOf) > 0 {
+		return v.validateAnyOf(s.AnyOf, value, location)
+	}
+	return nil
+}
+
+func (v validator) validateObject(s *Schema, value map[string]any, location string) error {
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			return fmt.Errorf("%v: missing required property %q", pointer(location), name)
+		}
+	}
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propSchema, known := s.Properties[name]
+		if !known {
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if s.AdditionalProperties.boolean != nil && !*s.AdditionalProperties.boolean {
+				return fmt.Errorf("%v: unknown property %q", pointer(location), name)
+			}
+			propSchema = s.AdditionalProperties
+		}
+		if err := v.validate(propSchema, value[name], location+"/"+escapePointer(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAnyOf checks that value matches at least one of schemas.
+// If it matches none of them, the error of the first schema whose
+// required properties are all present is reported, since that is
+// usually the schema that the value was meant to match (such as
+// the schema of the action type of a step).
+func (v validator) validateAnyOf(schemas []*Schema, value any, location string) error {
+	var applicableErr error
+	for _, schema := range schemas {
+		err := v.validate(schema, value, location)
+		if err == nil {
+			return nil
+		}
+		if applicableErr == nil && hasRequired(schema, value) {
+			applicableErr = err
+		}
+	}
+	if applicableErr != nil {
+		return applicableErr
+	}
+	return fmt.Errorf("%v: value does not match any of the allowed schemas", pointer(location))
+}
+
+// hasRequired reports whether value is an object
+// with all of the required properties of s
+func hasRequired(s *Schema, value any) bool {
+	obj, ok := value.(map[string]any)
+	if !ok || len(s.Required) == 0 {
+		return false
+	}
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesType reports whether value has the type (or one of the types)
+// given by the `type` keyword
+func matchesType(schemaType any, value any) bool {
+	switch schemaType := schemaType.(type) {
+	case string:
+		return typeName(value) == schemaType || (schemaType == "number" && typeName(value) == "integer")
+	case []string:
+		return slices.ContainsFunc(schemaType, func(t string) bool { return matchesType(t, value) })
+	case []any:
+		return slices.ContainsFunc(schemaType, func(t any) bool { return matchesType(t, value) })
+	default:
+		return false
+	}
+}
+
+// typeName returns the JSON Schema type of a decoded JSON value
+func typeName(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// asJSON converts a Go value from the schema into
+// the form that it has once decoded from JSON
+func asJSON(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+// pointer formats a JSON pointer for use in error messages
+func pointer(location string) string {
+	if location == "" {
+		return "/"
+	}
+	return location
+}
+
+// escapePointer escapes a property name for use in a JSON pointer
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testAction struct {
+	Name   string   `yaml:"name"`
+	Inline string   `yaml:"inline"`
+	Count  int      `yaml:"count"`
+	Tags   []string `yaml:"tags"`
+}
+
+func (a *testAction) JSONSchema(r *Reflector) *Schema {
+	inline := r.ReflectStruct(reflect.TypeOf(a))
+	inline.Required = []string{"name", "inline"}
+	printStr := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{"name": {Type: "string"}, "print_str": {Type: "string"}},
+		Required:             []string{"name", "print_str"},
+		AdditionalProperties: Bool(false),
+	}
+	return &Schema{AnyOf: []*Schema{inline, printStr}}
+}
+
+type testDocument struct {
+	Kind  string        `yaml:"kind"`
+	Steps []*testAction `yaml:"steps"`
+}
+
+func TestValidate(t *testing.T) {
+	r := NewReflector()
+	schema := r.ReflectStruct(reflect.TypeOf(testDocument{}))
+	schema.Properties["kind"] = &Schema{Enum: []any{"ttp", "campaign"}}
+	schema.Required = []string{"steps"}
+	one := 1
+	schema.Properties["steps"].MinItems = &one
+	schema = r.Document(schema)
+
+	testCases := []struct {
+		name               string
+		document           string
+		expectErrorMessage string
+	}{
+		{
+			name:     "Valid Document",
+			document: `{"kind": "ttp", "steps": [{"name": "a", "inline": "id", "count": 2, "tags": ["x", 1]}, {"name": "b", "print_str": "hi"}]}`,
+		},
+		{
+			name:               "Missing Required Property",
+			document:           `{"kind": "ttp"}`,
+			expectErrorMessage: `/: missing required property "steps"`,
+		},
+		{
+			name:               "Value Not In Enum",
+			document:           `{"kind": "test", "steps": [{"name": "b", "print_str": "hi"}]}`,
+			expectErrorMessage: "/kind: test is not one of [ttp campaign]",
+		},
+		{
+			name:               "Too Few Items",
+			document:           `{"steps": []}`,
+			expectErrorMessage: "/steps: expected at least 1 items but got 0",
+		},
+		{
+			name:               "Wrong Type",
+			document:           `{"steps": [{"name": "a", "inline": "id", "count": 1.5}]}`,
+			expectErrorMessage: "/steps/0/count: expected integer but got number",
+		},
+		{
+			name:               "Unknown Property Of The Matching Branch",
+			document:           `{"steps": [{"name": "b", "print_str": "hi", "color": "red"}]}`,
+			expectErrorMessage: `/steps/0: unknown property "color"`,
+		},
+		{
+			name:               "No Matching Branch",
+			document:           `{"steps": [{"name": "c"}]}`,
+			expectErrorMessage: "/steps/0: value does not match any of the allowed schemas",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var document any
+			require.NoError(t, json.Unmarshal([]byte(tc.document), &document))
+			err := schema.Validate(document)
+			if tc.expectErrorMessage != "" {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectErrorMessage, err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
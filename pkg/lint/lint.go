@@ -0,0 +1,368 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+// Package lint implements the static checks run by `ttpforge lint`.
+// Each check is a Rule with an ID and a severity; findings can be
+// suppressed inline with a `# ttpforge-lint-ignore <rule-id>` comment.
+package lint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/args"
+	"github.com/facebookincubator/ttpforge/pkg/blocks"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"github.com/facebookincubator/ttpforge/pkg/platforms"
+	"github.com/facebookincubator/ttpforge/pkg/preprocess"
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity determines whether a finding fails the lint run
+type Severity string
+
+const (
+	// SeverityError findings cause `ttpforge lint` to exit non-zero
+	SeverityError Severity = "error"
+	// SeverityWarning findings are reported but do not fail the run
+	SeverityWarning Severity = "warning"
+)
+
+// Rule is a single lint check.
+//
+// **Attributes:**
+//
+// ID: the identifier used in output and in suppression comments
+// Severity: the severity of the findings reported by the rule
+// Description: a short explanation of what the rule checks
+// Check: reports the findings of the rule for doc - corpus contains
+// every TTP that is being linted or referenced, for rules (such as
+// duplicate-uuid) that compare TTPs with each other. Rules only need
+// to set the position and message of their findings.
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Description string
+	Check       func(doc *Document, corpus []*Document) []Finding
+}
+
+// Finding is a single problem reported by a Rule
+type Finding struct {
+	RuleID   string
+	Severity Severity
+	Path     string
+	Line     int
+	Column   int
+	Message  string
+}
+
+// String formats the finding as `path:line:column: severity [rule-id] message`
+func (f Finding) String() string {
+	location := f.Path
+	if f.Line > 0 {
+		location = fmt.Sprintf("%v:%d:%d", f.Path, f.Line, f.Column)
+	}
+	return fmt.Sprintf("%v: %v [%v] %v", location, f.Severity, f.RuleID, f.Message)
+}
+
+// Document is a TTP file prepared for linting. The TTP is rendered with
+// the default value of each argument (or a placeholder value for
+// arguments without a default). The line numbers of the nodes under
+// Root are mapped back to the lines of the file that they were rendered
+// from, so that findings point at the file even if templates add or
+// remove lines.
+//
+// **Attributes:**
+//
+// Path: the path of the TTP file
+// Repo: the repository containing the TTP, or nil if there is none
+// Content: the raw (unrendered) content of the TTP file
+// Root: the mapping node of the rendered TTP, or nil if the TTP could not be rendered
+// Preamble: the preamble fields decoded from Root
+// Err: the error encountered while rendering or decoding the TTP, if any
+// PluginPaths: the directories searched for the plugins used by the TTP
+type Document struct {
+	Path        string
+	Repo        repos.Repo
+	Content     []byte
+	Root        *yaml.Node
+	Preamble    blocks.PreambleFields
+	Err         error
+	PluginPaths []string
+
+	suppressions suppressions
+	ttp          *blocks.TTP
+	argValues    map[string]any
+}
+
+// LoadDocument reads the TTP at path and prepares it for linting.
+// Problems with the content of the TTP are recorded in the
+// Err field of the returned Document rather than returned.
+//
+// **Parameters:**
+//
+// fsys: the filesystem containing the TTP
+// path: the path of the TTP file
+// repo: the repository containing the TTP (may be nil)
+//
+// **Returns:**
+//
+// *Document: the document for the TTP
+// error: an error if the file cannot be read
+func LoadDocument(fsys afero.Fs, path string, repo repos.Repo) (*Document, error) {
+	content, err := afero.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	return NewDocument(path, content, repo), nil
+}
+
+// NewDocument prepares the given TTP content for linting.
+//
+// **Parameters:**
+//
+// path: the path of the TTP file, used to report findings
+// content: the content of the TTP file
+// repo: the repository containing the TTP (may be nil)
+//
+// **Returns:**
+//
+// *Document: the document for the TTP
+func NewDocument(path string, content []byte, repo repos.Repo) *Document {
+	doc := &Document{
+		Path:         path,
+		Repo:         repo,
+		Content:      content,
+		suppressions: parseSuppressions(content),
+	}
+	doc.Err = doc.parse()
+	return doc
+}
+
+// parse renders and decodes the TTP, setting Root and Preamble
+// whenever the rendered TTP is at least valid YAML
+func (d *Document) parse() error {
+	result, err := preprocess.Parse(d.Content)
+	if err != nil {
+		return err
+	}
+	var argsContainer struct {
+		ArgSpecs []args.Spec `yaml:"args"`
+	}
+	if err := yaml.Unmarshal(result.PreambleBytes, &argsContainer); err != nil {
+		return fmt.Errorf("failed to unmarshal YAML preamble section: %w", err)
+	}
+	specs, argKvStrs := args.WithPlaceholders(argsContainer.ArgSpecs, nil)
+	d.argValues, err = args.ParseAndValidate(specs, argKvStrs)
+	if err != nil {
+		return fmt.Errorf("failed to parse and validate arguments: %w", err)
+	}
+
+	rendered, err := blocks.RenderTemplate(string(d.Content), blocks.RenderParameters{
+		Args:     d.argValues,
+		Platform: platforms.GetCurrentPlatformSpec(),
+	})
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(rendered, &node); err != nil {
+		return err
+	}
+	// findings (and suppression comments) refer to the lines of the file
+	parseutils.RemapLines(&node, parseutils.SourceLines(d.Content, rendered))
+	if node.Kind != yaml.DocumentNode || len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+		return errors.New("the TTP must be a YAML mapping")
+	}
+	d.Root = node.Content[0]
+
+	// the preamble is decoded leniently so that the other
+	// rules can still run if the TTP itself is invalid
+	if err := d.Root.Decode(&d.Preamble); err != nil {
+		d.Preamble = blocks.PreambleFields{}
+	}
+	var ttp blocks.TTP
+	if err := d.Root.Decode(&ttp); err != nil {
+		return err
+	}
+	d.ttp = &ttp
+	return nil
+}
+
+// validate validates the decoded TTP as `ttpforge run --dry-run`
+// would, so that no plugins (or other actions) are run
+func (d *Document) validate() error {
+	workDir, err := filepath.Abs(filepath.Dir(d.Path))
+	if err != nil {
+		return err
+	}
+	execCtx := blocks.TTPExecutionContext{
+		Cfg: blocks.TTPExecutionConfig{
+			DryRun:      true,
+			Repo:        d.Repo,
+			PluginPaths: d.PluginPaths,
+		},
+		Vars: &blocks.TTPExecutionVars{
+			WorkDir:  workDir,
+			StepVars: make(map[string]string),
+			Args:     d.argValues,
+			Env:      make(map[string]string),
+		},
+		StepResults: blocks.NewStepResultsRecord(),
+	}
+	return d.ttp.Validate(execCtx)
+}
+
+// Lint runs the rules over the given documents.
+// Suppressed findings are omitted and the remaining
+// findings are sorted by path and position.
+//
+// **Parameters:**
+//
+// docs: the documents to report findings for
+// corpus: all known documents, used by rules that compare TTPs
+// rules: the rules to run
+//
+// **Returns:**
+//
+// []Finding: the findings of all rules
+func Lint(docs []*Document, corpus []*Document, rules []Rule) []Finding {
+	var findings []Finding
+	for _, doc := range docs {
+		for _, rule := range rules {
+			for _, finding := range rule.Check(doc, corpus) {
+				if doc.suppressions.suppresses(rule.ID, finding.Line) {
+					continue
+				}
+				finding.RuleID = rule.ID
+				finding.Severity = rule.Severity
+				finding.Path = doc.Path
+				findings = append(findings, finding)
+			}
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return findings
+}
+
+// CountErrors returns the number of error-severity findings
+func CountErrors(findings []Finding) int {
+	var count int
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			count++
+		}
+	}
+	return count
+}
+
+// findingAt creates a finding positioned at node
+func findingAt(node *yaml.Node, format string, a ...any) Finding {
+	return Finding{
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+// findingForError creates a finding for err, using the
+// position recorded by parseutils.ErrorAt if there is one
+func findingForError(err error) Finding {
+	var finding Finding
+	var nodeErr *parseutils.NodeError
+	if errors.As(err, &nodeErr) {
+		finding.Line = nodeErr.Line
+		finding.Column = nodeErr.Column
+	}
+	finding.Message = err.Error()
+	return finding
+}
+
+// samePath reports whether two paths refer to the same TTP file
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+var suppressionRegexp = regexp.MustCompile(`#\s*ttpforge-lint-ignore(-file)?\s+([\w-]+(?:\s*,\s*[\w-]+)*)`)
+
+// suppressions records the rules suppressed by comments
+// in a TTP file, for the whole file or for specific lines
+type suppressions struct {
+	file  map[string]bool
+	lines map[int]map[string]bool
+}
+
+// parseSuppressions finds the suppression comments in content.
+// A `# ttpforge-lint-ignore <rule-id>[,<rule-id>...]` comment
+// applies to its own line and, if it is the only thing on its line,
+// to the following line. A `# ttpforge-lint-ignore-file` comment
+// applies to the whole file.
+func parseSuppressions(content []byte) suppressions {
+	s := suppressions{
+		file:  make(map[string]bool),
+		lines: make(map[int]map[string]bool),
+	}
+	for idx, line := range bytes.Split(content, []byte("\n")) {
+		match := suppressionRegexp.FindSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		lineNum := idx + 1
+		fileWide := match[2] >= 0
+		for _, ruleID := range strings.Split(string(line[match[4]:match[5]]), ",") {
+			ruleID = strings.TrimSpace(ruleID)
+			if fileWide {
+				s.file[ruleID] = true
+				continue
+			}
+			s.add(lineNum, ruleID)
+			if len(bytes.TrimSpace(line[:match[0]])) == 0 {
+				s.add(lineNum+1, ruleID)
+			}
+		}
+	}
+	return s
+}
+
+func (s suppressions) add(line int, ruleID string) {
+	if s.lines[line] == nil {
+		s.lines[line] = make(map[string]bool)
+	}
+	s.lines[line][ruleID] = true
+}
+
+func (s suppressions) suppresses(ruleID string, line int) bool {
+	return s.file[ruleID] || s.lines[line][ruleID]
+}
@@ -0,0 +1,293 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/ttpforge/pkg/repos"
+	"github.com/facebookincubator/ttpforge/pkg/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const cleanPreamble = `---
+api_version: 2.0
+uuid: 0a1b2c3d-0000-4000-8000-000000000001
+name: clean
+description: a TTP without any findings
+mitre:
+  tactics:
+    - TA0002 Execution
+`
+
+// ruleLines summarizes findings as `rule-id:line` strings
+func ruleLines(findings []Finding) []string {
+	var result []string
+	for _, finding := range findings {
+		result = append(result, fmt.Sprintf("%v:%02d", finding.RuleID, finding.Line))
+	}
+	return result
+}
+
+func TestLintRules(t *testing.T) {
+	testCases := []struct {
+		name             string
+		content          string
+		expectedFindings []string
+	}{
+		{
+			name: "Clean TTP",
+			content: cleanPreamble + `args:
+  - name: target
+steps:
+  - name: hello
+    inline: echo {{ .Args.target }}
+  - name: make_file
+    create_file: /tmp/lint-test
+    contents: hello
+    cleanup: default
+`,
+		},
+		{
+			name: "Missing Preamble Fields",
+			content: `---
+name: bare
+description: no uuid or mitre mapping
+steps:
+  - name: hello
+    print_str: hello
+`,
+			expectedFindings: []string{"missing-uuid:02", "missing-mitre:02"},
+		},
+		{
+			name: "Invalid UUID and Empty Mitre Mapping",
+			content: `---
+uuid: not-a-uuid
+name: bad
+description: bad preamble
+mitre:
+  techniques:
+    - T1059 Command and Scripting Interpreter
+steps:
+  - name: hello
+    print_str: hello
+`,
+			expectedFindings: []string{"missing-uuid:02", "missing-mitre:06"},
+		},
+		{
+			name: "Nocommit Placeholders",
+			content: cleanPreamble + `steps:
+  - name: "@nocommit"
+    inline: echo @nocommit
+`,
+			expectedFindings: []string{"nocommit:10", "nocommit:11"},
+		},
+		{
+			name: "Invalid Step",
+			content: cleanPreamble + `steps:
+  - name: hello
+    print_str: hello
+  - name: bad_timeout
+    inline: echo hello
+    timeout: soon
+`,
+			expectedFindings: []string{"invalid-ttp:12"},
+		},
+		{
+			name: "Plugins Are Not Run",
+			content: cleanPreamble + `steps:
+  - name: run_plugin
+    plugin: does-not-exist
+`,
+			expectedFindings: []string{"invalid-ttp:10"},
+		},
+		{
+			name: "Missing Cleanup",
+			content: cleanPreamble + `steps:
+  - name: make_file
+    create_file: /tmp/lint-test
+    contents: hello
+  - name: group
+    parallel:
+      - name: fetch
+        fetch_uri: https://example.com
+        location: /tmp/lint-fetch
+      - name: fetch_with_cleanup
+        fetch_uri: https://example.com
+        location: /tmp/lint-fetch2
+        cleanup: default
+`,
+			expectedFindings: []string{"missing-cleanup:10", "missing-cleanup:15"},
+		},
+		{
+			name: "Unused Args",
+			content: cleanPreamble + `args:
+  - name: used_in_template
+  - name: used_with_index
+  - name: used_in_loop
+    type: list
+    default: a,b
+  - name: unused
+    default: foo
+steps:
+  - name: hello
+    inline: echo {{ .Args.used_in_template }} {{ index .Args "used_with_index" }}
+  - name: loop
+    for_each:
+      arg: used_in_loop
+    print_str: "{[{ .Loop.Item }]}"
+`,
+			expectedFindings: []string{"unused-arg:15"},
+		},
+		{
+			name: "Invalid Platform",
+			content: cleanPreamble + `requirements:
+  platforms:
+    - os: linux
+    - os: plan10
+steps:
+  - name: hello
+    print_str: hello
+`,
+			expectedFindings: []string{"invalid-platform:12"},
+		},
+		{
+			name: "Unknown Field",
+			content: cleanPreamble + `steps:
+  - name: hello
+    print_str: hello
+    contents: oops
+`,
+			expectedFindings: []string{"invalid-ttp:12"},
+		},
+		{
+			name: "Required Args Use Placeholders",
+			content: cleanPreamble + `args:
+  - name: count
+    type: int
+  - name: mode
+    choices: [fast, slow]
+steps:
+  - name: hello
+    print_str: "{{ add .Args.count 1 }} {{ .Args.mode }}"
+`,
+		},
+		{
+			name: "Suppressions",
+			content: `---
+# ttpforge-lint-ignore-file missing-mitre
+uuid: 0a1b2c3d-0000-4000-8000-000000000002
+name: suppressed
+description: findings suppressed by comments
+steps:
+  # ttpforge-lint-ignore missing-cleanup
+  - name: make_file
+    create_file: /tmp/lint-test
+    contents: hello
+  - name: make_another_file # ttpforge-lint-ignore missing-cleanup, nocommit
+    create_file: /tmp/lint-test2
+    contents: "@nocommit"
+  - name: not_suppressed
+    create_file: /tmp/lint-test3
+    contents: hello
+`,
+			expectedFindings: []string{"nocommit:13", "missing-cleanup:14"},
+		},
+		{
+			name: "Templates Removing Lines",
+			content: cleanPreamble + `args:
+  - name: verbose
+    type: bool
+    default: false
+steps:
+{{ if .Args.verbose }}
+  - name: debug
+    print_str: verbose
+{{ end }}
+  # ttpforge-lint-ignore missing-cleanup
+  - name: make_file
+    create_file: /tmp/lint-test
+    contents: hello
+  - name: not_suppressed
+    create_file: /tmp/lint-test2
+    contents: hello
+`,
+			expectedFindings: []string{"missing-cleanup:22"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := NewDocument("ttp.yaml", []byte(tc.content), nil)
+			findings := Lint([]*Document{doc}, []*Document{doc}, DefaultRules())
+			assert.Equal(t, tc.expectedFindings, ruleLines(findings))
+		})
+	}
+}
+
+func TestLintAcrossRepo(t *testing.T) {
+	ttpWithUUID := func(id string) []byte {
+		return []byte(`---
+uuid: ` + id + `
+name: repo ttp
+description: a TTP in a repo
+mitre:
+  tactics:
+    - TA0002 Execution
+steps:
+  - name: sub
+    ttp: //does/not/exist.yaml
+  - name: templated
+    ttp: "{[{ .StepVars.ref }]}"
+  - name: found
+    ttp: //b.yaml
+`)
+	}
+	fsys, err := testutils.MakeAferoTestFs(map[string][]byte{
+		"repo/" + repos.RepoConfigFileName: []byte("ttp_search_paths: [ttps]"),
+		"repo/ttps/a.yaml":                 ttpWithUUID("0a1b2c3d-0000-4000-8000-000000000003"),
+		"repo/ttps/b.yaml":                 ttpWithUUID("0A1B2C3D-0000-4000-8000-000000000003"),
+		"repo/ttps/c.yaml":                 ttpWithUUID("0a1b2c3d-0000-4000-8000-000000000004"),
+	})
+	require.NoError(t, err)
+	spec := repos.Spec{Name: "test", Path: "repo"}
+	repo, err := spec.Load(fsys, "")
+	require.NoError(t, err)
+
+	var corpus []*Document
+	for _, name := range []string{"a", "b", "c"} {
+		doc, err := LoadDocument(fsys, "repo/ttps/"+name+".yaml", repo)
+		require.NoError(t, err)
+		corpus = append(corpus, doc)
+	}
+
+	findings := Lint(corpus[:1], corpus, DefaultRules())
+	require.Len(t, findings, 2)
+	assert.Equal(t, "repo/ttps/a.yaml:2:7: error [duplicate-uuid] uuid 0a1b2c3d-0000-4000-8000-000000000003 is also used by repo/ttps/b.yaml", findings[0].String())
+	assert.Equal(t, "unresolved-sub-ttp", findings[1].RuleID)
+	assert.Equal(t, 10, findings[1].Line)
+	assert.Equal(t, 1, CountErrors(findings[1:]))
+
+	// c.yaml has a unique uuid
+	findings = Lint(corpus[2:], corpus, DefaultRules())
+	assert.Equal(t, []string{"unresolved-sub-ttp:10"}, ruleLines(findings))
+}
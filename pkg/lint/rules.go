@@ -0,0 +1,339 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
+	"github.com/facebookincubator/ttpforge/pkg/platforms"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// nocommitPlaceholder is the value used by `ttpforge create ttp`
+// for fields that the author still needs to fill in
+const nocommitPlaceholder = "@nocommit"
+
+// statefulActions are the action types that leave files behind
+// unless the step that uses them has a cleanup
+var statefulActions = []string{"create_file", "copy_path", "edit_file", "fetch_uri"}
+
+// DefaultRules returns the rules run by `ttpforge lint`
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			ID:          "invalid-ttp",
+			Severity:    SeverityError,
+			Description: "the TTP cannot be rendered, decoded or validated (for example, because of an unknown field or an invalid step)",
+			Check:       checkInvalidTTP,
+		},
+		{
+			ID:          "missing-uuid",
+			Severity:    SeverityError,
+			Description: "the TTP has no uuid, or its uuid is not a valid UUID",
+			Check:       checkMissingUUID,
+		},
+		{
+			ID:          "duplicate-uuid",
+			Severity:    SeverityError,
+			Description: "the uuid of the TTP is also used by another TTP",
+			Check:       checkDuplicateUUID,
+		},
+		{
+			ID:          "missing-mitre",
+			Severity:    SeverityWarning,
+			Description: "the TTP has no MITRE ATT&CK mapping, or the mapping has no tactics",
+			Check:       checkMissingMitre,
+		},
+		{
+			ID:          "nocommit",
+			Severity:    SeverityError,
+			Description: "the TTP still contains " + nocommitPlaceholder + " placeholders from `ttpforge create ttp`",
+			Check:       checkNocommit,
+		},
+		{
+			ID:          "missing-cleanup",
+			Severity:    SeverityWarning,
+			Description: "a step creates files (" + strings.Join(statefulActions, ", ") + ") but has no cleanup",
+			Check:       checkMissingCleanup,
+		},
+		{
+			ID:          "unresolved-sub-ttp",
+			Severity:    SeverityError,
+			Description: "a `ttp:` step references a TTP that cannot be found in the repository",
+			Check:       checkUnresolvedSubTTP,
+		},
+		{
+			ID:          "unused-arg",
+			Severity:    SeverityWarning,
+			Description: "an argument is declared but never referenced",
+			Check:       checkUnusedArg,
+		},
+		{
+			ID:          "invalid-platform",
+			Severity:    SeverityError,
+			Description: "a platform in the requirements has an unknown os or arch",
+			Check:       checkInvalidPlatform,
+		},
+	}
+}
+
+func checkInvalidTTP(doc *Document, _ []*Document) []Finding {
+	if doc.Err != nil {
+		return []Finding{findingForError(doc.Err)}
+	}
+	// problems with the preamble are reported by the missing-mitre and
+	// invalid-platform rules, and validating a TTP loads its sub-TTPs,
+	// so TTPs with sub-TTPs that cannot be found (which are reported
+	// by the unresolved-sub-ttp rule) are not validated either.
+	// Validation also checks that executors are installed, so TTPs
+	// for other platforms are only decoded.
+	if doc.Preamble.Validate(false) != nil || !subTTPsResolve(doc) || !supportsCurrentPlatform(doc) {
+		return nil
+	}
+	if err := doc.validate(); err != nil {
+		return []Finding{findingForError(err)}
+	}
+	return nil
+}
+
+func checkMissingUUID(doc *Document, _ []*Document) []Finding {
+	if doc.Root == nil {
+		return nil
+	}
+	uuidNode := parseutils.FieldNode(doc.Root, "uuid")
+	if uuidNode == nil {
+		return []Finding{findingAt(doc.Root, "the TTP has no uuid")}
+	}
+	if _, err := uuid.Parse(uuidNode.Value); err != nil {
+		return []Finding{findingAt(uuidNode, "invalid uuid %q", uuidNode.Value)}
+	}
+	return nil
+}
+
+func checkDuplicateUUID(doc *Document, corpus []*Document) []Finding {
+	if doc.Root == nil {
+		return nil
+	}
+	uuidNode := parseutils.FieldNode(doc.Root, "uuid")
+	if uuidNode == nil {
+		return nil
+	}
+	id, err := uuid.Parse(uuidNode.Value)
+	if err != nil {
+		return nil
+	}
+
+	var others []string
+	for _, other := range corpus {
+		if samePath(other.Path, doc.Path) {
+			continue
+		}
+		if otherID, err := uuid.Parse(other.Preamble.UUID); err == nil && otherID == id {
+			others = append(others, other.Path)
+		}
+	}
+	if len(others) == 0 {
+		return nil
+	}
+	sort.Strings(others)
+	return []Finding{findingAt(uuidNode, "uuid %v is also used by %v", id, strings.Join(others, ", "))}
+}
+
+func checkMissingMitre(doc *Document, _ []*Document) []Finding {
+	if doc.Root == nil {
+		return nil
+	}
+	mitreNode := parseutils.FieldNode(doc.Root, "mitre")
+	if mitreNode == nil {
+		return []Finding{findingAt(doc.Root, "the TTP has no MITRE ATT&CK mapping (`mitre:`)")}
+	}
+	if doc.Preamble.MitreAttackMapping == nil || len(doc.Preamble.MitreAttackMapping.Tactics) == 0 {
+		return []Finding{findingAt(mitreNode, "the MITRE ATT&CK mapping has no tactics")}
+	}
+	return nil
+}
+
+func checkNocommit(doc *Document, _ []*Document) []Finding {
+	var findings []Finding
+	for idx, line := range bytes.Split(doc.Content, []byte("\n")) {
+		if col := bytes.Index(line, []byte(nocommitPlaceholder)); col >= 0 {
+			findings = append(findings, Finding{
+				Line:    idx + 1,
+				Column:  col + 1,
+				Message: fmt.Sprintf("replace the %v placeholder before committing the TTP", nocommitPlaceholder),
+			})
+		}
+	}
+	return findings
+}
+
+func checkMissingCleanup(doc *Document, _ []*Document) []Finding {
+	var findings []Finding
+	forEachStep(doc.Root, func(step *yaml.Node) {
+		if parseutils.FieldNode(step, "cleanup") != nil {
+			return
+		}
+		for _, actionType := range statefulActions {
+			if parseutils.FieldNode(step, actionType) != nil {
+				findings = append(findings, findingAt(step, "step %q uses %v but has no cleanup - add `cleanup: default` or a custom cleanup", stepName(step), actionType))
+				return
+			}
+		}
+	})
+	return findings
+}
+
+func checkUnresolvedSubTTP(doc *Document, _ []*Document) []Finding {
+	// sub-TTPs are resolved relative to the repository of the TTP
+	if doc.Repo == nil {
+		return nil
+	}
+	var findings []Finding
+	forEachStep(doc.Root, func(step *yaml.Node) {
+		refNode := parseutils.FieldNode(step, "ttp")
+		if refNode == nil || refNode.Kind != yaml.ScalarNode || strings.Contains(refNode.Value, "{[{") {
+			return
+		}
+		if _, err := doc.Repo.FindTTP(refNode.Value); err != nil {
+			findings = append(findings, findingAt(refNode, "could not resolve sub-TTP %q: %v", refNode.Value, err))
+		}
+	})
+	return findings
+}
+
+// subTTPsResolve reports whether every sub-TTP
+// referenced by doc can be found in its repository
+func subTTPsResolve(doc *Document) bool {
+	resolves := true
+	forEachStep(doc.Root, func(step *yaml.Node) {
+		refNode := parseutils.FieldNode(step, "ttp")
+		if refNode == nil {
+			return
+		}
+		if doc.Repo == nil || refNode.Kind != yaml.ScalarNode {
+			resolves = false
+			return
+		}
+		if _, err := doc.Repo.FindTTP(refNode.Value); err != nil {
+			resolves = false
+		}
+	})
+	return resolves
+}
+
+// supportsCurrentPlatform reports whether the requirements
+// of doc allow the TTP to run on the current platform
+func supportsCurrentPlatform(doc *Document) bool {
+	if doc.Preamble.Requirements == nil || len(doc.Preamble.Requirements.Platforms) == 0 {
+		return true
+	}
+	current := platforms.GetCurrentPlatformSpec()
+	for _, platform := range doc.Preamble.Requirements.Platforms {
+		if platform.IsCompatibleWith(current) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkUnusedArg(doc *Document, _ []*Document) []Finding {
+	if doc.Root == nil {
+		return nil
+	}
+	argsNode := parseutils.FieldNode(doc.Root, "args")
+	if argsNode == nil || argsNode.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var findings []Finding
+	for _, argNode := range argsNode.Content {
+		nameNode := parseutils.FieldNode(argNode, "name")
+		if nameNode == nil || nameNode.Value == "" {
+			continue
+		}
+		if !argIsUsed(doc.Content, nameNode.Value) {
+			findings = append(findings, findingAt(nameNode, "argument %q is never used", nameNode.Value))
+		}
+	}
+	return findings
+}
+
+// argIsUsed reports whether the TTP references the named argument,
+// either in a template (`.Args.name` or `index .Args "name"`) or
+// as the `arg:` of a loop
+func argIsUsed(content []byte, name string) bool {
+	quoted := regexp.QuoteMeta(name)
+	usage := regexp.MustCompile(`\.Args\.` + quoted + `\b|index\s+\$?\.Args\s+"` + quoted + `"|(?m)\barg:\s*["']?` + quoted + `["']?\s*$`)
+	return usage.Match(content)
+}
+
+func checkInvalidPlatform(doc *Document, _ []*Document) []Finding {
+	if doc.Root == nil {
+		return nil
+	}
+	platformsNode := parseutils.FieldNode(parseutils.FieldNode(doc.Root, "requirements"), "platforms")
+	if platformsNode == nil || platformsNode.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var findings []Finding
+	for _, platformNode := range platformsNode.Content {
+		var spec platforms.Spec
+		if err := platformNode.Decode(&spec); err != nil {
+			findings = append(findings, findingAt(platformNode, "invalid platform: %v", err))
+			continue
+		}
+		if err := spec.Validate(); err != nil {
+			findings = append(findings, findingAt(platformNode, "invalid platform: %v", err))
+		}
+	}
+	return findings
+}
+
+// forEachStep calls fn for each step of the TTP,
+// including the child steps of `parallel:` steps
+func forEachStep(root *yaml.Node, fn func(step *yaml.Node)) {
+	var visit func(steps *yaml.Node)
+	visit = func(steps *yaml.Node) {
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, step := range steps.Content {
+			if step.Kind != yaml.MappingNode {
+				continue
+			}
+			fn(step)
+			visit(parseutils.FieldNode(step, "parallel"))
+		}
+	}
+	visit(parseutils.FieldNode(root, "steps"))
+}
+
+// stepName returns the name of a step node
+func stepName(step *yaml.Node) string {
+	if nameNode := parseutils.FieldNode(step, "name"); nameNode != nil {
+		return nameNode.Value
+	}
+	return ""
+}
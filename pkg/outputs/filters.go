@@ -0,0 +1,362 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package outputs
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RegexFilter extracts the first match of a regular expression.
+// Group selects the capture group by number or name - by default,
+// the first group is used (or the whole match, if there are no groups).
+type RegexFilter struct {
+	Pattern string `yaml:"regex"`
+	Group   string `yaml:"group,omitempty"`
+}
+
+func (f *RegexFilter) validate() error {
+	_, _, err := f.compile()
+	return err
+}
+
+// compile returns the regular expression and
+// the index of the capture group to extract
+func (f *RegexFilter) compile() (*regexp.Regexp, int, error) {
+	re, err := regexp.Compile(f.Pattern)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid regex %q: %w", f.Pattern, err)
+	}
+	if f.Group == "" {
+		return re, min(re.NumSubexp(), 1), nil
+	}
+	if groupIdx, err := strconv.Atoi(f.Group); err == nil {
+		if groupIdx < 0 || groupIdx > re.NumSubexp() {
+			return nil, 0, fmt.Errorf("regex %q has no group %d", f.Pattern, groupIdx)
+		}
+		return re, groupIdx, nil
+	}
+	groupIdx := re.SubexpIndex(f.Group)
+	if groupIdx < 0 {
+		return nil, 0, fmt.Errorf("regex %q has no group named %q", f.Pattern, f.Group)
+	}
+	return re, groupIdx, nil
+}
+
+// Apply extracts the selected group of the first match
+func (f *RegexFilter) Apply(inStr string) (string, error) {
+	re, groupIdx, err := f.compile()
+	if err != nil {
+		return "", err
+	}
+	match := re.FindStringSubmatchIndex(inStr)
+	if match == nil {
+		return "", fmt.Errorf("regex %q did not match", f.Pattern)
+	}
+	start, end := match[2*groupIdx], match[2*groupIdx+1]
+	if start < 0 {
+		return "", fmt.Errorf("group %v of regex %q did not match", groupIdx, f.Pattern)
+	}
+	return inStr[start:end], nil
+}
+
+// LineFilter selects a single line. Lines are numbered
+// from 1 - negative numbers count back from the last line.
+type LineFilter struct {
+	Line int `yaml:"line"`
+}
+
+func (f *LineFilter) validate() error {
+	if f.Line == 0 {
+		return errors.New("lines are numbered from 1 (or from -1 for the last line)")
+	}
+	return nil
+}
+
+// Apply returns the selected line
+func (f *LineFilter) Apply(inStr string) (string, error) {
+	if err := f.validate(); err != nil {
+		return "", err
+	}
+	lines := splitLines(inStr)
+	idx := f.Line - 1
+	if f.Line < 0 {
+		idx = len(lines) + f.Line
+	}
+	if idx < 0 || idx >= len(lines) {
+		return "", fmt.Errorf("line %d not found: input has %d line(s)", f.Line, len(lines))
+	}
+	return lines[idx], nil
+}
+
+// SplitFilter splits its input on a separator and selects one of the
+// resulting fields. A separator consisting only of whitespace splits
+// on runs of whitespace, as awk does. Fields are indexed from 0 -
+// negative indices count back from the last field.
+type SplitFilter struct {
+	Separator string `yaml:"split"`
+	Index     int    `yaml:"index,omitempty"`
+}
+
+func (f *SplitFilter) validate() error {
+	if f.Separator == "" {
+		return errors.New("split separator must not be empty")
+	}
+	return nil
+}
+
+// Apply returns the selected field
+func (f *SplitFilter) Apply(inStr string) (string, error) {
+	if err := f.validate(); err != nil {
+		return "", err
+	}
+	fields := splitFields(trimNewline(inStr), f.Separator)
+	idx := f.Index
+	if idx < 0 {
+		idx += len(fields)
+	}
+	if idx < 0 || idx >= len(fields) {
+		return "", fmt.Errorf("field %d not found: input has %d field(s)", f.Index, len(fields))
+	}
+	return fields[idx], nil
+}
+
+// TrimFilter removes leading and trailing whitespace
+// or, if Chars is set, the specified characters
+type TrimFilter struct {
+	Trim  bool   `yaml:"trim"`
+	Chars string `yaml:"chars,omitempty"`
+}
+
+// Apply returns the trimmed string
+func (f *TrimFilter) Apply(inStr string) (string, error) {
+	switch {
+	case !f.Trim:
+		return inStr, nil
+	case f.Chars == "":
+		return strings.TrimSpace(inStr), nil
+	default:
+		return strings.Trim(inStr, f.Chars), nil
+	}
+}
+
+// KVFilter parses `key=value` pairs and returns the value
+// of the specified key. By default, there is one pair per line -
+// Delimiter can be set to parse several pairs on the same line,
+// such as the output of `id`.
+type KVFilter struct {
+	Key       string `yaml:"kv"`
+	Separator string `yaml:"separator,omitempty"`
+	Delimiter string `yaml:"delimiter,omitempty"`
+}
+
+func (f *KVFilter) validate() error {
+	if f.Key == "" {
+		return errors.New("kv key must not be empty")
+	}
+	return nil
+}
+
+// Apply returns the value of the first pair with the specified key
+func (f *KVFilter) Apply(inStr string) (string, error) {
+	separator := f.Separator
+	if separator == "" {
+		separator = "="
+	}
+	var pairs []string
+	if f.Delimiter == "" {
+		pairs = splitLines(inStr)
+	} else {
+		pairs = splitFields(trimNewline(inStr), f.Delimiter)
+	}
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, separator)
+		if found && strings.TrimSpace(key) == f.Key {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", fmt.Errorf("key not found: %v", f.Key)
+}
+
+// YAMLFilter will parse a YAML (or JSON) string and extract the value
+// at the provided path, such as `users.0.name`. Mappings and sequences
+// are returned as YAML.
+type YAMLFilter struct {
+	Path string `yaml:"yaml_path"`
+}
+
+func (f *YAMLFilter) validate() error {
+	if f.Path == "" {
+		return errors.New("yaml path must not be empty")
+	}
+	return nil
+}
+
+// Apply returns the value at the path
+func (f *YAMLFilter) Apply(inStr string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(inStr), &doc); err != nil {
+		return "", fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return "", fmt.Errorf("yaml path not found: %v", f.Path)
+	}
+	node := doc.Content[0]
+	for _, segment := range strings.Split(f.Path, ".") {
+		node = yamlChild(node, segment)
+		if node == nil {
+			return "", fmt.Errorf("yaml path not found: %v", f.Path)
+		}
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, nil
+	}
+	resetStyle(node)
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(out)), nil
+}
+
+// yamlChild returns the value of the given key of a mapping,
+// or the element at the given index of a sequence
+func yamlChild(node *yaml.Node, segment string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for idx := 0; idx+1 < len(node.Content); idx += 2 {
+			if node.Content[idx].Value == segment {
+				return node.Content[idx+1]
+			}
+		}
+	case yaml.SequenceNode:
+		idx, err := strconv.Atoi(segment)
+		if err == nil && idx >= 0 && idx < len(node.Content) {
+			return node.Content[idx]
+		}
+	}
+	return nil
+}
+
+// resetStyle ensures that values are written in
+// the default YAML style even if they were parsed
+// from JSON (which uses flow style and quoted strings)
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// CSVFilter extracts a column from CSV data whose first row
+// contains the column names. The values of the column
+// are returned one per line - use a LineFilter to select one.
+type CSVFilter struct {
+	Column    string `yaml:"csv_column"`
+	Delimiter string `yaml:"delimiter,omitempty"`
+}
+
+func (f *CSVFilter) validate() error {
+	if f.Column == "" {
+		return errors.New("csv column must not be empty")
+	}
+	if f.Delimiter != "" && utf8.RuneCountInString(f.Delimiter) != 1 {
+		return fmt.Errorf("csv delimiter must be a single character, not %q", f.Delimiter)
+	}
+	return nil
+}
+
+// Apply returns the values of the column
+func (f *CSVFilter) Apply(inStr string) (string, error) {
+	if err := f.validate(); err != nil {
+		return "", err
+	}
+	reader := csv.NewReader(strings.NewReader(inStr))
+	if f.Delimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(f.Delimiter)
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return "", errors.New("CSV input is empty")
+	}
+	colIdx := -1
+	for idx, name := range records[0] {
+		if strings.TrimSpace(name) == f.Column {
+			colIdx = idx
+			break
+		}
+	}
+	if colIdx < 0 {
+		return "", fmt.Errorf("csv column not found: %v", f.Column)
+	}
+	values := make([]string, 0, len(records)-1)
+	for rowIdx, record := range records[1:] {
+		if colIdx >= len(record) {
+			return "", fmt.Errorf("row %d of CSV input has no %q column", rowIdx+1, f.Column)
+		}
+		values = append(values, record[colIdx])
+	}
+	return strings.Join(values, "\n"), nil
+}
+
+// trimNewline removes the trailing newline
+// that most commands print after their output
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// splitLines splits s into lines,
+// accepting both \n and \r\n line endings
+func splitLines(s string) []string {
+	s = trimNewline(s)
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for idx, line := range lines {
+		lines[idx] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+// splitFields splits s on separator, or on runs
+// of whitespace if separator is only whitespace
+func splitFields(s, separator string) []string {
+	if strings.TrimSpace(separator) == "" {
+		return strings.Fields(s)
+	}
+	return strings.Split(s, separator)
+}
@@ -0,0 +1,304 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package outputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFilters(t *testing.T) {
+	const idOutput = "uid=1000(alice) gid=1000(alice) groups=1000(alice),27(sudo)\n"
+	const netstatOutput = `Proto Recv-Q Send-Q Local Address           Foreign Address         State
+tcp        0      0 127.0.0.1:631           0.0.0.0:*               LISTEN
+tcp        0      0 0.0.0.0:22              0.0.0.0:*               LISTEN
+`
+
+	testCases := []struct {
+		name           string
+		input          string
+		spec           string
+		result         string
+		wantLoadError  bool
+		wantApplyError bool
+	}{
+		{
+			name:  "Regex With Capture Group",
+			input: idOutput,
+			spec: `filters:
+  - regex: uid=(\d+)`,
+			result: "1000",
+		},
+		{
+			name:  "Regex Without Capture Group",
+			input: idOutput,
+			spec: `filters:
+  - regex: groups=\S+`,
+			result: "groups=1000(alice),27(sudo)",
+		},
+		{
+			name:  "Regex With Named Group",
+			input: idOutput,
+			spec: `filters:
+  - regex: gid=(\d+)\((?P<group>\w+)\)
+    group: group`,
+			result: "alice",
+		},
+		{
+			name:  "Regex With Group Number",
+			input: idOutput,
+			spec: `filters:
+  - regex: uid=(\d+)\((\w+)\)
+    group: 2`,
+			result: "alice",
+		},
+		{
+			name:  "Regex Does Not Match",
+			input: idOutput,
+			spec: `filters:
+  - regex: euid=(\d+)`,
+			wantApplyError: true,
+		},
+		{
+			name: "Invalid Regex",
+			spec: `filters:
+  - regex: uid=(\d+`,
+			wantLoadError: true,
+		},
+		{
+			name: "Regex Group Does Not Exist",
+			spec: `filters:
+  - regex: uid=(\d+)
+    group: 2`,
+			wantLoadError: true,
+		},
+		{
+			name:  "First Line",
+			input: "first\r\nsecond\r\n",
+			spec: `filters:
+  - line: 1`,
+			result: "first",
+		},
+		{
+			name:  "Last Line",
+			input: "first\nsecond\nthird\n",
+			spec: `filters:
+  - line: -1`,
+			result: "third",
+		},
+		{
+			name:  "Line Out Of Range",
+			input: "first\nsecond\n",
+			spec: `filters:
+  - line: 3`,
+			wantApplyError: true,
+		},
+		{
+			name: "Line Zero",
+			spec: `filters:
+  - line: 0`,
+			wantLoadError: true,
+		},
+		{
+			name:  "Split On Separator",
+			input: "alice:x:1000:1000::/home/alice:/bin/bash\n",
+			spec: `filters:
+  - split: ":"
+    index: -1`,
+			result: "/bin/bash",
+		},
+		{
+			name:  "Split On Whitespace",
+			input: "tcp   0   0   0.0.0.0:22\n",
+			spec: `filters:
+  - split: " "
+    index: 3`,
+			result: "0.0.0.0:22",
+		},
+		{
+			name:  "Split Index Out Of Range",
+			input: "a,b",
+			spec: `filters:
+  - split: ","
+    index: 2`,
+			wantApplyError: true,
+		},
+		{
+			name: "Empty Split Separator",
+			spec: `filters:
+  - split: ""`,
+			wantLoadError: true,
+		},
+		{
+			name:  "Trim Whitespace",
+			input: "  alice \n",
+			spec: `filters:
+  - trim: true`,
+			result: "alice",
+		},
+		{
+			name:  "Trim Characters",
+			input: `"ubuntu"`,
+			spec: `filters:
+  - trim: true
+    chars: '"'`,
+			result: "ubuntu",
+		},
+		{
+			name:  "Key Value Lines",
+			input: "NAME=\"Ubuntu\"\nVERSION_ID = 24.04\n",
+			spec: `filters:
+  - kv: VERSION_ID`,
+			result: "24.04",
+		},
+		{
+			name:  "Key Value Pairs On One Line",
+			input: idOutput,
+			spec: `filters:
+  - kv: gid
+    delimiter: " "`,
+			result: "1000(alice)",
+		},
+		{
+			name:  "Key Value Custom Separator",
+			input: "Name: alice\nShell: /bin/zsh\n",
+			spec: `filters:
+  - kv: Shell
+    separator: ":"`,
+			result: "/bin/zsh",
+		},
+		{
+			name:  "Key Not Found",
+			input: idOutput,
+			spec: `filters:
+  - kv: euid`,
+			wantApplyError: true,
+		},
+		{
+			name: "YAML Path",
+			input: `users:
+  - name: alice
+    shell: /bin/bash
+  - name: bob
+`,
+			spec: `filters:
+  - yaml_path: users.1.name`,
+			result: "bob",
+		},
+		{
+			name:  "YAML Path To Mapping",
+			input: `{"user": {"name": "alice", "uid": 1000}}`,
+			spec: `filters:
+  - yaml_path: user`,
+			result: "name: alice\nuid: 1000",
+		},
+		{
+			name:  "YAML Path Not Found",
+			input: "users: []",
+			spec: `filters:
+  - yaml_path: users.0`,
+			wantApplyError: true,
+		},
+		{
+			name:  "CSV Column",
+			input: "name,uid\nalice,1000\nbob,1001\n",
+			spec: `filters:
+  - csv_column: uid`,
+			result: "1000\n1001",
+		},
+		{
+			name:  "CSV Column With Delimiter",
+			input: "name;uid\nalice;1000\n",
+			spec: `filters:
+  - csv_column: name
+    delimiter: ;`,
+			result: "alice",
+		},
+		{
+			name:  "CSV Column Not Found",
+			input: "name,uid\nalice,1000\n",
+			spec: `filters:
+  - csv_column: gid`,
+			wantApplyError: true,
+		},
+		{
+			name:  "Filters Compose In Order",
+			input: netstatOutput,
+			spec: `filters:
+  - line: -1
+  - split: " "
+    index: 3
+  - split: ":"
+    index: -1`,
+			result: "22",
+		},
+		{
+			name:  "CSV Column Then Line",
+			input: "name,uid\nalice,1000\nbob,1001\n",
+			spec: `filters:
+  - csv_column: name
+  - line: 2`,
+			result: "bob",
+		},
+		{
+			name: "Ambiguous Filter Type",
+			spec: `filters:
+  - line: 1
+    regex: foo`,
+			wantLoadError: true,
+		},
+		{
+			name: "Unknown Filter Type",
+			spec: `filters:
+  - column: 1`,
+			wantLoadError: true,
+		},
+		{
+			name: "Unknown Filter Field",
+			spec: `filters:
+  - split: ","
+    idx: 1`,
+			wantLoadError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var spec Spec
+			err := yaml.Unmarshal([]byte(tc.spec), &spec)
+			if tc.wantLoadError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			result, err := spec.Apply(tc.input)
+			if tc.wantApplyError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.result, result)
+		})
+	}
+}
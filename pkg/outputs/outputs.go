@@ -22,7 +22,13 @@ package outputs
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
 
+	"github.com/facebookincubator/ttpforge/pkg/jsonschema"
+	"github.com/facebookincubator/ttpforge/pkg/parseutils"
 	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v3"
 )
@@ -83,8 +89,88 @@ type JSONFilter struct {
 	Path string `yaml:"json_path"`
 }
 
+// filterTypes returns a new, empty instance of each
+// filter type, keyed by the field that identifies it
+func filterTypes() map[string]Filter {
+	return map[string]Filter{
+		"json_path":  &JSONFilter{},
+		"regex":      &RegexFilter{},
+		"line":       &LineFilter{},
+		"split":      &SplitFilter{},
+		"trim":       &TrimFilter{},
+		"kv":         &KVFilter{},
+		"yaml_path":  &YAMLFilter{},
+		"csv_column": &CSVFilter{},
+	}
+}
+
+// validator is implemented by filters whose
+// fields can be checked when the filter is loaded
+type validator interface {
+	validate() error
+}
+
+// newFilterForNode creates an empty filter of the type
+// identified by the keys of the given YAML mapping
+func newFilterForNode(node *yaml.Node) (Filter, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a YAML mapping but got %v", node.Tag)
+	}
+	types := filterTypes()
+	var filterKey string
+	var filter Filter
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		key := node.Content[idx].Value
+		candidate, ok := types[key]
+		if !ok {
+			continue
+		}
+		if filter != nil {
+			return nil, fmt.Errorf("output spec contains filter with ambiguous type: both `%v:` and `%v:` were specified", filterKey, key)
+		}
+		filterKey = key
+		filter = candidate
+	}
+	if filter == nil {
+		// a misspelled key is reported along with a suggestion
+		allTypes := make([]any, 0, len(types))
+		for _, ft := range types {
+			allTypes = append(allTypes, ft)
+		}
+		if err := parseutils.CheckKnownFields(node, allTypes...); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no filter type was specified - expected one of: %v", strings.Join(slices.Sorted(maps.Keys(types)), ", "))
+	}
+	return filter, nil
+}
+
+// JSONSchema describes the output spec
+// as a list of filters of any filter type
+func (s *Spec) JSONSchema(r *jsonschema.Reflector) *jsonschema.Schema {
+	types := filterTypes()
+	filterSchema := &jsonschema.Schema{}
+	for _, key := range slices.Sorted(maps.Keys(types)) {
+		typeSchema := r.ReflectStruct(reflect.TypeOf(types[key]))
+		typeSchema.Required = []string{key}
+		filterSchema.AnyOf = append(filterSchema.AnyOf, typeSchema)
+	}
+	return &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"filters": {Type: "array", Items: filterSchema},
+		},
+		Required:             []string{"filters"},
+		AdditionalProperties: jsonschema.Bool(false),
+	}
+}
+
 // UnmarshalYAML is used to load specs from yaml files
 func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
+	return parseutils.ErrorAt(node, s.unmarshalYAML(node))
+}
+
+func (s *Spec) unmarshalYAML(node *yaml.Node) error {
 	type SpecTmp struct {
 		FilterNodes []yaml.Node `yaml:"filters"`
 	}
@@ -93,20 +179,29 @@ func (s *Spec) UnmarshalYAML(node *yaml.Node) error {
 	if err := node.Decode(&tmp); err != nil {
 		return err
 	}
+	if err := parseutils.CheckKnownFields(node, &tmp); err != nil {
+		return err
+	}
 
 	var filters []Filter
-	for _, fn := range tmp.FilterNodes {
-		filterTypes := []Filter{&JSONFilter{}}
-		var alreadyFound bool
-		for _, ft := range filterTypes {
-			if err := fn.Decode(ft); err == nil {
-				if alreadyFound {
-					return errors.New("output spec contains filter with ambiguous type")
-				}
-				filters = append(filters, ft)
-				alreadyFound = true
+	for idx := range tmp.FilterNodes {
+		fn := &tmp.FilterNodes[idx]
+		filter, err := newFilterForNode(fn)
+		if err != nil {
+			return parseutils.ErrorAt(fn, err)
+		}
+		if err := fn.Decode(filter); err != nil {
+			return parseutils.ErrorAt(fn, err)
+		}
+		if err := parseutils.CheckKnownFields(fn, filter); err != nil {
+			return err
+		}
+		if v, ok := filter.(validator); ok {
+			if err := v.validate(); err != nil {
+				return parseutils.ErrorAt(fn, err)
 			}
 		}
+		filters = append(filters, filter)
 	}
 	if len(filters) == 0 {
 		return errors.New("no valid filters found in output spec")
@@ -0,0 +1,317 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package parseutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NodeError associates an error with the position
+// of the YAML node that caused it. Its message is
+// that of the wrapped error - use Locate to render
+// the position once the file name is known.
+type NodeError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *NodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// locatedError is returned by Locate so that
+// errors are never located more than once
+type locatedError struct {
+	msg string
+	err error
+}
+
+func (e *locatedError) Error() string {
+	return e.msg
+}
+
+func (e *locatedError) Unwrap() error {
+	return e.err
+}
+
+// ErrorAt records the position of node in err, unless err already
+// carries the position of a (more specific) node
+//
+// **Parameters:**
+//
+// node: the YAML node that caused the error
+// err: the error to wrap
+//
+// **Returns:**
+//
+// error: the wrapped error, or nil if err is nil
+func ErrorAt(node *yaml.Node, err error) error {
+	if err == nil || node == nil || node.Line == 0 {
+		return err
+	}
+	var nodeErr *NodeError
+	var located *locatedError
+	if errors.As(err, &nodeErr) || errors.As(err, &located) {
+		return err
+	}
+	return &NodeError{Line: node.Line, Column: node.Column, Err: err}
+}
+
+// Locate prefixes the message of err with the position recorded
+// by ErrorAt, as `path:line:column:` (or `line L, column C:` if
+// the path is empty). Errors without a position are returned as-is.
+//
+// **Parameters:**
+//
+// err: the error to locate
+// path: the path of the file that was decoded
+//
+// **Returns:**
+//
+// error: the located error
+func Locate(err error, path string) error {
+	var nodeErr *NodeError
+	var located *locatedError
+	if err == nil || errors.As(err, &located) || !errors.As(err, &nodeErr) {
+		return err
+	}
+	prefix := fmt.Sprintf("line %d, column %d", nodeErr.Line, nodeErr.Column)
+	if path != "" {
+		prefix = fmt.Sprintf("%v:%d:%d", path, nodeErr.Line, nodeErr.Column)
+	}
+	return &locatedError{
+		msg: prefix + ": " + err.Error(),
+		err: err,
+	}
+}
+
+// FieldNode returns the value node for the given key
+// of a YAML mapping node, or nil if there is no such key
+func FieldNode(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+// CheckKnownFields reports keys of a YAML mapping that would be
+// silently ignored when decoding it into the given structs, such
+// as misspelled field names. Nested structs are checked as well,
+// except for types that implement yaml.Unmarshaler, which are
+// expected to check their own fields.
+//
+// **Parameters:**
+//
+// node: the YAML mapping node
+// targets: the structs (or pointers to them) into which node is decoded
+//
+// **Returns:**
+//
+// error: a NodeError for the first unknown key, if any
+func CheckKnownFields(node *yaml.Node, targets ...any) error {
+	types := make([]reflect.Type, 0, len(targets))
+	for _, target := range targets {
+		types = append(types, reflect.TypeOf(target))
+	}
+	return checkFields(node, types)
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	nodeType        = reflect.TypeOf(yaml.Node{})
+)
+
+// checkFields checks node against the union of the fields of types
+func checkFields(node *yaml.Node, types []reflect.Type) error {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	fields := make(map[string]reflect.Type)
+	for _, t := range types {
+		if !collectFields(t, fields) {
+			// an inline map accepts any key
+			return nil
+		}
+	}
+
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		keyNode, valueNode := node.Content[idx], node.Content[idx+1]
+		key := keyNode.Value
+		if key == "<<" {
+			continue
+		}
+		fieldType, ok := fields[key]
+		if !ok {
+			msg := fmt.Sprintf("unknown field %q", key)
+			if suggestion := closestKey(key, fields); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			return ErrorAt(keyNode, errors.New(msg))
+		}
+		if err := checkValue(valueNode, fieldType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkValue checks the fields of a value that is decoded
+// into the given type, if that type is a struct (or a
+// collection of structs) that does not decode itself
+func checkValue(node *yaml.Node, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nodeType || t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return checkFields(node, []reflect.Type{t})
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for _, item := range node.Content {
+			if err := checkValue(item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for idx := 1; idx < len(node.Content); idx += 2 {
+			if err := checkValue(node.Content[idx], t.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectFields adds the YAML keys of the struct type t to fields
+// and returns false if the struct accepts arbitrary keys
+func collectFields(t reflect.Type, fields map[string]reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		tag := field.Tag.Get("yaml")
+		if tag == "-" || strings.HasPrefix(tag, "-,") {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(","+opts+",", ",inline,") {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Map {
+				return false
+			}
+			if !collectFields(fieldType, fields) {
+				return false
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return true
+}
+
+// closestKey suggests the known key that is most similar to key,
+// if any is similar enough to be a plausible typo
+func closestKey(key string, fields map[string]reflect.Type) string {
+	candidates := make([]string, 0, len(fields))
+	for candidate := range fields {
+		candidates = append(candidates, candidate)
+	}
+	sort.Strings(candidates)
+
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if distance := editDistance(key, candidate); distance < bestDistance && distance < len(key) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
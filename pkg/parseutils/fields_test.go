@@ -0,0 +1,202 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package parseutils
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+type testCommonFields struct {
+	Name string `yaml:"name"`
+}
+
+type testSelfDecoding struct {
+	Anything string
+}
+
+func (s *testSelfDecoding) UnmarshalYAML(node *yaml.Node) error {
+	return nil
+}
+
+type testAction struct {
+	testCommonFields `yaml:",inline"`
+	Path             string                      `yaml:"path"`
+	Overwrite        bool                        `yaml:"overwrite,omitempty"`
+	Headers          []testHeader                `yaml:"headers,omitempty"`
+	Nested           *testHeader                 `yaml:"nested,omitempty"`
+	ByName           map[string]testHeader       `yaml:"by_name,omitempty"`
+	Custom           testSelfDecoding            `yaml:"custom,omitempty"`
+	Ignored          string                      `yaml:"-"`
+	Raw              yaml.Node                   `yaml:"raw,omitempty"`
+	Extra            map[string]testSelfDecoding `yaml:"extra,omitempty"`
+}
+
+type testHeader struct {
+	Field string `yaml:"field"`
+	Value string `yaml:"value"`
+}
+
+type testInlineMap struct {
+	Name  string         `yaml:"name"`
+	Other map[string]any `yaml:",inline"`
+}
+
+func TestCheckKnownFields(t *testing.T) {
+	testCases := []struct {
+		name               string
+		content            string
+		targets            []any
+		expectErrorMessage string
+		expectedLine       int
+		expectedColumn     int
+	}{
+		{
+			name: "All Fields Known",
+			content: `name: foo
+path: /tmp/foo
+overwrite: true
+headers:
+  - field: a
+    value: b
+nested:
+  field: c
+by_name:
+  x:
+    value: d
+custom:
+  whatever: is checked by the type itself
+raw:
+  anything: goes
+extra:
+  y:
+    whatever: goes`,
+			targets: []any{&testAction{}},
+		},
+		{
+			name: "Misspelled Field",
+			content: `name: foo
+path: /tmp/foo
+overwite: true`,
+			targets:            []any{&testAction{}},
+			expectErrorMessage: `unknown field "overwite" (did you mean "overwrite"?)`,
+			expectedLine:       3,
+			expectedColumn:     1,
+		},
+		{
+			name: "Unknown Field Without Suggestion",
+			content: `name: foo
+description: bar`,
+			targets:            []any{&testAction{}},
+			expectErrorMessage: `unknown field "description"`,
+			expectedLine:       2,
+			expectedColumn:     1,
+		},
+		{
+			name: "Field From Another Target",
+			content: `name: foo
+field: a`,
+			targets: []any{&testCommonFields{}, &testHeader{}},
+		},
+		{
+			name: "Unknown Field In Sequence Item",
+			content: `headers:
+  - field: a
+    valeu: b`,
+			targets:            []any{&testAction{}},
+			expectErrorMessage: `unknown field "valeu" (did you mean "value"?)`,
+			expectedLine:       3,
+			expectedColumn:     5,
+		},
+		{
+			name: "Unknown Field In Map Value",
+			content: `by_name:
+  x:
+    fields: a`,
+			targets:            []any{&testAction{}},
+			expectErrorMessage: `unknown field "fields" (did you mean "field"?)`,
+			expectedLine:       3,
+			expectedColumn:     5,
+		},
+		{
+			name:               "Fields Ignored By YAML Are Unknown",
+			content:            `ignored: foo`,
+			targets:            []any{&testAction{}},
+			expectErrorMessage: `unknown field "ignored"`,
+			expectedLine:       1,
+			expectedColumn:     1,
+		},
+		{
+			name: "Inline Map Accepts Anything",
+			content: `name: foo
+something: else`,
+			targets: []any{&testInlineMap{}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tc.content), &node))
+			err := CheckKnownFields(&node, tc.targets...)
+			if tc.expectErrorMessage == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tc.expectErrorMessage, err.Error())
+			var nodeErr *NodeError
+			require.ErrorAs(t, err, &nodeErr)
+			assert.Equal(t, tc.expectedLine, nodeErr.Line)
+			assert.Equal(t, tc.expectedColumn, nodeErr.Column)
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	var node yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte("outer:\n  inner: value\n"), &node))
+	outerNode := node.Content[0]
+	innerNode := FieldNode(outerNode, "outer").Content[0]
+
+	// the most specific position wins
+	baseErr := errors.New("bad value")
+	err := ErrorAt(outerNode, fmt.Errorf("decoding outer: %w", ErrorAt(innerNode, baseErr)))
+	assert.Equal(t, "decoding outer: bad value", err.Error())
+	assert.ErrorIs(t, err, baseErr)
+
+	located := Locate(err, "ttp.yaml")
+	assert.Equal(t, "ttp.yaml:2:3: decoding outer: bad value", located.Error())
+	assert.ErrorIs(t, located, baseErr)
+	assert.Equal(t, "line 2, column 3: decoding outer: bad value", Locate(err, "").Error())
+
+	// located errors are not located again, even by enclosing files
+	wrapped := ErrorAt(outerNode, fmt.Errorf("loading sub-TTP: %w", located))
+	assert.Equal(t, "loading sub-TTP: ttp.yaml:2:3: decoding outer: bad value", Locate(wrapped, "parent.yaml").Error())
+
+	assert.Equal(t, baseErr, Locate(baseErr, "ttp.yaml"))
+	assert.NoError(t, Locate(nil, "ttp.yaml"))
+	assert.NoError(t, ErrorAt(outerNode, nil))
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package parseutils
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SourceLines maps each line of a rendered template (such as a TTP)
+// to the line of the raw file that it was rendered from. Lines that templating leaves
+// unchanged are matched exactly (as the longest common subsequence of
+// the lines of both files). The remaining rendered lines are assigned,
+// in order, to the unmatched raw lines between the same matched lines.
+//
+// **Parameters:**
+//
+// raw: the content of the file
+// rendered: the rendered content
+//
+// **Returns:**
+//
+// []int: the (1-based) raw line of each rendered line, by index
+func SourceLines(raw, rendered []byte) []int {
+	rawLines := bytes.Split(raw, []byte("\n"))
+	renderedLines := bytes.Split(rendered, []byte("\n"))
+
+	// common[i][j] is the length of the longest common
+	// subsequence of rawLines[i:] and renderedLines[j:]
+	common := make([][]int, len(rawLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(renderedLines)+1)
+	}
+	for i := len(rawLines) - 1; i >= 0; i-- {
+		for j := len(renderedLines) - 1; j >= 0; j-- {
+			switch {
+			case bytes.Equal(rawLines[i], renderedLines[j]):
+				common[i][j] = common[i+1][j+1] + 1
+			case common[i+1][j] >= common[i][j+1]:
+				common[i][j] = common[i+1][j]
+			default:
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	result := make([]int, len(renderedLines))
+	// prevRaw is the index of the last matched raw line and
+	// gapStart the index of the first raw line after it
+	prevRaw, gapStart := -1, 0
+	i, j := 0, 0
+	for j < len(renderedLines) {
+		if i < len(rawLines) && bytes.Equal(rawLines[i], renderedLines[j]) && common[i][j] == common[i+1][j+1]+1 {
+			result[j] = i + 1
+			prevRaw, gapStart = i, i+1
+			i++
+			j++
+			continue
+		}
+		if i < len(rawLines) && common[i+1][j] >= common[i][j+1] {
+			i++
+			continue
+		}
+		// renderedLines[j] is not matched - assign it to the next
+		// unmatched raw line of the gap, or to the last line of
+		// the gap (or the matched line before it) if there is none
+		switch {
+		case gapStart < i:
+			result[j] = gapStart + 1
+			gapStart++
+		case gapStart > 0 && gapStart-1 > prevRaw:
+			result[j] = gapStart
+		case prevRaw >= 0:
+			result[j] = prevRaw + 1
+		default:
+			result[j] = 1
+		}
+		j++
+	}
+	return result
+}
+
+// RemapLines replaces the (rendered) line numbers of node and its
+// descendants with the source lines returned by SourceLines, so that
+// errors recorded with ErrorAt refer to the lines of the file
+//
+// **Parameters:**
+//
+// node: the YAML node decoded from the rendered content
+// lines: the source line of each rendered line, by index
+func RemapLines(node *yaml.Node, lines []int) {
+	if node.Line > 0 && node.Line <= len(lines) {
+		node.Line = lines[node.Line-1]
+	}
+	for _, child := range node.Content {
+		RemapLines(child, lines)
+	}
+}
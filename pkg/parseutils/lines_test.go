@@ -0,0 +1,66 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package parseutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSourceLines(t *testing.T) {
+	testCases := []struct {
+		name          string
+		raw           string
+		rendered      string
+		expectedLines []int
+	}{
+		{
+			name:          "Unchanged",
+			raw:           "a\nb\nc",
+			rendered:      "a\nb\nc",
+			expectedLines: []int{1, 2, 3},
+		},
+		{
+			name:          "Removed Lines",
+			raw:           "a\n{{ if false }}\nb\n{{ end }}\nc",
+			rendered:      "a\n\nc",
+			expectedLines: []int{1, 2, 5},
+		},
+		{
+			name:          "Changed Lines",
+			raw:           "a\nx: {{ .Args.x }}\ny: {{ .Args.y }}\nb",
+			rendered:      "a\nx: 1\ny: 2\nb",
+			expectedLines: []int{1, 2, 3, 4},
+		},
+		{
+			name:          "Added Lines",
+			raw:           "a\n{{ range .Args.items }}- {{ . }}\n{{ end }}b",
+			rendered:      "a\n- 1\n- 2\n- 3\nb",
+			expectedLines: []int{1, 2, 3, 3, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedLines, SourceLines([]byte(tc.raw), []byte(tc.rendered)))
+		})
+	}
+}
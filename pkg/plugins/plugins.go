@@ -0,0 +1,231 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// ProtocolVersion is sent with every request so that
+// plugins can reject requests that they do not understand
+const ProtocolVersion = 1
+
+// ExecutablePrefix is the prefix of the file name of every plugin
+// executable - the plugin `ad` is implemented by `ttpforge-plugin-ad`
+const ExecutablePrefix = "ttpforge-plugin-"
+
+// Method identifies the operation requested from a plugin
+type Method string
+
+const (
+	// MethodValidate asks the plugin to check the arguments
+	// of a step before the TTP runs
+	MethodValidate Method = "validate"
+	// MethodExecute asks the plugin to perform the action of a step
+	MethodExecute Method = "execute"
+	// MethodCleanup asks the plugin to undo the action of a step
+	MethodCleanup Method = "cleanup"
+)
+
+// Request is the JSON message written to the stdin of a plugin.
+// State is only set for cleanup requests and contains the
+// state returned by the plugin when the step was executed.
+type Request struct {
+	ProtocolVersion int                   `json:"protocol_version"`
+	Method          Method                `json:"method"`
+	Args            map[string]any        `json:"args,omitempty"`
+	Vars            Vars                  `json:"vars"`
+	Steps           map[string]StepResult `json:"steps,omitempty"`
+	State           any                   `json:"state,omitempty"`
+}
+
+// Vars are the execution variables of the TTP at the time of the request
+type Vars struct {
+	WorkDir  string            `json:"work_dir"`
+	Args     map[string]any    `json:"args,omitempty"`
+	StepVars map[string]string `json:"step_vars,omitempty"`
+	Env      map[string]string `json:"env,omitempty"`
+}
+
+// StepResult holds the results of a step that ran
+// before the step that the request is for
+type StepResult struct {
+	Stdout   string            `json:"stdout,omitempty"`
+	Stderr   string            `json:"stderr,omitempty"`
+	ExitCode int               `json:"exit_code"`
+	Outputs  map[string]string `json:"outputs,omitempty"`
+	Skipped  bool              `json:"skipped,omitempty"`
+}
+
+// Response is the JSON message that a plugin writes to its stdout.
+// A non-empty Error fails the step. State is stored by TTPForge
+// and passed back to the plugin in the cleanup request of the step.
+type Response struct {
+	Error    string            `json:"error,omitempty"`
+	Stdout   string            `json:"stdout,omitempty"`
+	Stderr   string            `json:"stderr,omitempty"`
+	ExitCode int               `json:"exit_code,omitempty"`
+	Outputs  map[string]string `json:"outputs,omitempty"`
+	State    any               `json:"state,omitempty"`
+}
+
+// EncodeRequest writes the request to w in the format expected by plugins
+func EncodeRequest(w io.Writer, req Request) error {
+	if req.ProtocolVersion == 0 {
+		req.ProtocolVersion = ProtocolVersion
+	}
+	return json.NewEncoder(w).Encode(req)
+}
+
+// DecodeResponse reads the response of a plugin from its stdout.
+// Anything other than a single JSON object is an error, so plugins
+// must send their own diagnostic output to stderr. Unknown fields
+// are ignored so that plugins can add fields of their own.
+func DecodeResponse(r io.Reader) (*Response, error) {
+	dec := json.NewDecoder(r)
+	var resp Response
+	if err := dec.Decode(&resp); err != nil {
+		if err == io.EOF {
+			return nil, fmt.Errorf("plugin did not write a response")
+		}
+		return nil, fmt.Errorf("invalid plugin response: %w", err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid plugin response: unexpected data after the response object")
+	}
+	return &resp, nil
+}
+
+// Plugin is an executable found in one of the plugin search paths
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// List returns the plugins found in the given search paths, sorted by
+// name. If several search paths contain a plugin of the same name,
+// the first one wins. Search paths that do not exist are ignored.
+//
+// **Parameters:**
+//
+// searchPaths: the directories in which to look for plugins
+//
+// **Returns:**
+//
+// []Plugin: the plugins that were found
+// error: an error if a search path could not be read
+func List(searchPaths []string) ([]Plugin, error) {
+	found := make(map[string]Plugin)
+	for _, dir := range searchPaths {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read plugin directory %v: %w", dir, err)
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry)
+			if !ok {
+				continue
+			}
+			if _, exists := found[name]; exists {
+				continue
+			}
+			found[name] = Plugin{
+				Name: name,
+				Path: filepath.Join(dir, entry.Name()),
+			}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, p := range found {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, nil
+}
+
+// Find returns the plugin with the given name
+//
+// **Parameters:**
+//
+// searchPaths: the directories in which to look for plugins
+// name: the name of the plugin (without the ExecutablePrefix)
+//
+// **Returns:**
+//
+// Plugin: the plugin that was found
+// error: an error if there is no such plugin
+func Find(searchPaths []string, name string) (Plugin, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Plugin{}, fmt.Errorf("invalid plugin name %q", name)
+	}
+	plugins, err := List(searchPaths)
+	if err != nil {
+		return Plugin{}, err
+	}
+	for _, p := range plugins {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	if len(searchPaths) == 0 {
+		return Plugin{}, fmt.Errorf("plugin %q not found: no plugin_paths are configured", name)
+	}
+	return Plugin{}, fmt.Errorf("plugin %q not found in %v", name, strings.Join(searchPaths, ", "))
+}
+
+// pluginName returns the name of the plugin implemented
+// by the given directory entry, if it is a plugin
+func pluginName(entry os.DirEntry) (string, bool) {
+	fileName := entry.Name()
+	if !strings.HasPrefix(fileName, ExecutablePrefix) || entry.IsDir() {
+		return "", false
+	}
+	info, err := entry.Info()
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	name := strings.TrimPrefix(fileName, ExecutablePrefix)
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		switch ext {
+		case ".exe", ".bat", ".cmd", ".com":
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		default:
+			return "", false
+		}
+	} else if info.Mode().Perm()&0111 == 0 {
+		return "", false
+	}
+	return name, name != ""
+}
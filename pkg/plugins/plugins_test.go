@@ -0,0 +1,159 @@
+/*
+Copyright © 2025-present, Meta Platforms, Inc. and affiliates
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package plugins
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePluginFile(t *testing.T, dir, fileName string, mode os.FileMode) string {
+	path := filepath.Join(dir, fileName)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"), mode))
+	return path
+}
+
+func TestListAndFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin discovery on windows relies on file extensions")
+	}
+	firstDir := t.TempDir()
+	secondDir := t.TempDir()
+	adPath := writePluginFile(t, firstDir, ExecutablePrefix+"ad", 0755)
+	writePluginFile(t, secondDir, ExecutablePrefix+"ad", 0755)
+	dnsPath := writePluginFile(t, secondDir, ExecutablePrefix+"dns", 0755)
+	writePluginFile(t, secondDir, ExecutablePrefix+"not-executable", 0644)
+	writePluginFile(t, secondDir, "unrelated-tool", 0755)
+	searchPaths := []string{firstDir, secondDir, filepath.Join(firstDir, "does-not-exist")}
+
+	found, err := List(searchPaths)
+	require.NoError(t, err)
+	assert.Equal(t, []Plugin{
+		{Name: "ad", Path: adPath},
+		{Name: "dns", Path: dnsPath},
+	}, found)
+
+	testCases := []struct {
+		name               string
+		searchPaths        []string
+		pluginName         string
+		expectedPath       string
+		expectErrorMessage string
+	}{
+		{
+			name:         "Earlier Search Path Wins",
+			searchPaths:  searchPaths,
+			pluginName:   "ad",
+			expectedPath: adPath,
+		},
+		{
+			name:               "Plugin Not Found",
+			searchPaths:        searchPaths,
+			pluginName:         "not-executable",
+			expectErrorMessage: `plugin "not-executable" not found`,
+		},
+		{
+			name:               "No Search Paths",
+			pluginName:         "ad",
+			expectErrorMessage: "no plugin_paths are configured",
+		},
+		{
+			name:               "Invalid Name",
+			searchPaths:        searchPaths,
+			pluginName:         "../ad",
+			expectErrorMessage: "invalid plugin name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plugin, err := Find(tc.searchPaths, tc.pluginName)
+			if tc.expectErrorMessage != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErrorMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedPath, plugin.Path)
+		})
+	}
+}
+
+func TestProtocolMessages(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, EncodeRequest(&buf, Request{
+		Method: MethodExecute,
+		Args:   map[string]any{"domain": "corp.example.com"},
+		Vars:   Vars{WorkDir: "/tmp"},
+	}))
+	assert.Equal(t, `{"protocol_version":1,"method":"execute","args":{"domain":"corp.example.com"},"vars":{"work_dir":"/tmp"}}`+"\n", buf.String())
+
+	testCases := []struct {
+		name               string
+		response           string
+		expected           *Response
+		expectErrorMessage string
+	}{
+		{
+			name:     "Full Response",
+			response: `{"stdout":"done","exit_code":0,"outputs":{"user":"alice"},"state":{"id":7}}`,
+			expected: &Response{
+				Stdout:  "done",
+				Outputs: map[string]string{"user": "alice"},
+				State:   map[string]any{"id": float64(7)},
+			},
+		},
+		{
+			name:               "Empty Response",
+			response:           "",
+			expectErrorMessage: "plugin did not write a response",
+		},
+		{
+			name:     "Unknown Field",
+			response: `{"stdout":"done","result":1}`,
+			expected: &Response{Stdout: "done"},
+		},
+		{
+			name:               "Trailing Output",
+			response:           `{"stdout":"done"} some debug output`,
+			expectErrorMessage: "unexpected data after the response object",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := DecodeResponse(strings.NewReader(tc.response))
+			if tc.expectErrorMessage != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectErrorMessage)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resp)
+		})
+	}
+}